@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+func TestConfigHandler_RedactsSecrets(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.JWT.HMACSecret = "secret"
+	router, err := api.NewRouter(api.AdminRoutes(config.NewStore(cfg)), cfg.Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	rec := httptestGet(router, "/admin/config")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "local-dev-key")
+	assert.NotContains(t, rec.Body.String(), "secret")
+
+	var response struct {
+		Data struct {
+			Server map[string]interface{} `json:"server"`
+			Auth   struct {
+				APIKeys []config.APIKey `json:"apiKeys"`
+			} `json:"auth"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "30s", response.Data.Server["readTimeout"])
+	require.Len(t, response.Data.Auth.APIKeys, 2)
+	assert.Equal(t, config.RedactedValue, response.Data.Auth.APIKeys[0].Key)
+	assert.Equal(t, "local-dev", response.Data.Auth.APIKeys[0].Subject)
+}
+
+// stubJobRunner runs the jobs by sending the result of the run on done.
+type stubJobRunner struct {
+	done chan error
+}
+
+func (s stubJobRunner) RunJob(name string) (<-chan error, error) {
+	if name != async.ReportGeneratorJob {
+		return nil, async.ErrUnknownJob
+	}
+	return s.done, nil
+}
+
+func (s stubJobRunner) JobStatus(name string) (async.JobStatus, error) {
+	return async.JobStatus{Job: name, State: async.JobRunning}, nil
+}
+
+func TestRunJobHandler(t *testing.T) {
+	jobs := stubJobRunner{done: make(chan error, 1)}
+	handler := api.RunJobHandler(jobs, 10*time.Millisecond)
+	run := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/jobs/run", strings.NewReader(body)))
+		return rec
+	}
+
+	// A run outlasting the wait is accepted, with the status of the job.
+	rec := run(`{"job":"report-generator"}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	var response struct {
+		Data async.JobStatus `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, async.JobRunning, response.Data.State)
+
+	jobs.done <- errors.New("disk full")
+	rec = run(`{"job":"report-generator"}`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), api.CodeJobFailed)
+
+	jobs.done <- nil
+	assert.Equal(t, http.StatusOK, run(`{"job":"report-generator"}`).Code)
+
+	assert.Equal(t, http.StatusNotFound, run(`{"job":"unknown"}`).Code)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+)
+
+// APIKeyHeader is the header carrying a static API key.
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware authenticates the request with an API key or a bearer token and stores the
+// resulting principal in the request context. Unauthenticated requests get a 401 problem, whose
+// detail does not tell why the credentials were refused: the reason is only logged.
+func AuthMiddleware(authenticator *auth.Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			principal *auth.Principal
+			err       error
+		)
+
+		if key := r.Header.Get(APIKeyHeader); key != "" {
+			principal, err = authenticator.AuthenticateAPIKey(key)
+		} else {
+			principal, err = authenticator.AuthenticateBearer(bearerToken(r))
+		}
+
+		if err != nil {
+			logmgr.GetLogger().LogDebug(r.Context(), fmt.Sprintf("authentication failed (requestId=%s): %v", RequestIDFromContext(r.Context()), err))
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gophernet"`)
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid credentials")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// AnonymousMiddleware stores the anonymous principal in the request context. It stands for
+// AuthMiddleware when authentication is disabled.
+func AnonymousMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), auth.Anonymous())))
+	})
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+
+	return ""
+}
+
+// AuthorizationMiddleware lets the request through only when the principal of the request has
+// at least one of the given roles: an empty roles list authorizes nobody. Unauthorized requests
+// get a 403 problem.
+func AuthorizationMiddleware(roles []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := auth.PrincipalFromContext(r.Context())
+		if !ok || !principal.HasAnyRole(roles...) {
+			detail := "This operation is not allowed to any role"
+			if len(roles) > 0 {
+				detail = "This operation requires one of the roles " + strings.Join(roles, ", ")
+			}
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden, detail)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
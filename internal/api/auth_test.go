@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestAuthMiddleware_RecordsRenter(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{{Key: "secret-key", Subject: "ops-script"}},
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewMemoryRepository("", "")
+	repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1, Width: 1})
+	service := services.NewGopherNetService(repo)
+
+	handler := api.AuthMiddleware(authenticator, api.RentBurrowHandler(service))
+
+	// Without credentials the request is rejected.
+	req := httptest.NewRequest(http.MethodPost, "/burrows/rent", strings.NewReader(`{"name":"Burrow1"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.False(t, repo.GetAllBurrows()[0].Occupied)
+
+	// With a valid API key the burrow is rented on behalf of the key owner.
+	req = httptest.NewRequest(http.MethodPost, "/burrows/rent", strings.NewReader(`{"name":"Burrow1"}`))
+	req.Header.Set(api.APIKeyHeader, "secret-key")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ops-script", repo.GetAllBurrows()[0].RentedBy)
+}
+
+func TestReleaseBurrowHandler_OnlyRenterReleases(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{
+			{Key: "bob-key", Subject: "bob", Roles: []string{auth.RoleRenter}},
+			{Key: "alice-key", Subject: "alice", Roles: []string{auth.RoleRenter}},
+		},
+	})
+	require.NoError(t, err)
+
+	repo := repository.NewMemoryRepository("", "")
+	repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1, Width: 1, Occupied: true, RentedBy: "alice"})
+	handler := api.AuthMiddleware(authenticator, api.ReleaseBurrowHandler(services.NewGopherNetService(repo)))
+
+	release := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/burrows/release", strings.NewReader(`{"name":"Burrow1"}`))
+		req.Header.Set(api.APIKeyHeader, key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusForbidden, release("bob-key").Code)
+	assert.True(t, repo.GetAllBurrows()[0].Occupied)
+
+	assert.Equal(t, http.StatusOK, release("alice-key").Code)
+	assert.False(t, repo.GetAllBurrows()[0].Occupied)
+
+	assert.Equal(t, http.StatusConflict, release("alice-key").Code)
+}
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	handler := api.AuthorizationMiddleware([]string{auth.RoleManager, auth.RoleAdmin}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		want      int
+	}{
+		{"anonymous", nil, http.StatusForbidden},
+		{"renter", &auth.Principal{Subject: "alice", Roles: []string{auth.RoleRenter}}, http.StatusForbidden},
+		{"manager", &auth.Principal{Subject: "bob", Roles: []string{auth.RoleManager}}, http.StatusNoContent},
+		{"admin", &auth.Principal{Subject: "carol", Roles: []string{auth.RoleRenter, auth.RoleAdmin}}, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/report", nil)
+		if tt.principal != nil {
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, tt.want, rec.Code, tt.name)
+
+		if tt.want == http.StatusForbidden {
+			var problem api.Problem
+			assert.Equal(t, api.ProblemContentType, rec.Header().Get("Content-Type"), tt.name)
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&problem), tt.name)
+			assert.Equal(t, api.CodeForbidden, problem.Code, tt.name)
+		}
+	}
+}
+
+func TestAuthorizationMiddleware_DeniesWithoutRoles(t *testing.T) {
+	handler := api.AuthorizationMiddleware(nil, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "carol", Roles: []string{auth.RoleAdmin}}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestServer_AnonymousCallersAreRenters(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, httptestGet(server.Handler, "/burrows").Code)
+
+	// The admin endpoints stay closed without authentication.
+	rec := httptest.NewRecorder()
+	server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/jobs/run", strings.NewReader(`{"job":"report-generator"}`)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// BackupHandler downloads the whole state as a state document, in the format of the state file.
+func BackupHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		backup, err := service.Backup()
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="gophernet-backup.json"`)
+		w.Write(backup)
+	}
+}
+
+// RestoreBackupHandler replaces the whole state by the backup in the body, a state document
+// downloaded from the backup endpoint or copied from the state file.
+func RestoreBackupHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		backup, err := io.ReadAll(r.Body)
+		if err == nil && len(backup) == 0 {
+			err = io.EOF
+		}
+		if err != nil {
+			writeDecodeProblem(w, r, err)
+			return
+		}
+
+		summary, err := service.RestoreBackup(backup)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Backup restored successfully",
+			Data:    summary,
+		})
+	}
+}
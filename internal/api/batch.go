@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// CodeBatchFailed is the code of the problem raised when an item of a batch fails, the batch
+// changing nothing.
+const CodeBatchFailed = "batch_failed"
+
+// maxBatchSize bounds the number of burrows of a batch.
+const maxBatchSize = 100
+
+// Statuses of the items of a batch.
+const (
+	BatchItemRented   = "rented"
+	BatchItemReleased = "released"
+	// BatchItemFailed is the status of the items that failed the batch.
+	BatchItemFailed = "failed"
+	// BatchItemAborted is the status of the valid items of a failed batch, left unchanged.
+	BatchItemAborted = "aborted"
+)
+
+// BatchBurrowsRequest is the payload of the batch rent and release endpoints.
+type BatchBurrowsRequest struct {
+	Names []string `json:"names"`
+}
+
+func (req *BatchBurrowsRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.check(len(req.Names) > 0, "names", "is required")
+	v.check(len(req.Names) <= maxBatchSize, "names", fmt.Sprintf("must contain at most %d names", maxBatchSize))
+	for i, name := range req.Names {
+		v.requiredString(name, fmt.Sprintf("names[%d]", i), maxNameLength)
+	}
+
+	return v.errors
+}
+
+// BatchItemResult is the outcome of an item of a batch. Code and Message say why a failed item failed.
+type BatchItemResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// BatchBurrowsResponse is the data returned by the batch endpoints.
+type BatchBurrowsResponse struct {
+	Items []BatchItemResult `json:"items"`
+}
+
+// RentBurrowsHandler rents every named burrow to the authenticated caller, or none of them when
+// one cannot be rented.
+func RentBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request BatchBurrowsRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		errs := service.RentBurrows(request.Names, caller.Subject)
+		writeBatchResult(w, r, request.Names, errs, BatchItemRented, "Burrows rented successfully")
+	}
+}
+
+// ReleaseBurrowsHandler frees every named burrow, or none of them when one is not rented. Callers
+// without the manager or admin role can only release the burrows they rent.
+func ReleaseBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request BatchBurrowsRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		errs := service.ReleaseBurrows(request.Names, releasingRenter(caller))
+		writeBatchResult(w, r, request.Names, errs, BatchItemReleased, "Burrows released successfully")
+	}
+}
+
+// writeBatchResult writes the outcome of every item of a batch: the items all have the status
+// done when no error is set, otherwise the batch failed with a conflict listing the items.
+func writeBatchResult(w http.ResponseWriter, r *http.Request, names []string, errs []error, done, message string) {
+	items := make([]BatchItemResult, len(names))
+	failed := 0
+	for i, name := range names {
+		items[i] = BatchItemResult{Name: name, Status: done}
+		if errs[i] == nil {
+			continue
+		}
+
+		items[i].Status = BatchItemFailed
+		items[i].Code = errorCode(errs[i])
+		items[i].Message = errs[i].Error()
+		failed++
+	}
+
+	if failed > 0 {
+		for i := range items {
+			if items[i].Status == done {
+				items[i].Status = BatchItemAborted
+			}
+		}
+
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(Problem{
+			Type:     problemTypePrefix + CodeBatchFailed,
+			Title:    http.StatusText(http.StatusConflict),
+			Status:   http.StatusConflict,
+			Detail:   fmt.Sprintf("%d of %d burrows failed, none was changed", failed, len(names)),
+			Instance: r.URL.Path,
+			Code:     CodeBatchFailed,
+			Items:    items,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JSONResponse{
+		Status:  "success",
+		Message: message,
+		Data:    BatchBurrowsResponse{Items: items},
+	})
+}
@@ -0,0 +1,92 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+func postBatch(handler http.Handler, body string, principal *auth.Principal) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/burrows/rent:batch", strings.NewReader(body))
+	if principal != nil {
+		req = req.WithContext(auth.WithPrincipal(req.Context(), principal))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestRentBurrowsHandler_AllOrNothing(t *testing.T) {
+	service := newTestService(t)
+	require.NoError(t, service.AddBurrow(&models.Burrow{Name: "The Shallow Den", Depth: 1, Width: 1}))
+	handler := api.RentBurrowsHandler(service)
+	bob := &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}}
+
+	// The Molehole is rented by alice: nothing is rented.
+	rec := postBatch(handler, `{"names":["The Deep Den","The Molehole","Nowhere"]}`, bob)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, api.CodeBatchFailed, problem.Code)
+	assert.Equal(t, []api.BatchItemResult{
+		{Name: "The Deep Den", Status: api.BatchItemAborted},
+		{Name: "The Molehole", Status: api.BatchItemFailed, Code: models.ErrBurrowUnavailable.Code, Message: "The Molehole: burrow not available"},
+		{Name: "Nowhere", Status: api.BatchItemFailed, Code: models.ErrBurrowNotFound.Code, Message: "Nowhere: burrow not found"},
+	}, problem.Items)
+	burrow, err := service.GetBurrow("The Deep Den")
+	require.NoError(t, err)
+	assert.False(t, burrow.Occupied)
+
+	rec = postBatch(handler, `{"names":["The Deep Den","The Shallow Den"]}`, bob)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response struct {
+		Data api.BatchBurrowsResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, []api.BatchItemResult{
+		{Name: "The Deep Den", Status: api.BatchItemRented},
+		{Name: "The Shallow Den", Status: api.BatchItemRented},
+	}, response.Data.Items)
+	for _, name := range []string{"The Deep Den", "The Shallow Den"} {
+		burrow, err := service.GetBurrow(name)
+		require.NoError(t, err)
+		assert.Equal(t, "bob", burrow.RentedBy)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, postBatch(handler, `{"names":[]}`, bob).Code)
+}
+
+func TestReleaseBurrowsHandler_OnlyRenterReleases(t *testing.T) {
+	service := newTestService(t)
+	require.NoError(t, service.RentBurrow("The Deep Den", "bob"))
+	handler := api.ReleaseBurrowsHandler(service)
+	body := `{"names":["The Deep Den","The Molehole"]}`
+
+	// The Molehole is rented by alice: bob releases nothing.
+	rec := postBatch(handler, body, &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}})
+	require.Equal(t, http.StatusConflict, rec.Code)
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, api.BatchItemAborted, problem.Items[0].Status)
+	assert.Equal(t, api.CodeForbidden, problem.Items[1].Code)
+	burrow, err := service.GetBurrow("The Deep Den")
+	require.NoError(t, err)
+	assert.True(t, burrow.Occupied)
+
+	// Managers release any burrow.
+	rec = postBatch(handler, body, &auth.Principal{Subject: "carol", Roles: []string{auth.RoleManager}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	for _, burrow := range service.GetAllBurrows() {
+		assert.False(t, burrow.Occupied)
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/billing"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// maxDescriptionLength bounds the length of the descriptions of the ledger entries.
+const maxDescriptionLength = 200
+
+// AddLedgerEntryRequest is the payload of the add ledger entry endpoint. Amount is in minor units,
+// negative for refunds.
+type AddLedgerEntryRequest struct {
+	Kind        models.EntryKind `json:"kind"`
+	Amount      int64            `json:"amount"`
+	Currency    string           `json:"currency,omitempty"`
+	RentalID    string           `json:"rentalId,omitempty"`
+	Description string           `json:"description"`
+}
+
+func (req *AddLedgerEntryRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.check(req.Kind == models.EntryRefund || req.Kind == models.EntryAdjustment, "kind", "must be refund or adjustment")
+	if req.Kind == models.EntryRefund {
+		v.check(req.Amount < 0, "amount", "must be negative for a refund")
+	} else {
+		v.check(req.Amount != 0, "amount", "must not be zero")
+	}
+	v.check(req.Currency == "" || len(req.Currency) == 3, "currency", "must be a 3 letters currency code")
+	v.requiredString(req.Description, "description", maxDescriptionLength)
+
+	return v.errors
+}
+
+// AddLedgerEntryHandler appends a refund or an adjustment to the ledger of the renter having the
+// "id" path parameter.
+func AddLedgerEntryHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request AddLedgerEntryRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		entry, err := service.AddLedgerEntry(&models.LedgerEntry{
+			Renter:      PathParam(r, "id"),
+			Kind:        request.Kind,
+			Amount:      request.Amount,
+			Currency:    request.Currency,
+			RentalID:    request.RentalID,
+			Description: request.Description,
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Ledger entry added successfully",
+			Data:    entry,
+		})
+	}
+}
+
+// GetInvoicesHandler writes the monthly invoices of the renter having the "id" path parameter, as
+// JSON (the default), CSV or HTML as the format query parameter says. The period query parameter
+// (YYYY-MM) selects the invoices of a month. Callers without the manager or admin role can only
+// read their invoices.
+func GetInvoicesHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renter := PathParam(r, "id")
+		if _, ok := authorizeRenter(w, r, renter, "only the renter can read their invoices"); !ok {
+			return
+		}
+
+		var v fieldValidator
+		format := billing.FormatJSON
+		if value := r.URL.Query().Get("format"); value != "" {
+			var err error
+			format, err = billing.ParseFormat(value)
+			v.check(err == nil, "format", "must be one of json, csv or html")
+		}
+		period := r.URL.Query().Get("period")
+		if period != "" {
+			_, err := time.Parse(billing.PeriodLayout, period)
+			v.check(err == nil, "period", "must be a month written YYYY-MM")
+		}
+		if len(v.errors) > 0 {
+			writeValidationProblem(w, r, v.errors)
+			return
+		}
+
+		invoices := billing.Invoices(renter, service.LedgerEntries(renter))
+		if period != "" {
+			selected := invoices[:0]
+			for _, invoice := range invoices {
+				if invoice.Period == period {
+					selected = append(selected, invoice)
+				}
+			}
+			invoices = selected
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		if format == billing.FormatCSV {
+			w.Header().Set("Content-Disposition", attachment("invoices-"+renter+".csv"))
+		}
+		billing.Write(w, format, invoices)
+	}
+}
+
+// attachment returns the Content-Disposition of an attachment with the filename, quoted and escaped,
+// or without a filename when it cannot be represented.
+func attachment(filename string) string {
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename}); disposition != "" {
+		return disposition
+	}
+	return "attachment"
+}
@@ -0,0 +1,92 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/billing"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestBilling(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	repo := repository.NewMemoryRepository("", "")
+	repo.SetClock(clk)
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}))
+
+	engine, err := pricing.NewEngine(config.Pricing{Currency: "EUR", BasePrice: 2400}, clk)
+	require.NoError(t, err)
+	service := services.NewGopherNetService(repo)
+	service.SetPricing(engine)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	call := func(subject, role, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Roles: []string{role}}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// A day of usage, accrued every hour.
+	require.Equal(t, http.StatusOK, call("alice", auth.RoleRenter, http.MethodPost, "/burrows/rent", `{"name":"The Deep Den"}`).Code)
+	for i := 0; i < 24; i++ {
+		clk.Advance(time.Hour)
+		service.AccrueUsage()
+	}
+
+	// Refunds are negative, and charges are only accrued.
+	refund := `{"kind":"refund","amount":-400,"description":"Flooded tunnel"}`
+	assert.Equal(t, http.StatusBadRequest, call("carol", auth.RoleManager, http.MethodPost, "/renters/alice/ledger", `{"kind":"refund","amount":400,"description":"Flooded tunnel"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call("carol", auth.RoleManager, http.MethodPost, "/renters/alice/ledger", `{"kind":"charge","amount":400,"description":"Extra"}`).Code)
+	assert.Equal(t, http.StatusNotFound, call("carol", auth.RoleManager, http.MethodPost, "/renters/alice/ledger", `{"kind":"adjustment","amount":1,"rentalId":"nope","description":"Fix"}`).Code)
+	rec := call("carol", auth.RoleManager, http.MethodPost, "/renters/alice/ledger", refund)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	// Renters only read their invoices.
+	assert.Equal(t, http.StatusForbidden, call("bob", auth.RoleRenter, http.MethodGet, "/renters/alice/invoices", "").Code)
+	assert.Equal(t, http.StatusBadRequest, call("alice", auth.RoleRenter, http.MethodGet, "/renters/alice/invoices?format=pdf", "").Code)
+
+	rec = call("alice", auth.RoleRenter, http.MethodGet, "/renters/alice/invoices", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var invoices []billing.Invoice
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&invoices))
+	require.Len(t, invoices, 1)
+	assert.Equal(t, "2024-03", invoices[0].Period)
+	assert.Equal(t, "EUR", invoices[0].Currency)
+	assert.Len(t, invoices[0].Entries, 25)
+	assert.Equal(t, int64(2000), invoices[0].Total)
+
+	rec = call("carol", auth.RoleManager, http.MethodGet, "/renters/alice/invoices?format=html&period=2024-03", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, billing.FormatHTML.ContentType(), rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "20.00 EUR")
+
+	rec = call("alice", auth.RoleRenter, http.MethodGet, "/renters/alice/invoices?format=csv&period=2024-04", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "invoice,period,currency,createdAt,kind,rentalId,burrow,description,amount\n", rec.Body.String())
+	assert.Equal(t, `attachment; filename=invoices-alice.csv`, rec.Header().Get("Content-Disposition"))
+
+	// The filename is quoted whatever the renter.
+	rec = call("carol", auth.RoleManager, http.MethodGet, "/renters/a%22b%3B%20x/invoices?format=csv", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="invoices-a\"b; x.csv"`, rec.Header().Get("Content-Disposition"))
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/burrowio"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// CodeUnsupportedMediaType is the code of the problem raised when a request body has an unsupported format.
+const CodeUnsupportedMediaType = "unsupported_media_type"
+
+// exportFlushRows is the number of burrows written between two flushes of an export.
+const exportFlushRows = 100
+
+// ImportBurrowsResponse is the data returned by the import endpoint.
+type ImportBurrowsResponse struct {
+	Mode    string           `json:"mode"`
+	DryRun  bool             `json:"dryRun"`
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Failed  int              `json:"failed"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// ImportRowError describes why a row of an import failed. Rows are numbered from 1, the CSV
+// header being row 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Name    string `json:"name,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ImportBurrowsHandler adds the burrows of a CSV or NDJSON body, reporting the failed rows.
+// The format is read from the format query parameter or the Content-Type header. The mode query
+// parameter handles the existing burrows (create, upsert or skip-existing) and dryRun=true
+// reports the outcome without changing anything.
+func ImportBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		var v fieldValidator
+		mode := services.ImportCreate
+		if value := query.Get("mode"); value != "" {
+			var err error
+			mode, err = services.ParseImportMode(value)
+			v.check(err == nil, "mode", fmt.Sprintf("must be one of %v", services.ImportModes))
+		}
+		dryRun := false
+		if value := query.Get("dryRun"); value != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(value)
+			v.check(err == nil, "dryRun", "must be a boolean")
+		}
+		if len(v.errors) > 0 {
+			writeValidationProblem(w, r, v.errors)
+			return
+		}
+
+		format, ok := requestFormat(r)
+		if !ok {
+			writeProblem(w, r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+				"Set the format parameter, or the Content-Type header, to csv, ndjson or json")
+			return
+		}
+
+		records, err := burrowio.ReadAll(r.Body, format)
+		if err != nil {
+			writeDecodeProblem(w, r, err)
+			return
+		}
+		for i := range records {
+			validateImportRecord(&records[i])
+		}
+
+		result, err := service.ImportBurrows(r.Context(), records, mode, dryRun)
+		if err != nil {
+			// The client is gone, or the endpoint timeout answered it: the remaining rows are left out.
+			logmgr.GetLogger().LogWarning(r.Context(), fmt.Sprintf("import of %d row(s) interrupted after %d row(s): %v", len(records), len(result.Rows), err))
+			return
+		}
+
+		response := ImportBurrowsResponse{
+			Mode:    string(mode),
+			DryRun:  dryRun,
+			Created: result.Count(services.ImportCreated),
+			Updated: result.Count(services.ImportUpdated),
+			Skipped: result.Count(services.ImportSkipped),
+			Errors:  []ImportRowError{},
+		}
+		for _, row := range result.Failures() {
+			response.Errors = append(response.Errors, ImportRowError{
+				Row:     row.Row,
+				Name:    row.Name,
+				Code:    errorCode(row.Err),
+				Message: row.Err.Error(),
+			})
+		}
+		response.Failed = len(response.Errors)
+
+		message := "Burrows imported"
+		if dryRun {
+			message = "Dry run completed, nothing was imported"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: message,
+			Data:    response,
+		})
+	}
+}
+
+// ExportBurrowsHandler streams every burrow as CSV or NDJSON (the default), as the format query
+// parameter says.
+func ExportBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := burrowio.FormatNDJSON
+		if value := r.URL.Query().Get("format"); value != "" {
+			var err error
+			if format, err = burrowio.ParseFormat(value); err != nil {
+				writeValidationProblem(w, r, []FieldError{{Field: "format", Message: "must be one of csv, ndjson or json"}})
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="burrows.%s"`, format))
+
+		flusher, _ := w.(http.Flusher)
+		writer := burrowio.NewWriter(w, format)
+		for i, burrow := range service.GetAllBurrows() {
+			if err := writer.Write(burrow); err != nil {
+				return
+			}
+			if flusher != nil && (i+1)%exportFlushRows == 0 {
+				flusher.Flush()
+			}
+		}
+		writer.Close()
+	}
+}
+
+// requestFormat returns the format of the request body, from the format query parameter or
+// the Content-Type header.
+func requestFormat(r *http.Request) (burrowio.Format, bool) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, err := burrowio.ParseFormat(value)
+		return format, err == nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	for _, format := range burrowio.Formats {
+		if strings.EqualFold(format.ContentType(), mediaType) {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// validateImportRecord applies the validation of the add endpoint to the burrow of the record.
+func validateImportRecord(record *burrowio.Record) {
+	if record.Err != nil {
+		return
+	}
+
+	burrow := record.Burrow
+	request := AddBurrowRequest{Name: burrow.Name, Depth: burrow.Depth, Width: burrow.Width, Age: burrow.Age}
+	fieldErrors := request.Validate()
+	if len(fieldErrors) == 0 {
+		return
+	}
+
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		messages = append(messages, fieldError.Field+" "+fieldError.Message)
+	}
+	record.Err = errors.New(strings.Join(messages, ", "))
+}
+
+// errorCode returns the code of the domain error wrapped by err, or the internal error code.
+func errorCode(err error) string {
+	var domainErr *models.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+
+	return CodeInternalError
+}
@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func newBulkRouter(t *testing.T) (*api.Router, *services.DefaultBurrowService) {
+	cfg := loadTestConfig(t)
+	service := newTestService(t)
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), cfg.Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	return router, service
+}
+
+func importBurrows(t *testing.T, router http.Handler, query, contentType, body string) (int, api.ImportBurrowsResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/burrows/import"+query, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response struct {
+		Data api.ImportBurrowsResponse `json:"data"`
+	}
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	}
+
+	return rec.Code, response.Data
+}
+
+const survey = "name,depth,width\nThe Survey Den,1.5,1.1\nThe Molehole,4,1.4\nThe Flat Den,1,0\n"
+
+func TestImportBurrows_Modes(t *testing.T) {
+	router, service := newBulkRouter(t)
+
+	// A dry run reports the outcome without importing.
+	code, response := importBurrows(t, router, "?dryRun=true", "text/csv", survey)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, response.Created)
+	assert.Equal(t, 2, response.Failed)
+	require.Len(t, service.GetAllBurrows(), 2)
+
+	code, response = importBurrows(t, router, "", "text/csv; charset=utf-8", survey)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, api.ImportBurrowsResponse{
+		Mode:    "create",
+		Created: 1,
+		Failed:  2,
+		Errors: []api.ImportRowError{
+			{Row: 3, Name: "The Molehole", Code: models.ErrBurrowAlreadyExists.Code, Message: "The Molehole: burrow already exists"},
+			{Row: 4, Name: "The Flat Den", Code: models.ErrInvalidBurrow.Code, Message: "width must be greater than 0 and at most 100 meters: invalid burrow"},
+		},
+	}, response)
+	require.Len(t, service.GetAllBurrows(), 3)
+
+	code, response = importBurrows(t, router, "?mode=skip-existing", "application/x-ndjson", `{"name":"The Molehole","depth":4,"width":1.4}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, response.Skipped)
+
+	// Upserts update the dimensions of the existing burrows and keep their rental.
+	code, response = importBurrows(t, router, "?mode=upsert&format=ndjson", "", `{"name":"The Molehole","depth":4,"width":1.4}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, response.Updated)
+	burrow, err := service.GetBurrow("The Molehole")
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, burrow.Depth)
+	assert.True(t, burrow.Occupied)
+}
+
+func TestImportBurrows_InvalidRequests(t *testing.T) {
+	router, _ := newBulkRouter(t)
+
+	code, _ := importBurrows(t, router, "", "application/xml", "<burrows/>")
+	assert.Equal(t, http.StatusUnsupportedMediaType, code)
+
+	code, _ = importBurrows(t, router, "?mode=replace", "text/csv", survey)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// A CSV header missing required columns fails the whole document.
+	code, _ = importBurrows(t, router, "", "text/csv", "name,depth\nThe Survey Den,1\n")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestImportBurrows_StopsWhenTheClientIsGone(t *testing.T) {
+	router, service := newBulkRouter(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/burrows/import", strings.NewReader(survey)).WithContext(ctx)
+	req.Header.Set("Content-Type", "text/csv")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	_, err := service.GetBurrow("The Survey Den")
+	assert.ErrorIs(t, err, models.ErrBurrowNotFound)
+}
+
+func TestExportBurrows(t *testing.T) {
+	router, _ := newBulkRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows/export?format=csv", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="burrows.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "name,depth,width,occupied,age,rentedBy\nThe Molehole,3,1.3,true,50,alice\nThe Deep Den,2.2,1.2,false,40,\n", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.Len(t, strings.Split(strings.TrimSpace(rec.Body.String()), "\n"), 2)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows/export?format=xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,118 @@
+// Renders the OpenAPI document served at specURL, and sends requests to its operations.
+(function () {
+  const operations = document.getElementById("operations");
+  const apiKey = document.getElementById("api-key");
+
+  function element(tag, attributes, children) {
+    const node = document.createElement(tag);
+    Object.entries(attributes || {}).forEach(([name, value]) => node.setAttribute(name, value));
+    (children || []).forEach((child) => node.append(child));
+    return node;
+  }
+
+  // describe replaces the references of a schema by the referenced schemas, up to a few levels.
+  function describe(spec, schema, depth) {
+    if (!schema) {
+      return undefined;
+    }
+    if (schema.$ref) {
+      const name = schema.$ref.split("/").pop();
+      return depth > 3 ? name : describe(spec, spec.components.schemas[name], depth + 1);
+    }
+    if (schema.type === "array") {
+      return [describe(spec, schema.items, depth)];
+    }
+    if (schema.type === "object" && schema.properties) {
+      const object = {};
+      Object.entries(schema.properties).forEach(([name, property]) => {
+        object[name] = describe(spec, property, depth);
+      });
+      return object;
+    }
+    return schema.enum ? schema.enum.join(" | ") : schema.format || schema.type || "any";
+  }
+
+  function schemaBlock(spec, title, content) {
+    const nodes = [];
+    Object.entries(content || {}).forEach(([mediaType, media]) => {
+      nodes.push(element("h4", {}, [title + " (" + mediaType + ")"]));
+      if (media.schema) {
+        nodes.push(element("pre", {}, [JSON.stringify(describe(spec, media.schema, 0), null, 2)]));
+      }
+    });
+    return nodes;
+  }
+
+  function send(method, path, inputs, body, output) {
+    let url = path;
+    inputs.forEach((input) => {
+      url = url.replace("{" + input.name + "}", encodeURIComponent(input.value));
+    });
+
+    const headers = {};
+    if (apiKey.value) {
+      headers["X-API-Key"] = apiKey.value;
+    }
+    const request = { method: method.toUpperCase(), headers: headers };
+    if (body) {
+      headers["Content-Type"] = "application/json";
+      request.body = body.value;
+    }
+
+    output.textContent = "…";
+    fetch(url, request)
+      .then((response) => response.text().then((text) => {
+        output.textContent = response.status + " " + response.statusText + "\n\n" + text;
+      }))
+      .catch((err) => {
+        output.textContent = String(err);
+      });
+  }
+
+  function operationNode(spec, path, method, operation) {
+    const children = [];
+    const inputs = (operation.parameters || []).map((parameter) => {
+      const input = element("input", { name: parameter.name, placeholder: parameter.name });
+      children.push(element("p", {}, [element("label", {}, [parameter.name + " (" + parameter.in + ") ", input])]));
+      return input;
+    });
+
+    let body = null;
+    if (operation.requestBody) {
+      children.push(...schemaBlock(spec, "Request", operation.requestBody.content));
+      body = element("textarea", { "aria-label": "Request body" });
+      children.push(body);
+    }
+    Object.entries(operation.responses || {}).forEach(([status, response]) => {
+      children.push(element("h4", {}, [status + " " + response.description]));
+      children.push(...schemaBlock(spec, "Response", response.content));
+    });
+
+    const output = element("pre", {});
+    const button = element("button", { type: "button" }, ["Send"]);
+    button.addEventListener("click", () => send(method, path, inputs, body, output));
+    children.push(button, output);
+
+    const summary = element("summary", {}, [
+      element("span", { class: "method " + method }, [method.toUpperCase()]),
+      path,
+      element("span", { class: "summary" }, [operation.summary || operation.operationId]),
+    ]);
+    return element("details", {}, [summary, element("div", { class: "operation" }, children)]);
+  }
+
+  fetch(specURL)
+    .then((response) => response.json())
+    .then((spec) => {
+      document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+      operations.replaceChildren();
+      Object.keys(spec.paths).sort().forEach((path) => {
+        Object.entries(spec.paths[path]).forEach(([method, operation]) => {
+          operations.append(operationNode(spec, path, method, operation));
+        });
+      });
+    })
+    .catch((err) => {
+      operations.replaceChildren(element("p", { class: "error" }, ["Cannot load " + specURL + ": " + err]));
+    });
+})();
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/graphqlapi"
+)
+
+// graphQLMutationEndpoints binds the mutations to the endpoint whose rate limit applies to them.
+var graphQLMutationEndpoints = map[string]string{
+	"rentBurrow":    "rent-burrow",
+	"releaseBurrow": "release-burrow",
+}
+
+// GraphQLRequest is the payload of the GraphQL endpoint.
+type GraphQLRequest struct {
+	graphqlapi.Request
+}
+
+func (req *GraphQLRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.check(req.Query != "", "query", "is required")
+
+	return v.errors
+}
+
+// GraphQLHandler executes GraphQL requests. Malformed requests get a problem, while the errors of
+// the queries themselves are reported in the "errors" of the GraphQL response. Every mutation
+// takes a token from the rate limiter of its endpoint in limits, the request being refused with a
+// 429 problem when one is missing.
+func GraphQLHandler(executor *graphqlapi.Executor, limits *RateLimits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request GraphQLRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		for _, mutation := range executor.Mutations(request.Request) {
+			key, ok := graphQLMutationEndpoints[mutation]
+			if !ok {
+				continue
+			}
+			if decision := limits.endpoint(key).allow(r); !decision.Allowed {
+				writeRateLimited(w, r, decision)
+				return
+			}
+		}
+
+		result := executor.Execute(r.Context(), request.Request)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
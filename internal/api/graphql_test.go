@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+)
+
+func TestGraphQLEndpoint(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false // anonymous callers, limited by IP
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"query":"{ burrow(name: \"The Deep Den\") { name occupied } }"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":{"burrow":{"name":"The Deep Den","occupied":false}}}`, rec.Body.String())
+
+	// Query errors are reported in the GraphQL response, malformed requests get a problem.
+	rec = post(`{"query":"{ burrows { unknown } }"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"errors"`)
+
+	rec = post(`{"variables":{}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, api.ProblemContentType, rec.Header().Get("Content-Type"))
+}
+
+func TestGraphQLEndpoint_MutationsAreRateLimited(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false // anonymous callers, limited by IP
+	endpoint := cfg.Rest.Endpoints["rent-burrow"]
+	endpoint.RateLimit = config.RateLimit{Requests: 1, Period: time.Minute}
+	cfg.Rest.Endpoints["rent-burrow"] = endpoint
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/burrows/rent", strings.NewReader(`{"name":"The Deep Den"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// The mutation shares the bucket of the rent endpoint.
+	rec = httptest.NewRecorder()
+	server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql",
+		strings.NewReader(`{"query":"mutation { rentBurrow(name: \"The Molehole\") { name } }"}`)))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
@@ -3,7 +3,15 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
 	"github.com/marcodd23/gopernet/internal/services"
 )
 
@@ -14,14 +22,135 @@ type JSONResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// maxNameLength bounds the length of the burrow and job names.
+const maxNameLength = 100
+
+// RentBurrowRequest is the payload of the rent endpoint.
+type RentBurrowRequest struct {
+	Name string `json:"name"`
+}
+
+func (req *RentBurrowRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.requiredString(req.Name, "name", maxNameLength)
+
+	return v.errors
+}
+
+// AddBurrowRequest is the payload of the add burrow endpoint.
+type AddBurrowRequest struct {
+	Name  string  `json:"name"`
+	Depth float64 `json:"depth"`
+	Width float64 `json:"width"`
+	Age   int     `json:"age,omitempty"` // in minutes
+}
+
+func (req *AddBurrowRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.requiredString(req.Name, "name", maxNameLength)
+	v.check(req.Depth >= 0 && req.Depth <= 1000, "depth", "must be between 0 and 1000 meters")
+	v.check(req.Width > 0 && req.Width <= 100, "width", "must be greater than 0 and at most 100 meters")
+	v.check(req.Age >= 0, "age", "must not be negative")
+
+	return v.errors
+}
+
+// UpdateBurrowRequest is the payload of the update burrow endpoint.
+type UpdateBurrowRequest struct {
+	Depth float64 `json:"depth"`
+	Width float64 `json:"width"`
+	Age   int     `json:"age,omitempty"` // in minutes
+}
+
+func (req *UpdateBurrowRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.check(req.Depth >= 0 && req.Depth <= 1000, "depth", "must be between 0 and 1000 meters")
+	v.check(req.Width > 0 && req.Width <= 100, "width", "must be greater than 0 and at most 100 meters")
+	v.check(req.Age >= 0, "age", "must not be negative")
+
+	return v.errors
+}
+
+// RentBurrowResponse is the data returned by the rent endpoint.
+type RentBurrowResponse struct {
+	Name string `json:"name"`
+}
+
+// ReleaseBurrowRequest is the payload of the release endpoint.
+type ReleaseBurrowRequest struct {
+	Name string `json:"name"`
+}
+
+func (req *ReleaseBurrowRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.requiredString(req.Name, "name", maxNameLength)
+
+	return v.errors
+}
+
+// ReleaseBurrowResponse is the data returned by the release endpoint.
+type ReleaseBurrowResponse struct {
+	Name string `json:"name"`
+}
+
+// RunJobRequest is the payload of the run job endpoint.
+type RunJobRequest struct {
+	Job string `json:"job"`
+}
+
+func (req *RunJobRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.requiredString(req.Job, "job", maxNameLength)
+
+	return v.errors
+}
+
+// RunJobResponse is the data returned by the run job endpoint.
+type RunJobResponse struct {
+	Job string `json:"job"`
+}
+
+// RunJobWait is how long the run job endpoint waits for the run to finish before answering
+// 202 Accepted, the run going on in the background.
+const RunJobWait = 2 * time.Second
+
+// JobRunner runs background jobs on demand.
+type JobRunner interface {
+	// RunJob starts a run of the named job; the returned channel gets its error once it is done.
+	RunJob(name string) (<-chan error, error)
+	// JobStatus returns the status of the last run of the named job.
+	JobStatus(name string) (async.JobStatus, error)
+}
+
+// callerPrincipal returns the caller of the request, stored by AuthMiddleware or AnonymousMiddleware.
+// Without one, it writes a 401 problem and returns false.
+func callerPrincipal(w http.ResponseWriter, r *http.Request) (*auth.Principal, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Missing or invalid credentials")
+	}
+
+	return principal, ok
+}
+
+// isManager reports whether the principal has the manager or admin role.
+func isManager(principal *auth.Principal) bool {
+	return principal.HasAnyRole(auth.RoleManager, auth.RoleAdmin)
+}
+
+// releasingRenter returns the renter whose burrows the principal can release, or "" when the
+// principal can release any burrow.
+func releasingRenter(principal *auth.Principal) string {
+	if isManager(principal) {
+		return ""
+	}
+
+	return principal.Subject
+}
+
 // GetBurrowsHandler returns the list of burrows.
 func GetBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
 		burrows := service.GetAllBurrows()
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(JSONResponse{
@@ -31,31 +160,39 @@ func GetBurrowsHandler(service *services.DefaultBurrowService) http.HandlerFunc
 	}
 }
 
-// RentBurrowHandler allows a burrow to be rented.
-func RentBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+// GetBurrowHandler returns the burrow named by the "name" path parameter.
+func GetBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		burrow, err := service.GetBurrow(PathParam(r, "name"))
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
 
-		var request struct {
-			Name string `json:"name"`
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   burrow,
+		})
+	}
+}
+
+// RentBurrowHandler allows a burrow to be rented by the authenticated caller.
+func RentBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request RentBurrowRequest
+		if !decodeJSON(w, r, &request) {
+			return
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
 			return
 		}
 
-		err := service.RentBurrow(request.Name)
+		err := service.RentBurrow(request.Name, caller.Subject)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(JSONResponse{
-				Status:  "error",
-				Message: err.Error(),
-			})
+			writeError(w, r, err)
 			return
 		}
 
@@ -65,28 +202,105 @@ func RentBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc
 		json.NewEncoder(w).Encode(JSONResponse{
 			Status:  "success",
 			Message: "Burrow rented successfully",
-			Data:    map[string]string{"name": request.Name},
+			Data:    RentBurrowResponse{Name: request.Name},
 		})
 	}
 }
 
-// GenerateReportHandler generates a report of the current state of the burrows.
-func GenerateReportHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+// ReleaseBurrowHandler frees a rented burrow. Callers without the manager or admin role can only
+// release the burrows they rent.
+func ReleaseBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request ReleaseBurrowRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		if err := service.ReleaseBurrow(request.Name, releasingRenter(caller)); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Burrow released successfully",
+			Data:    ReleaseBurrowResponse{Name: request.Name},
+		})
+	}
+}
+
+// AddBurrowHandler adds a new burrow.
+func AddBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		var request AddBurrowRequest
+		if !decodeJSON(w, r, &request) {
 			return
 		}
 
+		burrow := models.Burrow{
+			Name:  request.Name,
+			Depth: request.Depth,
+			Width: request.Width,
+			Age:   request.Age,
+		}
+
+		if err := service.AddBurrow(&burrow); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Burrow added successfully",
+			Data:    burrow,
+		})
+	}
+}
+
+// UpdateBurrowHandler replaces the depth, width and age of a burrow, keeping its rental.
+func UpdateBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request UpdateBurrowRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		name := PathParam(r, "name")
+		if err := service.UpdateBurrow(&models.Burrow{Name: name, Depth: request.Depth, Width: request.Width, Age: request.Age}); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		burrow, err := service.GetBurrow(name)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Burrow updated successfully",
+			Data:    burrow,
+		})
+	}
+}
+
+// GenerateReportHandler generates a report of the current state of the burrows.
+func GenerateReportHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 		// Generate the report
 		report, err := service.GenerateReport()
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(JSONResponse{
-				Status:  "error",
-				Message: "Failed to generate report",
-			})
+			writeError(w, r, err)
 			return
 		}
 
@@ -98,3 +312,114 @@ func GenerateReportHandler(service *services.DefaultBurrowService) http.HandlerF
 		})
 	}
 }
+
+// ConfigHandler returns the active configuration of store, its secrets redacted.
+func ConfigHandler(store *config.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Configuration retrieved successfully",
+			Data:    config.Document(store.Current().Redacted()),
+		})
+	}
+}
+
+// ReadinessHandler reports whether the service is ready to serve traffic.
+func ReadinessHandler(checker *health.Checker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Check(r.Context())
+
+		if !report.Ready {
+			var failing []string
+			for name, outcome := range report.Checks {
+				if outcome != health.CheckOK {
+					failing = append(failing, name)
+				}
+			}
+			sort.Strings(failing)
+
+			w.Header().Set("Content-Type", ProblemContentType)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(Problem{
+				Type:     problemTypePrefix + CodeNotReady,
+				Title:    http.StatusText(http.StatusServiceUnavailable),
+				Status:   http.StatusServiceUnavailable,
+				Detail:   "Failing checks: " + strings.Join(failing, ", "),
+				Instance: r.URL.Path,
+				Code:     CodeNotReady,
+				Checks:   report.Checks,
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   report,
+		})
+	}
+}
+
+// RunJobHandler runs a background job immediately. When the run does not finish within wait, it
+// answers 202 Accepted with the status of the job, to poll on the job status endpoint. A failed
+// run gets a 500 problem.
+func RunJobHandler(jobs JobRunner, wait time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request RunJobRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		done, err := jobs.RunJob(request.Job)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				writeProblem(w, r, http.StatusInternalServerError, CodeJobFailed, err.Error())
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(JSONResponse{
+				Status:  "success",
+				Message: "Job executed successfully",
+				Data:    RunJobResponse{Job: request.Job},
+			})
+		case <-timer.C:
+			writeJobStatus(w, r, jobs, request.Job, http.StatusAccepted, "Job is running")
+		case <-r.Context().Done():
+			writeJobStatus(w, r, jobs, request.Job, http.StatusAccepted, "Job is running")
+		}
+	}
+}
+
+// JobStatusHandler returns the status of the last run of a background job.
+func JobStatusHandler(jobs JobRunner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJobStatus(w, r, jobs, PathParam(r, "name"), http.StatusOK, "")
+	}
+}
+
+func writeJobStatus(w http.ResponseWriter, r *http.Request, jobs JobRunner, name string, code int, message string) {
+	status, err := jobs.JobStatus(name)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(JSONResponse{
+		Status:  "success",
+		Message: message,
+		Data:    status,
+	})
+}
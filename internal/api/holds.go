@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// HoldBurrowHandler holds the burrow named by the "name" path parameter for the authenticated
+// caller while they complete their checkout.
+func HoldBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		hold, err := service.HoldBurrow(PathParam(r, "name"), caller.Subject)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Burrow held successfully",
+			Data:    hold,
+		})
+	}
+}
+
+// ConfirmHoldHandler rents the burrow of the hold having the "id" path parameter to the renter of
+// the hold. Callers without the manager or admin role can only confirm their holds.
+func ConfirmHoldHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := PathParam(r, "id")
+
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		if !isManager(caller) {
+			hold, err := service.GetHold(id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			if hold.Renter != caller.Subject {
+				writeProblem(w, r, http.StatusForbidden, CodeForbidden, "only the renter of the hold can confirm it")
+				return
+			}
+		}
+
+		hold, err := service.ConfirmHold(id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Hold confirmed, burrow rented successfully",
+			Data:    hold,
+		})
+	}
+}
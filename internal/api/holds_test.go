@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestHolds(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := repository.NewMemoryRepository("", "")
+	repo.SetClock(clk)
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2}))
+	service := services.NewGopherNetService(repo)
+	service.SetHoldTTL(10 * time.Minute)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	call := func(subject, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Roles: []string{auth.RoleRenter}}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+	hold := func(subject string) (int, models.Hold) {
+		rec := call(subject, "/burrows/The%20Deep%20Den/hold", "")
+		var response struct {
+			Data models.Hold `json:"data"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		return rec.Code, response.Data
+	}
+
+	code, aliceHold := hold("alice")
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "alice", aliceHold.Renter)
+	assert.Equal(t, clk.Now().Add(10*time.Minute), aliceHold.ExpiresAt.UTC())
+
+	// The held burrow is blocked for bob, who cannot confirm the hold of alice either.
+	code, _ = hold("bob")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, http.StatusConflict, call("bob", "/burrows/rent", `{"name":"The Deep Den"}`).Code)
+	assert.Equal(t, http.StatusForbidden, call("bob", "/holds/"+aliceHold.ID+"/confirm", "").Code)
+
+	// Once expired, the hold cannot be confirmed and bob can hold the burrow.
+	clk.Advance(10 * time.Minute)
+	assert.Equal(t, http.StatusGone, call("alice", "/holds/"+aliceHold.ID+"/confirm", "").Code)
+	code, bobHold := hold("bob")
+	require.Equal(t, http.StatusCreated, code)
+
+	assert.Equal(t, http.StatusOK, call("bob", "/holds/"+bobHold.ID+"/confirm", "").Code)
+	burrow, err := service.GetBurrow("The Deep Den")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", burrow.RentedBy)
+	assert.Equal(t, http.StatusNotFound, call("bob", "/holds/"+bobHold.ID+"/confirm", "").Code)
+}
@@ -0,0 +1,326 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// RequestIDHeader carries the request ID, both in the requests and in the responses.
+const RequestIDHeader = "X-Request-ID"
+
+// CodeTimeout is the code of the problem returned when a handler exceeds its timeout.
+const CodeTimeout = "timeout"
+
+// maxRequestIDLength bounds the length of the request IDs accepted from the clients.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID of the request being served.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Chain applies the middlewares to the handler, the first one being the outermost.
+func Chain(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// RequestIDMiddleware propagates the X-Request-ID of the request, generating one when missing or
+// invalid, through the request context and the response headers.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// AccessLogMiddleware logs every request once it has been served.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(recorder, r)
+
+		logAccess(r, recorder.statusCode(), recorder.bytes, time.Since(start))
+	})
+}
+
+func logAccess(r *http.Request, status int, bytes int64, duration time.Duration) {
+	if zLog, ok := logmgr.GetLogger().GetLogger().(*zerolog.Logger); ok {
+		level, severity := zerolog.InfoLevel, "INFO"
+		if status >= http.StatusInternalServerError {
+			level, severity = zerolog.ErrorLevel, "ERROR"
+		}
+
+		zLog.WithLevel(level).
+			Str("severity", severity).
+			Str("requestId", RequestIDFromContext(r.Context())).
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Int("status", status).
+			Int64("bytes", bytes).
+			Dur("durationMs", duration).
+			Str("remoteAddr", r.RemoteAddr).
+			Str("userAgent", r.UserAgent()).
+			Msg("http request")
+		return
+	}
+
+	logmgr.GetLogger().LogInfo(r.Context(), fmt.Sprintf("http request requestId=%s method=%s path=%s status=%d bytes=%d duration=%s",
+		RequestIDFromContext(r.Context()), r.Method, r.URL.Path, status, bytes, duration))
+}
+
+// RecoveryMiddleware turns a panicking handler into a 500 problem, logging the panic and its stack.
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logmgr.GetLogger().LogError(r.Context(), fmt.Sprintf("panic serving %s %s (requestId=%s): %v\n%s",
+				r.Method, r.URL.Path, RequestIDFromContext(r.Context()), recovered, debug.Stack()))
+
+			if !recorder.wroteHeader {
+				writeProblem(w, r, http.StatusInternalServerError, CodeInternalError, "An unexpected error occurred")
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// TimeoutMiddleware cancels the request context after timeout and answers with a 503 problem if the
+// handler has not completed by then. The response is buffered until the handler returns, and
+// discarded once the timeout passed.
+// Handlers must stop on the cancellation of the request context: a handler panicking after the
+// timeout has its panic logged, its caller being gone.
+func TimeoutMiddleware(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+
+		tw := &timeoutWriter{header: make(http.Header)}
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+
+				if !tw.timedOut {
+					panicked <- recovered
+					return
+				}
+				logmgr.GetLogger().LogError(r.Context(), fmt.Sprintf("panic after the timeout serving %s %s (requestId=%s): %v\n%s",
+					r.Method, r.URL.Path, RequestIDFromContext(r.Context()), recovered, debug.Stack()))
+			}()
+			next.ServeHTTP(tw, r)
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			// Re-panic in the serving goroutine, so that the recovery middleware handles it.
+			panic(p)
+		case <-done:
+		case <-ctx.Done():
+		}
+
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+
+		if ctx.Err() == nil {
+			tw.writeTo(w)
+			return
+		}
+
+		// Once the deadline passed, the response of the handler is discarded, even when it has
+		// completed meanwhile; a panic raised before is still handled.
+		select {
+		case p := <-panicked:
+			panic(p)
+		default:
+		}
+
+		tw.timedOut = true
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			writeProblem(w, r, http.StatusServiceUnavailable, CodeTimeout,
+				fmt.Sprintf("The request did not complete within %s", timeout))
+		}
+	})
+}
+
+// timeoutWriter buffers the response of a handler run under TimeoutMiddleware.
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	code     int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+
+	return tw.body.Write(b)
+}
+
+// writeTo writes the buffered response to w. The caller holds tw.mu.
+func (tw *timeoutWriter) writeTo(w http.ResponseWriter) {
+	for key, values := range tw.header {
+		w.Header()[key] = values
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	w.WriteHeader(tw.code)
+	w.Write(tw.body.Bytes())
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = code
+}
+
+// statusRecorder records the status code and the size of a response.
+// It exposes the optional interfaces of the wrapped writer used by the streaming endpoints.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if !sr.wroteHeader {
+		sr.status = code
+		sr.wroteHeader = true
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if !sr.wroteHeader {
+		sr.WriteHeader(http.StatusOK)
+	}
+
+	n, err := sr.ResponseWriter.Write(b)
+	sr.bytes += int64(n)
+
+	return n, err
+}
+
+func (sr *statusRecorder) statusCode() int {
+	if sr.status == 0 {
+		return http.StatusOK
+	}
+
+	return sr.status
+}
+
+// Unwrap lets http.ResponseController reach the wrapped writer.
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		if !sr.wroteHeader {
+			sr.WriteHeader(http.StatusOK)
+		}
+		flusher.Flush()
+	}
+}
+
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+
+	// A hijacked connection is handed over to the handler: record it as a protocol switch.
+	sr.status = http.StatusSwitchingProtocols
+	sr.wroteHeader = true
+
+	return hijacker.Hijack()
+}
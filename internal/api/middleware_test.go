@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := api.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = api.RequestIDFromContext(r.Context())
+	}))
+
+	t.Run("propagates the request ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/burrows", nil)
+		req.Header.Set(api.RequestIDHeader, "abc-123")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", rec.Header().Get(api.RequestIDHeader))
+	})
+
+	t.Run("generates a request ID", func(t *testing.T) {
+		for _, incoming := range []string{"", "bad id\n", strings.Repeat("a", 200)} {
+			req := httptest.NewRequest(http.MethodGet, "/burrows", nil)
+			req.Header.Set(api.RequestIDHeader, incoming)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.NotEmpty(t, seen)
+			assert.NotEqual(t, incoming, seen)
+			assert.Equal(t, seen, rec.Header().Get(api.RequestIDHeader))
+		}
+	})
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	handler := api.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), api.RequestIDMiddleware, api.AccessLogMiddleware, api.RecoveryMiddleware)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, api.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rec.Header().Get(api.RequestIDHeader))
+
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, api.CodeInternalError, problem.Code)
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Run("times out a slow handler", func(t *testing.T) {
+		handler := api.TimeoutMiddleware(10*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			w.Write([]byte("too late"))
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var problem api.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+		assert.Equal(t, api.CodeTimeout, problem.Code)
+	})
+
+	t.Run("passes the response of a fast handler", func(t *testing.T) {
+		handler := api.TimeoutMiddleware(time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("done"))
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "done", rec.Body.String())
+	})
+
+	t.Run("panics reach the recovery middleware", func(t *testing.T) {
+		handler := api.RecoveryMiddleware(api.TimeoutMiddleware(time.Second, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("panics after the timeout are logged", func(t *testing.T) {
+		finished := make(chan struct{})
+		handler := api.RecoveryMiddleware(api.TimeoutMiddleware(10*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer close(finished)
+			// Wait for the timeout to be answered.
+			for _, err := w.Write(nil); err == nil; _, err = w.Write(nil) {
+				time.Sleep(time.Millisecond)
+			}
+			panic("boom")
+		})))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report", nil))
+		<-finished
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
@@ -0,0 +1,390 @@
+package api
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// OpenAPI is the root of an OpenAPI 3 document.
+type OpenAPI struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       OpenAPIInfo          `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// OpenAPIInfo holds the API metadata.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path, by lower case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter describes a path parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes the payload of an operation.
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a payload.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and the security schemes.
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes an authentication method.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Schema is the subset of the OpenAPI schema object generated from the Go types.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// NewOpenAPI generates the OpenAPI document of the routes, using the paths and methods
+// of their endpoint configuration and the Go types of their payloads.
+func NewOpenAPI(routes []Route, cfg *config.ServiceConfig) *OpenAPI {
+	spec := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: cfg.Name, Version: cfg.Version},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+
+	if cfg.Auth.Enabled {
+		spec.Components.SecuritySchemes = map[string]*SecurityScheme{
+			"apiKey":     {Type: "apiKey", In: "header", Name: APIKeyHeader},
+			"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+
+	generator := &schemaGenerator{components: spec.Components.Schemas}
+
+	for _, route := range routes {
+		endpoint, ok := cfg.Rest.Endpoints[route.Key]
+		if !ok {
+			continue
+		}
+
+		operation := &Operation{
+			OperationID: route.Key,
+			Summary:     route.Summary,
+			Responses:   make(map[string]*Response),
+		}
+
+		for _, segment := range splitPath(endpoint.Path) {
+			if name, ok := paramName(segment); ok {
+				operation.Parameters = append(operation.Parameters, &Parameter{
+					Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+				})
+			}
+		}
+
+		if route.Request != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(generator.schemaOf(reflect.TypeOf(route.Request))),
+			}
+		} else if len(route.RequestTypes) > 0 {
+			operation.RequestBody = &RequestBody{Required: true, Content: rawContent(route.RequestTypes)}
+		}
+
+		content := jsonContent(generator.envelope(route.Response))
+		if len(route.ResponseTypes) > 0 {
+			content = rawContent(route.ResponseTypes)
+		}
+		operation.Responses[statusKey(route.SuccessStatus)] = &Response{
+			Description: "Successful response",
+			Content:     content,
+		}
+		operation.Responses["default"] = &Response{
+			Description: "Error response (RFC 7807 problem details)",
+			Content: map[string]*MediaType{
+				ProblemContentType: {Schema: generator.schemaOf(reflect.TypeOf(Problem{}))},
+			},
+		}
+
+		if cfg.Auth.Enabled && !endpoint.Public {
+			operation.Security = []map[string][]string{{"apiKey": {}}, {"bearerAuth": {}}}
+		}
+
+		item, ok := spec.Paths[endpoint.Path]
+		if !ok {
+			item = &PathItem{}
+			spec.Paths[endpoint.Path] = item
+		}
+		(*item)[strings.ToLower(endpoint.Method)] = operation
+	}
+
+	return spec
+}
+
+// ResponseSchema returns the schema of the successful response of the operation bound to the
+// endpoint key, or nil if the operation does not exist.
+func (spec *OpenAPI) ResponseSchema(cfg *config.ServiceConfig, key string) *Schema {
+	endpoint := cfg.Rest.Endpoints[key]
+	item, ok := spec.Paths[endpoint.Path]
+	if !ok {
+		return nil
+	}
+
+	operation, ok := (*item)[strings.ToLower(endpoint.Method)]
+	if !ok {
+		return nil
+	}
+
+	for status, response := range operation.Responses {
+		if status != "default" && response.Content != nil {
+			return response.Content["application/json"].Schema
+		}
+	}
+
+	return nil
+}
+
+// Resolve follows a schema reference.
+func (spec *OpenAPI) Resolve(schema *Schema) *Schema {
+	if schema != nil && schema.Ref != "" {
+		return spec.Components.Schemas[strings.TrimPrefix(schema.Ref, schemaRefPrefix)]
+	}
+
+	return schema
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// rawContent documents bodies of the media types as strings.
+func rawContent(mediaTypes []string) map[string]*MediaType {
+	content := make(map[string]*MediaType, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+	}
+
+	return content
+}
+
+func statusKey(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return strconv.Itoa(status)
+}
+
+type schemaGenerator struct {
+	components map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// envelope returns the schema of a JSONResponse carrying data of the given type.
+func (g *schemaGenerator) envelope(data interface{}) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string", Enum: []string{"success", "error"}},
+			"message": {Type: "string"},
+		},
+		Required:             []string{"status"},
+		AdditionalProperties: false,
+	}
+
+	if data != nil {
+		schema.Properties["data"] = g.schemaOf(reflect.TypeOf(data))
+	}
+
+	return schema
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.componentRef(t)
+	default:
+		// interface{} and other dynamic values accept anything.
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) componentRef(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := g.components[name]; ok && existing != nil {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	// Register a placeholder first, so that recursive types terminate.
+	g.components[name] = &Schema{}
+	*g.components[name] = *g.structSchema(t)
+
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := g.structSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		schema.Properties[name] = g.schemaOf(field.Type)
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// OpenAPIHandler serves the OpenAPI document.
+func OpenAPIHandler(spec *OpenAPI) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(spec)
+	}
+}
+
+//go:embed docs
+var docsFiles embed.FS
+
+// docsPage is the documentation page, its style and script embedded so that it works offline.
+var docsPage = template.Must(template.ParseFS(docsFiles, "docs/index.html"))
+
+// DocsHandler serves an interactive documentation page rendering the OpenAPI document served at specPath.
+func DocsHandler(specPath string) http.HandlerFunc {
+	style, _ := docsFiles.ReadFile("docs/docs.css")
+	script, _ := docsFiles.ReadFile("docs/docs.js")
+
+	var page bytes.Buffer
+	if err := docsPage.Execute(&page, struct {
+		SpecURL string
+		Style   template.CSS
+		Script  template.JS
+	}{specPath, template.CSS(style), template.JS(script)}); err != nil {
+		panic(err)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	}
+}
@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/marcodd23/go-micro-core/pkg/configmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+type noopJobRunner struct{}
+
+func (noopJobRunner) RunJob(name string) (<-chan error, error) {
+	done := make(chan error, 1)
+	done <- nil
+	return done, nil
+}
+
+func (noopJobRunner) JobStatus(name string) (async.JobStatus, error) {
+	return async.JobStatus{Job: name, State: async.JobSucceeded}, nil
+}
+
+// loadTestConfig loads the property.yaml shipped with the service, so that the
+// tests exercise the real endpoints configuration.
+func loadTestConfig(t *testing.T) *config.ServiceConfig {
+	var cfg config.ServiceConfig
+	require.NoError(t, configmgr.ReadConfiguration("../../property.yaml", &cfg))
+
+	return &cfg
+}
+
+// asCaller serves the requests as the principal, standing for the authentication middleware.
+func asCaller(handler http.Handler, principal *auth.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func newTestService(t *testing.T) *services.DefaultBurrowService {
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 3.0, Width: 1.3, Occupied: true, Age: 50, RentedBy: "alice"}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2, Age: 40}))
+
+	engine, err := pricing.NewEngine(loadTestConfig(t).Pricing, clock.New())
+	require.NoError(t, err)
+
+	service := services.NewGopherNetService(repo)
+	service.SetPricing(engine)
+
+	return service
+}
+
+// TestOpenAPI_HandlersMatchSpec calls every documented handler and fails when a response
+// payload drifts from the schema published in the OpenAPI document.
+func TestOpenAPI_HandlersMatchSpec(t *testing.T) {
+	cfg := loadTestConfig(t)
+	routes := api.Routes(newTestService(t), health.NewChecker(), noopJobRunner{})
+	spec := api.NewOpenAPI(routes, cfg)
+
+	router, err := api.NewRouter(routes, cfg.Rest.Endpoints, nil)
+	require.NoError(t, err)
+	handler := asCaller(router, &auth.Principal{Subject: "alice", Roles: []string{auth.RoleAdmin}})
+	bob := asCaller(router, &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}})
+
+	requests := map[string]struct {
+		path   string
+		body   string
+		caller http.Handler
+	}{
+		"get-burrow":      {path: "/burrows/The%20Molehole"},
+		"quote-burrow":    {path: "/burrows/The%20Molehole/quote"},
+		"rent-burrow":     {body: `{"name":"The Deep Den"}`},
+		"release-burrow":  {body: `{"name":"The Molehole"}`},
+		"rent-burrows":    {body: `{"names":["The Molehole"]}`},
+		"release-burrows": {body: `{"names":["The Molehole","The Deep Den"]}`},
+		"hold-burrow":     {path: "/burrows/The%20Deep%20Den/hold"},
+		// The Deep Den is rented by alice once her hold is confirmed, bob waits for it.
+		"join-waitlist":         {path: "/burrows/The%20Deep%20Den/waitlist", caller: bob},
+		"get-waitlist-position": {path: "/burrows/The%20Deep%20Den/waitlist", caller: bob},
+		"leave-waitlist":        {path: "/burrows/The%20Deep%20Den/waitlist", caller: bob},
+		"add-renter":            {path: "/renters", body: `{"id":"dave","name":"Dave","contact":{"channel":"email","address":"dave@example.com"}}`},
+		"get-renter":            {path: "/renters/dave"},
+		"update-renter":         {path: "/renters/dave", body: `{"name":"Dave D.","contact":{"channel":"sms","address":"+14155550100"},"limits":{"maxConcurrentBurrows":2}}`},
+		"get-rentals":           {path: "/renters/dave/rentals"},
+		"delete-renter":         {path: "/renters/dave"},
+		"get-invoices":          {path: "/renters/alice/invoices?format=csv"},
+		"add-ledger-entry":      {path: "/renters/alice/ledger", body: `{"kind":"adjustment","amount":-150,"description":"Goodwill"}`},
+		"add-burrow":            {body: `{"name":"The New Den","depth":1.0,"width":1.1,"age":0}`},
+		"import-burrows":        {path: "/burrows/import?format=ndjson", body: `{"name":"The Survey Den","depth":1.0,"width":1.1}`},
+		"update-burrow":         {path: "/burrows/The%20New%20Den", body: `{"depth":1.5,"width":1.1,"age":10}`},
+		"run-job":               {body: `{"job":"report-generator"}`},
+		"get-job":               {path: "/admin/jobs/report-generator"},
+		"restore-backup":        {body: `{"burrows":[{"name":"The Restored Den","depth":1.0,"width":1.1}]}`},
+	}
+
+	for _, route := range routes {
+		endpoint := cfg.Rest.Endpoints[route.Key]
+		sample := requests[route.Key]
+		path := sample.path
+		if path == "" {
+			path = endpoint.Path
+		}
+
+		caller := sample.caller
+		if caller == nil {
+			caller = handler
+		}
+
+		rec := httptest.NewRecorder()
+		caller.ServeHTTP(rec, httptest.NewRequest(endpoint.Method, path, strings.NewReader(sample.body)))
+		require.Less(t, rec.Code, 300, "%s: %s", route.Key, rec.Body.String())
+		if len(route.ResponseTypes) > 0 {
+			assert.Contains(t, route.ResponseTypes, rec.Header().Get("Content-Type"), route.Key)
+			continue
+		}
+
+		schema := spec.ResponseSchema(cfg, route.Key)
+		require.NotNil(t, schema, "%s is missing from the OpenAPI document", route.Key)
+
+		var payload interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &payload), route.Key)
+		assert.Empty(t, validateSchema(spec, schema, payload, route.Key), "%s response drifted from the OpenAPI document", route.Key)
+
+		if route.Key == "hold-burrow" {
+			// Confirm the hold just created.
+			hold := payload.(map[string]interface{})["data"].(map[string]interface{})
+			confirm := requests["confirm-hold"]
+			confirm.path = "/holds/" + hold["id"].(string) + "/confirm"
+			requests["confirm-hold"] = confirm
+		}
+
+		if route.Request != nil {
+			var request interface{}
+			require.NoError(t, json.Unmarshal([]byte(sample.body), &request), route.Key)
+			requestSchema := spec.Paths[endpoint.Path]
+			operation := (*requestSchema)[strings.ToLower(endpoint.Method)]
+			assert.Empty(t, validateSchema(spec, operation.RequestBody.Content["application/json"].Schema, request, route.Key+" request"))
+		}
+	}
+}
+
+func TestOpenAPI_ServedDocument(t *testing.T) {
+	cfg := loadTestConfig(t)
+	routes := api.Routes(newTestService(t), health.NewChecker(), noopJobRunner{})
+	routes = append(routes, api.DocsRoutes(routes, cfg)...)
+
+	router, err := api.NewRouter(routes, cfg.Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var spec api.OpenAPI
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths, "/burrows/{name}")
+	assert.Contains(t, spec.Components.Schemas, "Burrow")
+	assert.Equal(t, "name", (*spec.Paths["/burrows/{name}"])["get"].Parameters[0].Name)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `const specURL = "/openapi.json";`)
+	assert.NotContains(t, rec.Body.String(), "https://", "the page must not load assets from a CDN")
+
+	// The spec path is escaped for the script it is written in.
+	rec = httptest.NewRecorder()
+	api.DocsHandler(`/spec";alert(1);//</script>`).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Contains(t, rec.Body.String(), `const specURL = "/spec\";alert(1);//\u003c/script\u003e";`)
+}
+
+// validateSchema returns the violations of value against schema.
+func validateSchema(spec *api.OpenAPI, schema *api.Schema, value interface{}, path string) []string {
+	schema = spec.Resolve(schema)
+	if schema == nil || schema.Type == "" {
+		return nil
+	}
+
+	var violations []string
+	fail := func(format string, args ...interface{}) []string {
+		return append(violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("expected object, got %T", value)
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				violations = fail("missing required property %q", name)
+			}
+		}
+
+		for name, property := range object {
+			if propertySchema, ok := schema.Properties[name]; ok {
+				violations = append(violations, validateSchema(spec, propertySchema, property, path+"."+name)...)
+				continue
+			}
+
+			switch additional := schema.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					violations = fail("unexpected property %q", name)
+				}
+			case *api.Schema:
+				violations = append(violations, validateSchema(spec, additional, property, path+"."+name)...)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fail("expected array, got %T", value)
+		}
+
+		for i, item := range items {
+			violations = append(violations, validateSchema(spec, schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fail("expected string, got %T", value)
+		}
+
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			return fail("%q is not one of %v", s, schema.Enum)
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			return fail("expected integer, got %v", value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fail("expected number, got %T", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("expected boolean, got %T", value)
+		}
+	}
+
+	return violations
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// ProblemContentType is the media type of the error responses (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of a problem to build its type URI.
+const problemTypePrefix = "urn:gophernet:problem:"
+
+// Codes of the problems raised by the API layer itself. Domain problems use the code of their models.Error.
+const (
+	CodeMalformedRequest = "malformed_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInternalError    = "internal_error"
+	CodeRateLimited      = "rate_limited"
+	CodeJobFailed        = "job_failed"
+	CodeNotReady         = "not_ready"
+)
+
+// Problem is an RFC 7807 problem details object, extended with a stable machine readable code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Errors lists the invalid fields of a validation problem.
+	Errors []FieldError `json:"errors,omitempty"`
+	// Items lists the outcome of every item of a failed batch.
+	Items []BatchItemResult `json:"items,omitempty"`
+	// Checks lists the outcome of every readiness check of a service that is not ready.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// statusByCode maps the codes of the domain errors to their HTTP status.
+var statusByCode = map[string]int{
+	models.ErrBurrowNotFound.Code:      http.StatusNotFound,
+	models.ErrBurrowUnavailable.Code:   http.StatusConflict,
+	models.ErrBurrowCollapsed.Code:     http.StatusGone,
+	models.ErrBurrowAlreadyExists.Code: http.StatusConflict,
+	models.ErrBurrowNotRented.Code:     http.StatusConflict,
+	models.ErrForbidden.Code:           http.StatusForbidden,
+	models.ErrInvalidBurrow.Code:       http.StatusBadRequest,
+	models.ErrBurrowHeld.Code:          http.StatusConflict,
+	models.ErrHoldNotFound.Code:        http.StatusNotFound,
+	models.ErrHoldExpired.Code:         http.StatusGone,
+	models.ErrBurrowAvailable.Code:     http.StatusConflict,
+	models.ErrAlreadyWaitlisted.Code:   http.StatusConflict,
+	models.ErrBurrowAlreadyTaken.Code:  http.StatusConflict,
+	models.ErrNotWaitlisted.Code:       http.StatusNotFound,
+	models.ErrRentalNotFound.Code:      http.StatusNotFound,
+	models.ErrInvalidLedgerEntry.Code:  http.StatusBadRequest,
+	models.ErrRenterNotFound.Code:      http.StatusNotFound,
+	models.ErrRenterAlreadyExists.Code: http.StatusConflict,
+	models.ErrInvalidRenter.Code:       http.StatusBadRequest,
+	models.ErrRenterOverQuota.Code:     http.StatusConflict,
+	models.ErrRenterHasRentals.Code:    http.StatusConflict,
+	models.ErrInvalidBackup.Code:       http.StatusBadRequest,
+	models.ErrPricingUnavailable.Code:  http.StatusServiceUnavailable,
+	async.ErrJobRunning.Code:           http.StatusConflict,
+	async.ErrUnknownJob.Code:           http.StatusNotFound,
+}
+
+// writeProblem writes a problem response.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblemWithErrors(w, r, status, code, detail, nil)
+}
+
+// writeProblemWithErrors writes a problem response listing the invalid fields.
+func writeProblemWithErrors(w http.ResponseWriter, r *http.Request, status int, code, detail string, fieldErrors []FieldError) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:     problemTypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+		Errors:   fieldErrors,
+	})
+}
+
+// writeError writes the problem matching err: domain errors are mapped to their status and
+// code, any other error is logged and reported as an internal error without leaking its details.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var domainErr *models.Error
+	if errors.As(err, &domainErr) {
+		status, ok := statusByCode[domainErr.Code]
+		if !ok {
+			status = http.StatusUnprocessableEntity
+		}
+
+		writeProblem(w, r, status, domainErr.Code, err.Error())
+		return
+	}
+
+	logmgr.GetLogger().LogError(r.Context(), fmt.Sprintf("error serving %s %s (requestId=%s)",
+		r.Method, r.URL.Path, RequestIDFromContext(r.Context())), err)
+	writeProblem(w, r, http.StatusInternalServerError, CodeInternalError, "An unexpected error occurred")
+}
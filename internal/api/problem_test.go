@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestProblems(t *testing.T) {
+	cfg := loadTestConfig(t)
+
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Occupied", Depth: 1, Width: 1, Occupied: true}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Collapsed", Depth: 1, Width: 1, Age: 25 * 24 * 60}))
+	service := services.NewGopherNetService(repo)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), cfg.Rest.Endpoints, nil)
+	require.NoError(t, err)
+	handler := asCaller(router, &auth.Principal{Subject: "alice", Roles: []string{auth.RoleManager}})
+
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		status       int
+		code         string
+	}{
+		{"unknown burrow", http.MethodPost, "/burrows/rent", `{"name":"Nowhere"}`, http.StatusNotFound, "burrow_not_found"},
+		{"occupied burrow", http.MethodPost, "/burrows/rent", `{"name":"Occupied"}`, http.StatusConflict, "burrow_unavailable"},
+		{"collapsed burrow", http.MethodPost, "/burrows/rent", `{"name":"Collapsed"}`, http.StatusGone, "burrow_collapsed"},
+		{"malformed body", http.MethodPost, "/burrows/rent", `{"name":`, http.StatusBadRequest, api.CodeMalformedRequest},
+		{"duplicated burrow", http.MethodPost, "/burrows", `{"name":"Occupied","depth":1,"width":1}`, http.StatusConflict, "burrow_already_exists"},
+		{"missing burrow", http.MethodGet, "/burrows/Nowhere", "", http.StatusNotFound, "burrow_not_found"},
+		{"wrong method", http.MethodDelete, "/burrows/rent", "", http.StatusMethodNotAllowed, api.CodeMethodNotAllowed},
+		{"unknown route", http.MethodGet, "/nowhere", "", http.StatusNotFound, api.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+
+		assert.Equal(t, tt.status, rec.Code, tt.name)
+		assert.Equal(t, api.ProblemContentType, rec.Header().Get("Content-Type"), tt.name)
+
+		var problem api.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem), tt.name)
+		assert.Equal(t, tt.code, problem.Code, tt.name)
+		assert.Equal(t, tt.status, problem.Status, tt.name)
+		assert.Equal(t, "urn:gophernet:problem:"+tt.code, problem.Type, tt.name)
+		assert.Equal(t, strings.Split(tt.path, "?")[0], problem.Instance, tt.name)
+	}
+}
+
+func TestReadinessHandler_NotReadyIsAProblem(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Register("persistence", func(ctx context.Context) error { return errors.New("circuit open") })
+	checker.Register("clock", func(ctx context.Context) error { return nil })
+
+	rec := httptest.NewRecorder()
+	api.ReadinessHandler(checker).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, api.ProblemContentType, rec.Header().Get("Content-Type"))
+
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, api.CodeNotReady, problem.Code)
+	assert.Equal(t, "Failing checks: persistence", problem.Detail)
+	assert.Equal(t, map[string]string{"persistence": "circuit open", "clock": health.CheckOK}, problem.Checks)
+}
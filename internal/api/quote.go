@@ -0,0 +1,25 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// QuoteBurrowHandler returns the daily price of renting the burrow named by the "name" path parameter.
+func QuoteBurrowHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		quote, err := service.QuoteBurrow(PathParam(r, "name"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   quote,
+		})
+	}
+}
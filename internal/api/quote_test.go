@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestQuoteBurrow(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	repo := repository.NewMemoryRepository("", "")
+	repo.SetClock(clk)
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 1, Width: 2}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Ruin", Depth: 1, Width: 2, Age: models.CurrentLifecycle().CollapseAge}))
+
+	engine, err := pricing.NewEngine(config.Pricing{
+		Currency:      "EUR",
+		BasePrice:     500,
+		PerCubicMeter: 200,
+		PerMeterDepth: 50,
+		Occupancy:     []config.OccupancySurge{{Above: 0.4, Multiplier: 2}},
+	}, clk)
+	require.NoError(t, err)
+	service := services.NewGopherNetService(repo)
+	service.SetPricing(engine)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "alice", Roles: []string{auth.RoleRenter}}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+	quote := func(name string) models.Quote {
+		rec := call(http.MethodGet, "/burrows/"+name+"/quote", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var response struct {
+			Data models.Quote `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+
+		return response.Data
+	}
+
+	// 500 + 200 * pi (volume) + 50 * 1 (depth), no standing burrow is rented.
+	denQuote := quote("The%20Deep%20Den")
+	assert.Equal(t, "EUR", denQuote.Currency)
+	assert.Equal(t, int64(1178), denQuote.Price)
+
+	assert.Equal(t, http.StatusNotFound, call(http.MethodGet, "/burrows/The%20Lost%20Den/quote", "").Code)
+	assert.Equal(t, http.StatusGone, call(http.MethodGet, "/burrows/The%20Ruin/quote", "").Code)
+
+	// The rental stores the quote given before it, its own rental not counting in the occupancy.
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/burrows/rent", `{"name":"The Deep Den"}`).Code)
+	burrow, err := service.GetBurrow("The Deep Den")
+	require.NoError(t, err)
+	require.NotNil(t, burrow.Quote)
+	assert.Equal(t, denQuote.Price, burrow.Quote.Price)
+
+	// Half of the standing burrows are now rented: the Molehole costs twice as much, also when
+	// rented through a hold.
+	assert.Equal(t, int64(2356), quote("The%20Molehole").Price)
+	rec := call(http.MethodPost, "/burrows/The%20Molehole/hold", "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var held struct {
+		Data models.Hold `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&held))
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/holds/"+held.Data.ID+"/confirm", "").Code)
+	burrow, err = service.GetBurrow("The Molehole")
+	require.NoError(t, err)
+	require.NotNil(t, burrow.Quote)
+	assert.Equal(t, int64(2356), burrow.Quote.Price)
+
+	// The quote ends with the rental.
+	require.Equal(t, http.StatusOK, call(http.MethodPost, "/burrows/release", `{"name":"The Deep Den"}`).Code)
+	burrow, err = service.GetBurrow("The Deep Den")
+	require.NoError(t, err)
+	assert.Nil(t, burrow.Quote)
+}
+
+func TestQuoteBurrow_WithoutPricing(t *testing.T) {
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}))
+
+	router, err := api.NewRouter(api.Routes(services.NewGopherNetService(repo), health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	asCaller(router, &auth.Principal{Subject: "alice", Roles: []string{auth.RoleRenter}}).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows/The%20Deep%20Den/quote", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, models.ErrPricingUnavailable.Code, problem.Code)
+}
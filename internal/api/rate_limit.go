@@ -0,0 +1,202 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/resilience"
+)
+
+// Headers describing the rate limit of the client.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitMiddleware rate limits every client: the authenticated principal when there is one,
+// whose API key or token identifies it, otherwise the client IP. Limited requests get a 429
+// problem with a Retry-After header. Every response carries the X-RateLimit-* headers.
+func RateLimitMiddleware(limiter *resilience.RateLimiter, next http.Handler) http.Handler {
+	return rateLimit(limiter, rateLimitKey, true, next)
+}
+
+// IPRateLimitMiddleware rate limits every client IP, whoever the caller is. It runs before the
+// authentication, to slow down the clients guessing credentials. The X-RateLimit-* headers are
+// left to the endpoint limit.
+func IPRateLimitMiddleware(limiter *resilience.RateLimiter, next http.Handler) http.Handler {
+	return rateLimit(limiter, ipRateLimitKey, false, next)
+}
+
+// rateLimit rate limits the clients identified by key, describing the limit in the response
+// headers when headers is true.
+func rateLimit(limiter *resilience.RateLimiter, key func(*http.Request) string, headers bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision := limiter.Allow(key(r))
+
+		if headers {
+			w.Header().Set(RateLimitLimitHeader, strconv.Itoa(decision.Limit))
+			w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(decision.Remaining))
+			w.Header().Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(decision.Reset)))
+		}
+
+		if !decision.Allowed {
+			writeRateLimited(w, r, decision)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeRateLimited answers a limited request with a 429 problem and a Retry-After header.
+func writeRateLimited(w http.ResponseWriter, r *http.Request, decision resilience.Decision) {
+	retryAfter := ceilSeconds(decision.RetryAfter)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeProblem(w, r, http.StatusTooManyRequests, CodeRateLimited,
+		"Too many requests, retry in "+strconv.Itoa(retryAfter)+"s")
+}
+
+// RateLimits holds the rate limiter of every endpoint, and the one of the client IPs, updated on
+// every reload of the configuration. Handlers performing the operation of another endpoint share
+// its limiter.
+type RateLimits struct {
+	clock clock.Clock
+	ip    *endpointRateLimiter
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointRateLimiter
+}
+
+// NewRateLimits creates the rate limiters of the endpoints of store, measured with clk.
+func NewRateLimits(clk clock.Clock, store *config.Store) *RateLimits {
+	cfg := store.Current()
+	l := &RateLimits{
+		clock:     clk,
+		ip:        newEndpointRateLimiter(clk, cfg.Rest.RateLimit, IPRateLimitMiddleware),
+		endpoints: make(map[string]*endpointRateLimiter, len(cfg.Rest.Endpoints)),
+	}
+	for key, endpoint := range cfg.Rest.Endpoints {
+		l.endpoints[key] = newEndpointRateLimiter(clk, endpoint.RateLimit, RateLimitMiddleware)
+	}
+
+	store.OnChange(func(_, cfg *config.ServiceConfig) {
+		l.ip.set(cfg.Rest.RateLimit)
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for key, limiter := range l.endpoints {
+			limiter.set(cfg.Rest.Endpoints[key].RateLimit)
+		}
+	})
+
+	return l
+}
+
+// endpoint returns the limiter of the endpoint, which does not limit unknown endpoints.
+func (l *RateLimits) endpoint(key string) *endpointRateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limiter, ok := l.endpoints[key]
+	if !ok {
+		limiter = newEndpointRateLimiter(l.clock, config.RateLimit{}, RateLimitMiddleware)
+		l.endpoints[key] = limiter
+	}
+
+	return limiter
+}
+
+// endpointRateLimiter rate limits an endpoint with its configured limit, which can be reloaded.
+// Changing the limit starts with full buckets.
+type endpointRateLimiter struct {
+	clock      clock.Clock
+	middleware func(*resilience.RateLimiter, http.Handler) http.Handler
+
+	mu      sync.RWMutex
+	limit   config.RateLimit
+	limiter *resilience.RateLimiter // nil when the endpoint is not rate limited
+}
+
+// newEndpointRateLimiter returns a limiter applying limit through middleware, RateLimitMiddleware
+// or IPRateLimitMiddleware.
+func newEndpointRateLimiter(clk clock.Clock, limit config.RateLimit, middleware func(*resilience.RateLimiter, http.Handler) http.Handler) *endpointRateLimiter {
+	l := &endpointRateLimiter{clock: clk, middleware: middleware}
+	l.set(limit)
+
+	return l
+}
+
+func (l *endpointRateLimiter) set(limit config.RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit == l.limit && (l.limiter != nil) == (limit.Requests > 0) {
+		return
+	}
+
+	l.limit = limit
+	l.limiter = nil
+	if limit.Requests > 0 {
+		l.limiter = resilience.NewRateLimiter(l.clock, limit.Requests, limit.Period, limit.Burst)
+	}
+}
+
+// allow takes a token from the bucket of the client of r. Requests to an endpoint without rate
+// limit are always allowed.
+func (l *endpointRateLimiter) allow(r *http.Request) resilience.Decision {
+	l.mu.RLock()
+	limiter := l.limiter
+	l.mu.RUnlock()
+
+	if limiter == nil {
+		return resilience.Decision{Allowed: true}
+	}
+
+	return limiter.Allow(rateLimitKey(r))
+}
+
+func (l *endpointRateLimiter) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.mu.RLock()
+		limiter := l.limiter
+		l.mu.RUnlock()
+
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l.middleware(limiter, next).ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey identifies the client of a request: its authenticated subject, or its IP for the
+// anonymous callers.
+func rateLimitKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Method != auth.MethodNone {
+		return "principal:" + principal.Subject
+	}
+
+	return ipRateLimitKey(r)
+}
+
+// ipRateLimitKey identifies the client of a request by its IP.
+func ipRateLimitKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
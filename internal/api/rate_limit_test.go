@@ -0,0 +1,139 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/resilience"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false // anonymous callers, limited by IP
+	endpoint := cfg.Rest.Endpoints["get-burrows"]
+	endpoint.RateLimit = config.RateLimit{Requests: 2, Period: time.Minute}
+	cfg.Rest.Endpoints["get-burrows"] = endpoint
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clk, config.NewStore(cfg))
+	require.NoError(t, err)
+
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/burrows", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	rec := get("192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(api.RateLimitLimitHeader))
+	assert.Equal(t, "1", rec.Header().Get(api.RateLimitRemainingHeader))
+	assert.Equal(t, "30", rec.Header().Get(api.RateLimitResetHeader))
+
+	assert.Equal(t, http.StatusOK, get("192.0.2.1:1235").Code)
+
+	rec = get("192.0.2.1:1236")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+	assert.Equal(t, "0", rec.Header().Get(api.RateLimitRemainingHeader))
+
+	var problem api.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem))
+	assert.Equal(t, api.CodeRateLimited, problem.Code)
+
+	// Other clients and other endpoints are not affected.
+	assert.Equal(t, http.StatusOK, get("192.0.2.2:1234").Code)
+	assert.Empty(t, httptestGet(server.Handler, "/health/ready").Header().Get(api.RateLimitLimitHeader))
+
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, http.StatusOK, get("192.0.2.1:1234").Code)
+}
+
+func TestRateLimit_Reload(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false // anonymous callers, limited by IP
+	store := config.NewStore(cfg)
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), store)
+	require.NoError(t, err)
+	assert.Empty(t, httptestGet(server.Handler, "/burrows").Header().Get(api.RateLimitLimitHeader))
+
+	next := loadTestConfig(t)
+	next.Auth.Enabled = false
+	endpoint := next.Rest.Endpoints["get-burrows"]
+	endpoint.RateLimit = config.RateLimit{Requests: 1, Period: time.Minute}
+	next.Rest.Endpoints["get-burrows"] = endpoint
+	assert.Empty(t, store.Reload(next))
+
+	assert.Equal(t, "1", httptestGet(server.Handler, "/burrows").Header().Get(api.RateLimitLimitHeader))
+	assert.Equal(t, http.StatusTooManyRequests, httptestGet(server.Handler, "/burrows").Code)
+
+	// Removing the limit lifts it.
+	store.Reload(cfg)
+	assert.Equal(t, http.StatusOK, httptestGet(server.Handler, "/burrows").Code)
+}
+
+func TestRateLimitMiddleware_KeysByPrincipal(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := resilience.NewRateLimiter(clk, 1, time.Minute, 1)
+	handler := api.RateLimitMiddleware(limiter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	call := func(subject, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, "/burrows/rent", strings.NewReader(`{}`))
+		req.RemoteAddr = remoteAddr
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	// The same caller is limited from any address, different callers share no bucket.
+	assert.Equal(t, http.StatusOK, call("alice", "192.0.2.1:1234"))
+	assert.Equal(t, http.StatusTooManyRequests, call("alice", "192.0.2.2:1234"))
+	assert.Equal(t, http.StatusOK, call("bob", "192.0.2.1:1234"))
+}
+
+func httptestGet(handler http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec
+}
+
+func TestRateLimit_ClientIPBeforeAuthentication(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Rest.RateLimit = config.RateLimit{Requests: 2, Period: time.Minute}
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	guess := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/burrows", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, guess("guess-1"))
+	assert.Equal(t, http.StatusUnauthorized, guess("guess-2"))
+	// Further guesses are refused before their key is checked, valid keys included.
+	assert.Equal(t, http.StatusTooManyRequests, guess("guess-3"))
+	assert.Equal(t, http.StatusTooManyRequests, guess("local-dev-key"))
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// maxAddressLength bounds the length of the contact addresses of the renters.
+const maxAddressLength = 200
+
+// RenterProfile is the editable part of a renter account.
+type RenterProfile struct {
+	Name    string              `json:"name"`
+	Contact models.Contact      `json:"contact"`
+	Limits  models.RenterLimits `json:"limits,omitempty"`
+}
+
+func (req *RenterProfile) validate(v *fieldValidator) {
+	v.requiredString(req.Name, "name", maxNameLength)
+	v.check(slices.Contains(models.ContactChannels, req.Contact.Channel), "contact.channel", "must be email, sms or webhook")
+	v.requiredString(req.Contact.Address, "contact.address", maxAddressLength)
+	v.check(req.Limits.MaxConcurrentBurrows >= 0, "limits.maxConcurrentBurrows", "must not be negative")
+}
+
+// CreateRenterRequest is the payload of the add renter endpoint. ID is the subject the renter
+// authenticates as.
+type CreateRenterRequest struct {
+	ID string `json:"id"`
+	RenterProfile
+}
+
+func (req *CreateRenterRequest) Validate() []FieldError {
+	var v fieldValidator
+	v.requiredString(req.ID, "id", maxNameLength)
+	req.RenterProfile.validate(&v)
+
+	return v.errors
+}
+
+// UpdateRenterRequest is the payload of the update renter endpoint.
+type UpdateRenterRequest struct {
+	RenterProfile
+}
+
+func (req *UpdateRenterRequest) Validate() []FieldError {
+	var v fieldValidator
+	req.RenterProfile.validate(&v)
+
+	return v.errors
+}
+
+// DeleteRenterResponse is the data returned by the delete renter endpoint.
+type DeleteRenterResponse struct {
+	ID string `json:"id"`
+}
+
+// GetRentersHandler returns the list of renters.
+func GetRentersHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renters := service.GetAllRenters()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   renters,
+		})
+	}
+}
+
+// AddRenterHandler creates a renter account.
+func AddRenterHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request CreateRenterRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		renter, err := service.AddRenter(&models.Renter{
+			ID:      request.ID,
+			Name:    request.Name,
+			Contact: request.Contact,
+			Limits:  request.Limits,
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Renter added successfully",
+			Data:    renter,
+		})
+	}
+}
+
+// GetRenterHandler returns the renter having the "id" path parameter. Callers without the manager
+// or admin role can only read their own account.
+func GetRenterHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := PathParam(r, "id")
+		if _, ok := authorizeRenter(w, r, id, "only the renter can read their account"); !ok {
+			return
+		}
+
+		renter, err := service.GetRenter(id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   renter,
+		})
+	}
+}
+
+// UpdateRenterHandler replaces the profile of the renter having the "id" path parameter. Callers
+// without the manager or admin role can only update their own account, and not its limits.
+func UpdateRenterHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := PathParam(r, "id")
+		caller, ok := authorizeRenter(w, r, id, "only the renter can update their account")
+		if !ok {
+			return
+		}
+
+		var request UpdateRenterRequest
+		if !decodeJSON(w, r, &request) {
+			return
+		}
+
+		if !isManager(caller) {
+			current, err := service.GetRenter(id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			if current.Limits != request.Limits {
+				writeProblem(w, r, http.StatusForbidden, CodeForbidden, "only a manager can change the limits of a renter")
+				return
+			}
+		}
+
+		renter, err := service.UpdateRenter(&models.Renter{
+			ID:      id,
+			Name:    request.Name,
+			Contact: request.Contact,
+			Limits:  request.Limits,
+		})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Renter updated successfully",
+			Data:    renter,
+		})
+	}
+}
+
+// DeleteRenterHandler deletes the renter having the "id" path parameter, who must not rent any
+// burrow. Their rental history and ledger are kept.
+func DeleteRenterHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := PathParam(r, "id")
+		if err := service.DeleteRenter(id); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Renter deleted successfully",
+			Data:    DeleteRenterResponse{ID: id},
+		})
+	}
+}
+
+// GetRentalsHandler returns the current and past rentals of the renter having the "id" path
+// parameter, oldest first, or a 404 problem when the renter has neither an account nor rentals.
+// Callers without the manager or admin role can only read their own.
+func GetRentalsHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renter := PathParam(r, "id")
+		if _, ok := authorizeRenter(w, r, renter, "only the renter can read their rentals"); !ok {
+			return
+		}
+
+		rentals, err := service.GetRentals(renter)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   rentals,
+		})
+	}
+}
+
+// authorizeRenter returns the caller when they may act on the renter: managers and admins on any
+// renter, others on themselves. Otherwise it writes a 401 or a 403 problem with the detail, and
+// returns false.
+func authorizeRenter(w http.ResponseWriter, r *http.Request, renter, detail string) (*auth.Principal, bool) {
+	caller, ok := callerPrincipal(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	if !isManager(caller) && caller.Subject != renter {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, detail)
+		return nil, false
+	}
+
+	return caller, true
+}
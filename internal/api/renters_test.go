@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func TestRenters(t *testing.T) {
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 1, Width: 2}))
+	service := services.NewGopherNetService(repo)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	call := func(subject, role, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Roles: []string{role}}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	// Contact addresses must match their channel.
+	assert.Equal(t, http.StatusBadRequest, call("carol", auth.RoleManager, http.MethodPost, "/renters", `{"id":"alice","name":"Alice","contact":{"channel":"fax","address":"123"}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, call("carol", auth.RoleManager, http.MethodPost, "/renters", `{"id":"alice","name":"Alice","contact":{"channel":"sms","address":"alice@example.com"}}`).Code)
+	alice := `{"id":"alice","name":"Alice","contact":{"channel":"email","address":"alice@example.com"},"limits":{"maxConcurrentBurrows":1}}`
+	require.Equal(t, http.StatusCreated, call("carol", auth.RoleManager, http.MethodPost, "/renters", alice).Code)
+	assert.Equal(t, http.StatusConflict, call("carol", auth.RoleManager, http.MethodPost, "/renters", alice).Code)
+
+	// Renters read and update their own account, but not its limits.
+	assert.Equal(t, http.StatusForbidden, call("bob", auth.RoleRenter, http.MethodGet, "/renters/alice", "").Code)
+	assert.Equal(t, http.StatusForbidden, call("alice", auth.RoleRenter, http.MethodPut, "/renters/alice", `{"name":"Alice","contact":{"channel":"email","address":"alice@example.com"},"limits":{"maxConcurrentBurrows":5}}`).Code)
+	rec := call("alice", auth.RoleRenter, http.MethodPut, "/renters/alice", `{"name":"Alice A.","contact":{"channel":"webhook","address":"https://alice.example.com/hook"},"limits":{"maxConcurrentBurrows":1}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = call("alice", auth.RoleRenter, http.MethodGet, "/renters/alice", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var renter struct {
+		Data models.Renter `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &renter))
+	assert.Equal(t, "Alice A.", renter.Data.Name)
+	assert.Equal(t, models.ContactWebhook, renter.Data.Contact.Channel)
+
+	// A renter over quota is refused.
+	require.Equal(t, http.StatusOK, call("alice", auth.RoleRenter, http.MethodPost, "/burrows/rent", `{"name":"The Deep Den"}`).Code)
+	assert.Equal(t, http.StatusConflict, call("alice", auth.RoleRenter, http.MethodPost, "/burrows/rent", `{"name":"The Molehole"}`).Code)
+
+	assert.Equal(t, http.StatusForbidden, call("bob", auth.RoleRenter, http.MethodGet, "/renters/alice/rentals", "").Code)
+	assert.Equal(t, http.StatusNotFound, call("carol", auth.RoleManager, http.MethodGet, "/renters/dave/rentals", "").Code)
+	rec = call("alice", auth.RoleRenter, http.MethodGet, "/renters/alice/rentals", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var rentals struct {
+		Data []models.Rental `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rentals))
+	require.Len(t, rentals.Data, 1)
+	assert.Equal(t, "The Deep Den", rentals.Data[0].Burrow)
+	assert.Nil(t, rentals.Data[0].EndedAt)
+
+	// Renters still renting a burrow cannot be deleted.
+	assert.Equal(t, http.StatusConflict, call("carol", auth.RoleManager, http.MethodDelete, "/renters/alice", "").Code)
+	require.Equal(t, http.StatusOK, call("alice", auth.RoleRenter, http.MethodPost, "/burrows/release", `{"name":"The Deep Den"}`).Code)
+	assert.Equal(t, http.StatusOK, call("carol", auth.RoleManager, http.MethodDelete, "/renters/alice", "").Code)
+	assert.Equal(t, http.StatusNotFound, call("carol", auth.RoleManager, http.MethodGet, "/renters/alice", "").Code)
+}
+
+func TestRenters_AnonymousRentals(t *testing.T) {
+	repo := repository.NewMemoryRepository("", "")
+	names := []string{"Burrow1", "Burrow2", "Burrow3", "Burrow4"}
+	for _, name := range names {
+		require.NoError(t, repo.AddBurrow(&models.Burrow{Name: name, Depth: 1, Width: 2}))
+	}
+	service := services.NewGopherNetService(repo)
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+	handler := api.AnonymousMiddleware(router)
+
+	// Without authentication every caller is the anonymous renter, not limited by default.
+	for _, name := range names {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/burrows/rent", strings.NewReader(`{"name":"`+name+`"}`)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+	burrow, err := service.GetBurrow("Burrow4")
+	require.NoError(t, err)
+	assert.Equal(t, auth.AnonymousSubject, burrow.RentedBy)
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// Route binds a handler to the endpoint configured under Key in config.Rest.Endpoints.
+// Request and Response are zero values of the request payload and of the response data
+// types, used to document the route in the OpenAPI document; nil means no payload.
+// RequestTypes and ResponseTypes list the media types of the bodies that are not JSON, documented
+// instead of Request and Response.
+type Route struct {
+	Key           string
+	Handler       http.Handler
+	Summary       string
+	Request       interface{}
+	Response      interface{}
+	RequestTypes  []string
+	ResponseTypes []string
+	SuccessStatus int
+}
+
+// Middleware decorates the handler of the endpoint configured under key.
+type Middleware func(key string, endpoint config.Endpoint, next http.Handler) http.Handler
+
+// Router dispatches requests to the routes by method and path. Paths can contain
+// parameters written as "{name}", read by the handlers through PathParam.
+type Router struct {
+	routes []*boundRoute
+}
+
+type boundRoute struct {
+	key      string
+	method   string
+	path     string
+	segments []string
+	literals int
+	handler  http.Handler
+}
+
+// NewRouter binds every route to its endpoint configuration, wrapping its handler with the middleware.
+// It fails when a route key is bound twice, has no endpoint configured, has an empty path or an unknown
+// method, or when two endpoints share the same method and path.
+func NewRouter(routes []Route, endpoints map[string]config.Endpoint, middleware Middleware) (*Router, error) {
+	router := &Router{}
+	keys := make(map[string]bool, len(routes))
+	patterns := make(map[string]string, len(routes))
+
+	for _, route := range routes {
+		if keys[route.Key] {
+			return nil, errors.Errorf("endpoint %q is bound to more than one handler", route.Key)
+		}
+		keys[route.Key] = true
+
+		endpoint, ok := endpoints[route.Key]
+		if !ok {
+			return nil, errors.Errorf("endpoint %q is missing from rest.endpoints", route.Key)
+		}
+
+		method := strings.ToUpper(strings.TrimSpace(endpoint.Method))
+		if !config.HTTPMethods[method] {
+			return nil, errors.Errorf("endpoint %q: unknown HTTP method %q", route.Key, endpoint.Method)
+		}
+
+		if !strings.HasPrefix(endpoint.Path, "/") {
+			return nil, errors.Errorf("endpoint %q: path %q must start with /", route.Key, endpoint.Path)
+		}
+
+		segments := splitPath(endpoint.Path)
+		pattern := method + " " + normalizePattern(segments)
+		if other, exists := patterns[pattern]; exists {
+			return nil, errors.Errorf("endpoints %q and %q are both bound to %s %s", other, route.Key, method, endpoint.Path)
+		}
+		patterns[pattern] = route.Key
+
+		handler := route.Handler
+		if middleware != nil {
+			handler = middleware(route.Key, endpoint, handler)
+		}
+
+		router.routes = append(router.routes, &boundRoute{
+			key:      route.Key,
+			method:   method,
+			path:     endpoint.Path,
+			segments: segments,
+			literals: literalSegments(segments),
+			handler:  handler,
+		})
+	}
+
+	// Literal segments take precedence over parameters: "/burrows/export" wins over "/burrows/{name}".
+	sort.SliceStable(router.routes, func(i, j int) bool {
+		return router.routes[i].literals > router.routes[j].literals
+	})
+
+	return router, nil
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := splitPath(r.URL.Path)
+
+	var (
+		allowed  []string
+		literals int
+	)
+	for _, route := range rt.routes {
+		if len(allowed) > 0 && route.literals < literals {
+			// The path matched a more literal route, which does not fall back on the parameters:
+			// GET /burrows/rent is not GET /burrows/{name}.
+			break
+		}
+
+		params, ok := route.match(segments)
+		if !ok {
+			continue
+		}
+		literals = route.literals
+
+		if route.method != r.Method && !(route.method == http.MethodGet && r.Method == http.MethodHead) {
+			allowed = append(allowed, route.method)
+			continue
+		}
+
+		if len(params) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, params))
+		}
+		route.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Allowed methods: "+strings.Join(allowed, ", "))
+		return
+	}
+
+	writeProblem(w, r, http.StatusNotFound, CodeNotFound, "No route matches "+r.URL.Path)
+}
+
+// UnboundEndpoints returns the sorted keys of the endpoints that no route is bound to.
+func UnboundEndpoints(routes []Route, endpoints map[string]config.Endpoint) []string {
+	bound := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		bound[route.Key] = true
+	}
+
+	var unbound []string
+	for key := range endpoints {
+		if !bound[key] {
+			unbound = append(unbound, key)
+		}
+	}
+	sort.Strings(unbound)
+
+	return unbound
+}
+
+type pathParamsKey struct{}
+
+// PathParam returns the value of the named path parameter of the matched route.
+func PathParam(r *http.Request, name string) string {
+	params, _ := r.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
+
+func (route *boundRoute) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(route.segments) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, segment := range route.segments {
+		if name, ok := paramName(segment); ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func paramName(segment string) (string, bool) {
+	if len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+
+	return "", false
+}
+
+// normalizePattern replaces the parameter names, so that "/a/{x}" and "/a/{y}" are detected as duplicates.
+func normalizePattern(segments []string) string {
+	normalized := make([]string, len(segments))
+	for i, segment := range segments {
+		if _, ok := paramName(segment); ok {
+			segment = "{}"
+		}
+		normalized[i] = segment
+	}
+
+	return "/" + strings.Join(normalized, "/")
+}
+
+func literalSegments(segments []string) int {
+	count := 0
+	for _, segment := range segments {
+		if _, ok := paramName(segment); !ok {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,145 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/wsapi"
+)
+
+func echoHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"route": name, "name": api.PathParam(r, "name")})
+	})
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	router, err := api.NewRouter([]api.Route{
+		{Key: "get-burrows", Handler: echoHandler("get-burrows")},
+		{Key: "get-burrow", Handler: echoHandler("get-burrow")},
+		{Key: "rent-burrow", Handler: echoHandler("rent-burrow")},
+		{Key: "add-burrow", Handler: echoHandler("add-burrow")},
+	}, map[string]config.Endpoint{
+		"get-burrows": {Method: "GET", Path: "/burrows"},
+		"get-burrow":  {Method: "get", Path: "/burrows/{name}"},
+		"rent-burrow": {Method: "POST", Path: "/burrows/rent"},
+		"add-burrow":  {Method: "POST", Path: "/burrows"},
+	}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		method, path string
+		code         int
+		route, name  string
+	}{
+		{http.MethodGet, "/burrows", http.StatusOK, "get-burrows", ""},
+		{http.MethodPost, "/burrows", http.StatusOK, "add-burrow", ""},
+		{http.MethodGet, "/burrows/The%20Molehole", http.StatusOK, "get-burrow", "The Molehole"},
+		{http.MethodPost, "/burrows/rent", http.StatusOK, "rent-burrow", ""},
+		// The literal route does not fall back on the burrow named "rent".
+		{http.MethodGet, "/burrows/rent", http.StatusMethodNotAllowed, "", ""},
+		{http.MethodDelete, "/burrows", http.StatusMethodNotAllowed, "", ""},
+		{http.MethodGet, "/unknown", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		assert.Equal(t, tt.code, rec.Code, tt.method+" "+tt.path)
+
+		if tt.code != http.StatusOK {
+			continue
+		}
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+		assert.Equal(t, tt.route, body["route"], tt.method+" "+tt.path)
+		assert.Equal(t, tt.name, body["name"], tt.method+" "+tt.path)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/burrows", nil))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Allow"))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/burrows/rent", nil))
+	assert.Equal(t, "POST", rec.Header().Get("Allow"))
+}
+
+func TestRouter_InvalidConfiguration(t *testing.T) {
+	handler := echoHandler("any")
+
+	tests := map[string]struct {
+		routes    []api.Route
+		endpoints map[string]config.Endpoint
+	}{
+		"missing endpoint key": {
+			routes:    []api.Route{{Key: "get-burrows", Handler: handler}},
+			endpoints: map[string]config.Endpoint{"get-burows": {Method: "GET", Path: "/burrows"}},
+		},
+		"key bound twice": {
+			routes:    []api.Route{{Key: "get-burrows", Handler: handler}, {Key: "get-burrows", Handler: handler}},
+			endpoints: map[string]config.Endpoint{"get-burrows": {Method: "GET", Path: "/burrows"}},
+		},
+		"duplicated route": {
+			routes: []api.Route{{Key: "get-burrow", Handler: handler}, {Key: "get-burrow-by-name", Handler: handler}},
+			endpoints: map[string]config.Endpoint{
+				"get-burrow":         {Method: "GET", Path: "/burrows/{id}"},
+				"get-burrow-by-name": {Method: "GET", Path: "/burrows/{name}"},
+			},
+		},
+		"empty path": {
+			routes:    []api.Route{{Key: "get-burrows", Handler: handler}},
+			endpoints: map[string]config.Endpoint{"get-burrows": {Method: "GET"}},
+		},
+		"unknown method": {
+			routes:    []api.Route{{Key: "get-burrows", Handler: handler}},
+			endpoints: map[string]config.Endpoint{"get-burrows": {Method: "GTE", Path: "/burrows"}},
+		},
+	}
+
+	for name, tt := range tests {
+		_, err := api.NewRouter(tt.routes, tt.endpoints, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+// TestRoutes_MatchEndpointKeys fails when a route is served by NewServer without being listed by
+// EndpointKeys, whose keys the configuration validation checks rest.endpoints against.
+func TestRoutes_MatchEndpointKeys(t *testing.T) {
+	cfg := loadTestConfig(t)
+	service := newTestService(t)
+
+	routes := api.Routes(service, health.NewChecker(), noopJobRunner{})
+	routes = append(routes, api.AdminRoutes(config.NewStore(cfg))...)
+	routes = append(routes, api.DocsRoutes(routes, cfg)...)
+	graphQLRoutes, err := api.GraphQLRoutes(service, cfg, api.NewRateLimits(clock.New(), config.NewStore(cfg)))
+	require.NoError(t, err)
+	routes = append(routes, graphQLRoutes...)
+	routes = append(routes, api.WebSocketRoutes(wsapi.NewHub(service, events.NewBus(), cfg.WebSocket))...)
+
+	keys := make([]string, 0, len(routes))
+	for _, route := range routes {
+		keys = append(keys, route.Key)
+	}
+	assert.ElementsMatch(t, api.EndpointKeys(), keys)
+	assert.ElementsMatch(t, api.EndpointKeys(), config.EndpointKeys())
+}
+
+func TestNewServer_FailsOnUnboundEndpoints(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Rest.Endpoints["get-burrows-v2"] = config.Endpoint{Method: "GET", Path: "/v2/burrows", Roles: []string{"renter"}}
+
+	_, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	assert.ErrorContains(t, err, "get-burrows-v2")
+}
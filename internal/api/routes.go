@@ -1,14 +1,319 @@
 package api
 
 import (
-	"github.com/marcodd23/gopernet/internal/config"
 	"net/http"
 
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/billing"
+	"github.com/marcodd23/gopernet/internal/burrowio"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/graphqlapi"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
 	"github.com/marcodd23/gopernet/internal/services"
+	"github.com/marcodd23/gopernet/internal/wsapi"
+)
+
+// Keys of the endpoints served next to the Routes table.
+const (
+	configEndpoint  = "get-config"
+	openAPIEndpoint = "openapi"
+	docsEndpoint    = "docs"
+	graphQLEndpoint = "graphql"
+	wsEndpoint      = "ws"
 )
 
-func RegisterRoutes(mux *http.ServeMux, service *services.DefaultBurrowService, config *config.ServiceConfig) {
-	mux.HandleFunc(config.Rest.Endpoints["get-burrows"].Path, GetBurrowsHandler(service))
-	mux.HandleFunc(config.Rest.Endpoints["rent-burrow"].Path, RentBurrowHandler(service))
-	mux.HandleFunc(config.Rest.Endpoints["get-report"].Path, GenerateReportHandler(service))
+func init() {
+	config.RegisterEndpoints(EndpointKeys()...)
+}
+
+// EndpointKeys returns the keys of the endpoints served by NewServer: the keys of the Routes table,
+// built without its dependencies, and of the endpoints served next to it.
+func EndpointKeys() []string {
+	routes := Routes(nil, nil, nil)
+	keys := make([]string, 0, len(routes)+5)
+	for _, route := range routes {
+		keys = append(keys, route.Key)
+	}
+
+	return append(keys, configEndpoint, openAPIEndpoint, docsEndpoint, graphQLEndpoint, wsEndpoint)
+}
+
+// Routes returns the route table, binding every handler to its key in config.Rest.Endpoints.
+func Routes(service *services.DefaultBurrowService, checker *health.Checker, jobs JobRunner) []Route {
+	return []Route{
+		{
+			Key:      "get-burrows",
+			Handler:  GetBurrowsHandler(service),
+			Summary:  "List all the burrows",
+			Response: []models.Burrow{},
+		},
+		{
+			Key:      "get-burrow",
+			Handler:  GetBurrowHandler(service),
+			Summary:  "Get a burrow by name",
+			Response: models.Burrow{},
+		},
+		{
+			Key:      "quote-burrow",
+			Handler:  QuoteBurrowHandler(service),
+			Summary:  "Quote the daily price of renting a burrow",
+			Response: models.Quote{},
+		},
+		{
+			Key:      "rent-burrow",
+			Handler:  RentBurrowHandler(service),
+			Summary:  "Rent an available burrow",
+			Request:  RentBurrowRequest{},
+			Response: RentBurrowResponse{},
+		},
+		{
+			Key:      "release-burrow",
+			Handler:  ReleaseBurrowHandler(service),
+			Summary:  "Release a rented burrow",
+			Request:  ReleaseBurrowRequest{},
+			Response: ReleaseBurrowResponse{},
+		},
+		{
+			Key:      "rent-burrows",
+			Handler:  RentBurrowsHandler(service),
+			Summary:  "Rent several available burrows, all or none of them",
+			Request:  BatchBurrowsRequest{},
+			Response: BatchBurrowsResponse{},
+		},
+		{
+			Key:      "release-burrows",
+			Handler:  ReleaseBurrowsHandler(service),
+			Summary:  "Release several rented burrows, all or none of them",
+			Request:  BatchBurrowsRequest{},
+			Response: BatchBurrowsResponse{},
+		},
+		{
+			Key:           "hold-burrow",
+			Handler:       HoldBurrowHandler(service),
+			Summary:       "Hold an available burrow during checkout",
+			Response:      models.Hold{},
+			SuccessStatus: http.StatusCreated,
+		},
+		{
+			Key:      "confirm-hold",
+			Handler:  ConfirmHoldHandler(service),
+			Summary:  "Confirm a hold, renting its burrow",
+			Response: models.Hold{},
+		},
+		{
+			Key:           "join-waitlist",
+			Handler:       JoinWaitlistHandler(service),
+			Summary:       "Queue for an occupied burrow",
+			Response:      models.WaitlistPosition{},
+			SuccessStatus: http.StatusCreated,
+		},
+		{
+			Key:      "get-waitlist-position",
+			Handler:  GetWaitlistPositionHandler(service),
+			Summary:  "Get your position in the waitlist of a burrow",
+			Response: models.WaitlistPosition{},
+		},
+		{
+			Key:      "leave-waitlist",
+			Handler:  LeaveWaitlistHandler(service),
+			Summary:  "Leave the waitlist of a burrow",
+			Response: LeaveWaitlistResponse{},
+		},
+		{
+			Key:      "list-renters",
+			Handler:  GetRentersHandler(service),
+			Summary:  "List all the renters",
+			Response: []models.Renter{},
+		},
+		{
+			Key:           "add-renter",
+			Handler:       AddRenterHandler(service),
+			Summary:       "Add a renter account",
+			Request:       CreateRenterRequest{},
+			Response:      models.Renter{},
+			SuccessStatus: http.StatusCreated,
+		},
+		{
+			Key:      "get-renter",
+			Handler:  GetRenterHandler(service),
+			Summary:  "Get a renter by ID",
+			Response: models.Renter{},
+		},
+		{
+			Key:      "update-renter",
+			Handler:  UpdateRenterHandler(service),
+			Summary:  "Update the profile, contact and limits of a renter",
+			Request:  UpdateRenterRequest{},
+			Response: models.Renter{},
+		},
+		{
+			Key:      "get-rentals",
+			Handler:  GetRentalsHandler(service),
+			Summary:  "List the current and past rentals of a renter",
+			Response: []models.Rental{},
+		},
+		{
+			Key:      "delete-renter",
+			Handler:  DeleteRenterHandler(service),
+			Summary:  "Delete a renter who rents no burrow",
+			Response: DeleteRenterResponse{},
+		},
+		{
+			Key:           "get-invoices",
+			Handler:       GetInvoicesHandler(service),
+			Summary:       "Get the monthly invoices of a renter as JSON, CSV or HTML",
+			ResponseTypes: []string{billing.FormatJSON.ContentType(), billing.FormatCSV.ContentType(), billing.FormatHTML.ContentType()},
+		},
+		{
+			Key:           "add-ledger-entry",
+			Handler:       AddLedgerEntryHandler(service),
+			Summary:       "Add a refund or an adjustment to the ledger of a renter",
+			Request:       AddLedgerEntryRequest{},
+			Response:      models.LedgerEntry{},
+			SuccessStatus: http.StatusCreated,
+		},
+		{
+			Key:           "add-burrow",
+			Handler:       AddBurrowHandler(service),
+			Summary:       "Add a new burrow",
+			Request:       AddBurrowRequest{},
+			Response:      models.Burrow{},
+			SuccessStatus: http.StatusCreated,
+		},
+		{
+			Key:      "update-burrow",
+			Handler:  UpdateBurrowHandler(service),
+			Summary:  "Update the depth, width and age of a burrow",
+			Request:  UpdateBurrowRequest{},
+			Response: models.Burrow{},
+		},
+		{
+			Key:          "import-burrows",
+			Handler:      ImportBurrowsHandler(service),
+			Summary:      "Import burrows from CSV or NDJSON, reporting the failed rows",
+			RequestTypes: []string{burrowio.FormatCSV.ContentType(), burrowio.FormatNDJSON.ContentType()},
+			Response:     ImportBurrowsResponse{},
+		},
+		{
+			Key:           "export-burrows",
+			Handler:       ExportBurrowsHandler(service),
+			Summary:       "Export every burrow as CSV or NDJSON",
+			ResponseTypes: []string{burrowio.FormatCSV.ContentType(), burrowio.FormatNDJSON.ContentType()},
+		},
+		{
+			Key:      "get-report",
+			Handler:  GenerateReportHandler(service),
+			Summary:  "Generate the burrows report",
+			Response: "",
+		},
+		{
+			Key:      "run-job",
+			Handler:  RunJobHandler(jobs, RunJobWait),
+			Summary:  "Run a background job immediately, answering 202 when it outlasts the wait",
+			Request:  RunJobRequest{},
+			Response: RunJobResponse{},
+		},
+		{
+			Key:      "get-job",
+			Handler:  JobStatusHandler(jobs),
+			Summary:  "Get the status of the last run of a background job",
+			Response: async.JobStatus{},
+		},
+		{
+			Key:           "get-backup",
+			Handler:       BackupHandler(service),
+			Summary:       "Download a backup of the whole state",
+			ResponseTypes: []string{"application/json"},
+		},
+		{
+			Key:          "restore-backup",
+			Handler:      RestoreBackupHandler(service),
+			Summary:      "Replace the whole state by a backup",
+			RequestTypes: []string{"application/json"},
+			Response:     models.RestoreSummary{},
+		},
+		{
+			Key:      "readiness",
+			Handler:  ReadinessHandler(checker),
+			Summary:  "Report whether the service is ready",
+			Response: health.Report{},
+		},
+	}
+}
+
+// DocsRoutes returns the routes serving the OpenAPI document of the given routes and its documentation page.
+func DocsRoutes(routes []Route, cfg *config.ServiceConfig) []Route {
+	return []Route{
+		{Key: openAPIEndpoint, Handler: OpenAPIHandler(NewOpenAPI(routes, cfg))},
+		{Key: docsEndpoint, Handler: DocsHandler(cfg.Rest.Endpoints[openAPIEndpoint].Path)},
+	}
+}
+
+// AdminRoutes returns the route serving the active configuration of store, its secrets redacted.
+func AdminRoutes(store *config.Store) []Route {
+	return []Route{
+		{
+			Key:      configEndpoint,
+			Handler:  ConfigHandler(store),
+			Summary:  "Get the active configuration, secrets redacted",
+			Response: map[string]interface{}{},
+		},
+	}
+}
+
+// GraphQLRoutes returns the route serving the GraphQL API, resolving through the service. The
+// mutations are rate limited by the limiters of their endpoints in limits.
+func GraphQLRoutes(service *services.DefaultBurrowService, cfg *config.ServiceConfig, limits *RateLimits) ([]Route, error) {
+	executor, err := graphqlapi.NewExecutor(service, cfg.GraphQL)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Route{{Key: graphQLEndpoint, Handler: GraphQLHandler(executor, limits)}}, nil
+}
+
+// WebSocketRoutes returns the route serving the live feed of the hub.
+func WebSocketRoutes(hub *wsapi.Hub) []Route {
+	return []Route{{Key: wsEndpoint, Handler: hub}}
+}
+
+// newRoutesRouter builds the router of the route table. Request bodies are capped to the endpoint
+// maxBodyBytes, or to rest.maxBodyBytes, handlers are bounded by the endpoint timeout and clients
+// are rate limited by the endpoint limiter of limits. Client IPs are rate limited by rest.rateLimit
+// across every endpoint, before the authentication.
+// Every non public endpoint is authorized against the endpoint roles. When authenticator is not
+// nil, it requires authentication; otherwise its callers are the anonymous principal, a renter.
+func newRoutesRouter(routes []Route, authenticator *auth.Authenticator, limits *RateLimits, cfg *config.ServiceConfig) (*Router, error) {
+	return NewRouter(routes, cfg.Rest.Endpoints, func(key string, endpoint config.Endpoint, handler http.Handler) http.Handler {
+		maxBodyBytes := endpoint.MaxBodyBytes
+		if maxBodyBytes <= 0 {
+			maxBodyBytes = cfg.Rest.MaxBodyBytes
+		}
+		if maxBodyBytes <= 0 {
+			maxBodyBytes = DefaultMaxBodyBytes
+		}
+
+		authenticated := authenticator != nil && !endpoint.Public
+		if !endpoint.Public {
+			handler = AuthorizationMiddleware(endpoint.Roles, handler)
+		}
+
+		// Rate limiting runs after the authentication, to limit the authenticated callers by identity.
+		handler = limits.endpoint(key).wrap(handler)
+
+		if authenticated {
+			handler = AuthMiddleware(authenticator, handler)
+		} else if !endpoint.Public {
+			handler = AnonymousMiddleware(handler)
+		}
+		handler = limits.ip.wrap(handler)
+
+		if endpoint.Timeout > 0 {
+			handler = TimeoutMiddleware(endpoint.Timeout, handler)
+		}
+
+		return limitBody(maxBodyBytes, handler)
+	})
 }
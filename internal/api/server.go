@@ -1,19 +1,98 @@
 package api
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/marcodd23/gopernet/internal/config"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
 	"github.com/marcodd23/gopernet/internal/services"
+	"github.com/marcodd23/gopernet/internal/tlsconfig"
+	"github.com/marcodd23/gopernet/internal/wsapi"
+)
+
+// Defaults of the server timeouts, protecting against clients holding connections open.
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
 )
 
-func NewServer(service *services.DefaultBurrowService, config *config.ServiceConfig) *http.Server {
-	mux := http.NewServeMux()
-	RegisterRoutes(mux, service, config)
+// NewServer creates the HTTP server: requests get a request ID, are access logged, recovered
+// from panics and dispatched by the router built from the route table. Rate limits are measured with clk.
+// The WebSocket feed reads the events from bus, and its connections are closed by Shutdown.
+// The server is built from the active configuration of store, whose reloads update the rate limits.
+// When server.tls is enabled, the server has a TLSConfig and must be started with ListenAndServeTLS("", "").
+func NewServer(service *services.DefaultBurrowService, checker *health.Checker, jobs JobRunner, bus *events.Bus, clk clock.Clock, store *config.Store) (*http.Server, error) {
+	config := store.Current()
+
+	var authenticator *auth.Authenticator
+	if config.Auth.Enabled {
+		var err error
+		authenticator, err = auth.NewAuthenticator(config.Auth)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to set up authentication")
+		}
+	}
+
+	routes := Routes(service, checker, jobs)
+	routes = append(routes, AdminRoutes(store)...)
+	routes = append(routes, DocsRoutes(routes, config)...)
+
+	limits := NewRateLimits(clk, store)
+	graphQLRoutes, err := GraphQLRoutes(service, config, limits)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid graphql schema")
+	}
+	routes = append(routes, graphQLRoutes...)
+
+	hub := wsapi.NewHub(service, bus, config.WebSocket)
+	routes = append(routes, WebSocketRoutes(hub)...)
+
+	if unbound := UnboundEndpoints(routes, config.Rest.Endpoints); len(unbound) > 0 {
+		return nil, errors.Errorf("invalid routes configuration: no route is bound to the endpoints %s", strings.Join(unbound, ", "))
+	}
 
-	return &http.Server{
-		Addr:    fmt.Sprintf(":%s", config.Server.Port),
-		Handler: mux,
+	router, err := newRoutesRouter(routes, authenticator, limits, config)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid routes configuration")
 	}
+
+	handler := Chain(router, RequestIDMiddleware, AccessLogMiddleware, RecoveryMiddleware)
+
+	var tlsConfig *tls.Config
+	if config.HTTP.TLS.Enabled {
+		tlsConfig, err = tlsconfig.New(config.HTTP.TLS, clk)
+		if err != nil {
+			return nil, errors.WithMessage(err, "invalid tls configuration")
+		}
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%s", config.Server.Port),
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: durationOrDefault(config.HTTP.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		ReadTimeout:       config.HTTP.ReadTimeout,
+		WriteTimeout:      config.HTTP.WriteTimeout,
+		IdleTimeout:       durationOrDefault(config.HTTP.IdleTimeout, DefaultIdleTimeout),
+	}
+	server.RegisterOnShutdown(hub.Close)
+
+	return server, nil
+}
+
+func durationOrDefault(value, defaultValue time.Duration) time.Duration {
+	if value <= 0 {
+		return defaultValue
+	}
+
+	return value
 }
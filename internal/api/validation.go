@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxBodyBytes limits the request bodies when neither the endpoint nor rest.maxBodyBytes set a limit.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Codes of the request validation problems.
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeRequestTooLarge  = "request_too_large"
+)
+
+// FieldError describes why a field of a request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validatable is implemented by every request payload. Validate returns an error for each invalid field.
+type Validatable interface {
+	Validate() []FieldError
+}
+
+// decodeJSON decodes the request body into dst, rejecting trailing data, and validates it. Every
+// unknown field and every field of the wrong type is reported along with the errors of Validate.
+// On failure it writes the problem response and returns false.
+// Every mutating endpoint must decode its payload through decodeJSON.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst Validatable) bool {
+	var body json.RawMessage
+	decoder := json.NewDecoder(r.Body)
+
+	err := decoder.Decode(&body)
+	if err == nil {
+		if _, trailingErr := decoder.Token(); trailingErr != io.EOF {
+			err = errors.New("request body must contain a single JSON object")
+		}
+	}
+
+	var fieldErrors []FieldError
+	if err == nil {
+		fieldErrors, err = decodeObject(body, reflect.ValueOf(dst).Elem(), "")
+	}
+	if err != nil {
+		writeDecodeProblem(w, r, err)
+		return false
+	}
+
+	invalid := make(map[string]bool, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		invalid[fieldError.Field] = true
+	}
+	for _, fieldError := range dst.Validate() {
+		// A field that could not be decoded is only reported once.
+		if !invalid[fieldError.Field] {
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		writeValidationProblem(w, r, fieldErrors)
+		return false
+	}
+
+	return true
+}
+
+// decodeObject decodes the JSON object data into the struct dst, one member at a time, returning
+// an error for every unknown member and every member of the wrong type. Field names are prefixed
+// by prefix. It fails when data is not an object.
+func decodeObject(data []byte, dst reflect.Value, prefix string) ([]FieldError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errors.New("request body must be a JSON object")
+	}
+
+	fields := jsonFields(dst.Type())
+
+	var fieldErrors []FieldError
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		index, ok := lookupField(fields, name)
+		if !ok {
+			fieldErrors = append(fieldErrors, FieldError{Field: prefix + name, Message: "unknown field"})
+			continue
+		}
+
+		fieldErrors = append(fieldErrors, decodeField(value, dst.FieldByIndex(index), prefix+name)...)
+	}
+
+	return fieldErrors, nil
+}
+
+// decodeField decodes value into the field, descending into the nested objects.
+func decodeField(value json.RawMessage, field reflect.Value, name string) []FieldError {
+	if isNestedObject(field.Type()) && !bytes.Equal(value, []byte("null")) {
+		fieldErrors, err := decodeObject(value, field, name+".")
+		if err != nil {
+			return []FieldError{{Field: name, Message: "must be of type object"}}
+		}
+
+		return fieldErrors
+	}
+
+	if err := json.Unmarshal(value, field.Addr().Interface()); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return []FieldError{{Field: name, Message: fmt.Sprintf("must be of type %s", jsonTypeName(typeErr.Type.Kind().String()))}}
+		}
+
+		return []FieldError{{Field: name, Message: err.Error()}}
+	}
+
+	return nil
+}
+
+// jsonFields returns the index of the fields of the struct type t by JSON name, the fields of the
+// embedded structs included.
+func jsonFields(t reflect.Type) map[string][]int {
+	fields := make(map[string][]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for embeddedName, index := range jsonFields(field.Type) {
+				if _, exists := fields[embeddedName]; !exists {
+					fields[embeddedName] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = []int{i}
+	}
+
+	return fields
+}
+
+// lookupField finds the field of a JSON member the way encoding/json does: an exact match first,
+// then a case-insensitive one.
+func lookupField(fields map[string][]int, name string) ([]int, bool) {
+	if index, ok := fields[name]; ok {
+		return index, true
+	}
+
+	for fieldName, index := range fields {
+		if strings.EqualFold(fieldName, name) {
+			return index, true
+		}
+	}
+
+	return nil, false
+}
+
+// isNestedObject reports whether values of type t are decoded member by member: structs decoding
+// themselves, such as time.Time, are not.
+func isNestedObject(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem())
+}
+
+func writeDecodeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		maxBytesErr *http.MaxBytesError
+		typeErr     *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, CodeRequestTooLarge,
+			fmt.Sprintf("Request body must not be larger than %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &typeErr):
+		writeValidationProblem(w, r, []FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("must be of type %s", jsonTypeName(typeErr.Type.Kind().String())),
+		}})
+	case errors.Is(err, io.EOF):
+		writeProblem(w, r, http.StatusBadRequest, CodeMalformedRequest, "Request body must not be empty")
+	default:
+		writeProblem(w, r, http.StatusBadRequest, CodeMalformedRequest, err.Error())
+	}
+}
+
+func writeValidationProblem(w http.ResponseWriter, r *http.Request, fieldErrors []FieldError) {
+	writeProblemWithErrors(w, r, http.StatusBadRequest, CodeValidationFailed, "The request has invalid fields", fieldErrors)
+}
+
+func jsonTypeName(kind string) string {
+	switch {
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"):
+		return "integer"
+	case strings.HasPrefix(kind, "float"):
+		return "number"
+	case kind == "bool":
+		return "boolean"
+	case kind == "slice", kind == "array":
+		return "array"
+	case kind == "struct", kind == "map":
+		return "object"
+	default:
+		return kind
+	}
+}
+
+// limitBody caps the size of the request body.
+func limitBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fieldValidator collects the field errors of a request.
+type fieldValidator struct {
+	errors []FieldError
+}
+
+func (v *fieldValidator) check(ok bool, field, message string) {
+	if !ok {
+		v.errors = append(v.errors, FieldError{Field: field, Message: message})
+	}
+}
+
+func (v *fieldValidator) requiredString(value, field string, maxLength int) {
+	switch {
+	case strings.TrimSpace(value) == "":
+		v.check(false, field, "is required")
+	case len(value) > maxLength:
+		v.check(false, field, fmt.Sprintf("must be at most %d characters long", maxLength))
+	}
+}
@@ -0,0 +1,108 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+)
+
+func TestValidation(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Rest.MaxBodyBytes = 128
+
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+		fields []api.FieldError
+	}{
+		{
+			name: "every invalid field is listed", path: "/burrows",
+			body:   `{"name":" ","depth":-1,"width":0,"age":-5}`,
+			status: http.StatusBadRequest, code: api.CodeValidationFailed,
+			fields: []api.FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "depth", Message: "must be between 0 and 1000 meters"},
+				{Field: "width", Message: "must be greater than 0 and at most 100 meters"},
+				{Field: "age", Message: "must not be negative"},
+			},
+		},
+		{
+			name: "empty name", path: "/burrows/rent",
+			body:   `{"name":""}`,
+			status: http.StatusBadRequest, code: api.CodeValidationFailed,
+			fields: []api.FieldError{{Field: "name", Message: "is required"}},
+		},
+		{
+			name: "unknown field", path: "/burrows/rent",
+			body:   `{"name":"The Deep Den","nmae":"typo"}`,
+			status: http.StatusBadRequest, code: api.CodeValidationFailed,
+			fields: []api.FieldError{{Field: "nmae", Message: "unknown field"}},
+		},
+		{
+			name: "wrong type", path: "/burrows",
+			body:   `{"name":"Den","depth":"deep","width":1}`,
+			status: http.StatusBadRequest, code: api.CodeValidationFailed,
+			fields: []api.FieldError{{Field: "depth", Message: "must be of type number"}},
+		},
+		{
+			name: "every decoding error is listed", path: "/burrows",
+			body:   `{"name":1,"depth":"deep","nmae":"x","width":-1}`,
+			status: http.StatusBadRequest, code: api.CodeValidationFailed,
+			fields: []api.FieldError{
+				{Field: "name", Message: "must be of type string"},
+				{Field: "depth", Message: "must be of type number"},
+				{Field: "nmae", Message: "unknown field"},
+				{Field: "width", Message: "must be greater than 0 and at most 100 meters"},
+			},
+		},
+		{
+			name: "not an object", path: "/burrows/rent",
+			body:   `["The Deep Den"]`,
+			status: http.StatusBadRequest, code: api.CodeMalformedRequest,
+		},
+		{
+			name: "trailing data", path: "/burrows/rent",
+			body:   `{"name":"The Deep Den"}{"name":"The Molehole"}`,
+			status: http.StatusBadRequest, code: api.CodeMalformedRequest,
+		},
+		{
+			name: "empty body", path: "/burrows/rent",
+			status: http.StatusBadRequest, code: api.CodeMalformedRequest,
+		},
+		{
+			name: "body too large", path: "/burrows/rent",
+			body:   `{"name":"` + strings.Repeat("x", 200) + `"}`,
+			status: http.StatusRequestEntityTooLarge, code: api.CodeRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set(api.APIKeyHeader, "local-dev-key")
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, req)
+		assert.Equal(t, tt.status, rec.Code, tt.name)
+
+		var problem api.Problem
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&problem), tt.name)
+		assert.Equal(t, tt.code, problem.Code, tt.name)
+		assert.Equal(t, tt.fields, problem.Errors, tt.name)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// LeaveWaitlistResponse is the data returned by the leave waitlist endpoint.
+type LeaveWaitlistResponse struct {
+	Burrow string `json:"burrow"`
+}
+
+// JoinWaitlistHandler queues the authenticated caller for the burrow named by the "name" path
+// parameter, which must be occupied or held.
+func JoinWaitlistHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		position, err := service.JoinWaitlist(PathParam(r, "name"), caller.Subject)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Joined the waitlist successfully",
+			Data:    position,
+		})
+	}
+}
+
+// GetWaitlistPositionHandler returns the position of the authenticated caller in the waitlist of
+// the burrow named by the "name" path parameter.
+func GetWaitlistPositionHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		position, err := service.GetWaitlistPosition(PathParam(r, "name"), caller.Subject)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status: "success",
+			Data:   position,
+		})
+	}
+}
+
+// LeaveWaitlistHandler removes the authenticated caller from the waitlist of the burrow named by
+// the "name" path parameter.
+func LeaveWaitlistHandler(service *services.DefaultBurrowService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		caller, ok := callerPrincipal(w, r)
+		if !ok {
+			return
+		}
+
+		name := PathParam(r, "name")
+		if err := service.LeaveWaitlist(name, caller.Subject); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(JSONResponse{
+			Status:  "success",
+			Message: "Left the waitlist successfully",
+			Data:    LeaveWaitlistResponse{Burrow: name},
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+func TestWaitlist(t *testing.T) {
+	service := newTestService(t)
+	bus := events.NewBus()
+	service.SetEventBus(bus)
+	received, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+
+	router, err := api.NewRouter(api.Routes(service, health.NewChecker(), noopJobRunner{}), loadTestConfig(t).Rest.Endpoints, nil)
+	require.NoError(t, err)
+
+	call := func(method, subject string) (int, models.WaitlistPosition) {
+		req := httptest.NewRequest(method, "/burrows/The%20Molehole/waitlist", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: subject, Roles: []string{auth.RoleRenter}}))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var response struct {
+			Data models.WaitlistPosition `json:"data"`
+		}
+		json.NewDecoder(rec.Body).Decode(&response)
+
+		return rec.Code, response.Data
+	}
+
+	// The Molehole is rented by alice.
+	code, position := call(http.MethodPost, "bob")
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 1, position.Position)
+	code, _ = call(http.MethodPost, "bob")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = call(http.MethodPost, "carol")
+	require.Equal(t, http.StatusCreated, code)
+	code, position = call(http.MethodGet, "carol")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.WaitlistPosition{Burrow: "The Molehole", Position: 2, Length: 2}, position)
+
+	// Releasing the burrow holds it for bob, and notifies bob.
+	require.NoError(t, service.ReleaseBurrow("The Molehole", ""))
+	var promoted *models.Hold
+	for promoted == nil {
+		event := <-received
+		if event.Type == events.WaitlistPromoted {
+			promoted = event.Data.(*models.Hold)
+		}
+	}
+	assert.Equal(t, "bob", promoted.Renter)
+	assert.ErrorIs(t, service.RentBurrow("The Molehole", "carol"), models.ErrBurrowHeld)
+
+	_, position = call(http.MethodGet, "carol")
+	assert.Equal(t, 1, position.Position)
+	code, _ = call(http.MethodDelete, "carol")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call(http.MethodGet, "carol")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Available burrows have no waitlist.
+	req := httptest.NewRequest(http.MethodPost, "/burrows/The%20Deep%20Den/waitlist", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), models.ErrBurrowAvailable.Code)
+}
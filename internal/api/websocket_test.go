@@ -0,0 +1,45 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/wsapi"
+)
+
+func TestWebSocketEndpoint(t *testing.T) {
+	cfg := loadTestConfig(t)
+	cfg.Auth.Enabled = false // anonymous callers, limited by IP
+	server, err := api.NewServer(newTestService(t), health.NewChecker(), noopJobRunner{}, events.NewBus(), clock.New(), config.NewStore(cfg))
+	require.NoError(t, err)
+
+	// The connection goes through the middlewares of the server.
+	httpServer := httptest.NewServer(server.Handler)
+	defer httpServer.Close()
+
+	conn, response, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.NotEmpty(t, response.Header.Get(api.RequestIDHeader))
+
+	require.NoError(t, conn.WriteJSON(wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Burrows: []string{"The Deep Den"}}))
+	var reply wsapi.ServerMessage
+	require.NoError(t, conn.ReadJSON(&reply))
+	assert.Equal(t, wsapi.TypeSubscribed, reply.Type)
+
+	// Shutting down the server closes the connections.
+	require.NoError(t, server.Shutdown(context.Background()))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}
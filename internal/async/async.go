@@ -2,80 +2,162 @@ package async
 
 import (
 	"context"
-	"github.com/marcodd23/go-micro-core/pkg/logmgr"
-	"log"
+	"fmt"
 	"sync"
-	"time"
 
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/models"
 	"github.com/marcodd23/gopernet/internal/services"
 )
 
+// Names of the background jobs, used as keys of the jobs configuration.
+const (
+	BurrowUpdaterJob   = "burrow-updater"
+	PeriodicSaverJob   = "periodic-saver"
+	ReportGeneratorJob = "report-generator"
+	HoldExpirerJob     = "hold-expirer"
+	UsageAccruerJob    = "usage-accruer"
+)
+
+var (
+	// ErrUnknownJob is returned when running a job that does not exist.
+	ErrUnknownJob = &models.Error{Code: "unknown_job", Message: "unknown job"}
+	// ErrJobRunning is returned when running a SkipIfRunning job whose previous run has not finished.
+	ErrJobRunning = &models.Error{Code: "job_running", Message: "job is already running"}
+)
+
 type BackgroundTaskManager struct {
-	service services.GopherService
+	service   services.GopherService
+	saver     *StateSaver
+	scheduler *Scheduler
+
+	mu        sync.Mutex
+	scheduled map[string]*ScheduledJob
+}
+
+// NewBackgroundTaskManager creates a BackgroundTaskManager scheduling its jobs with clk.
+func NewBackgroundTaskManager(service services.GopherService, saver *StateSaver, clk clock.Clock) *BackgroundTaskManager {
+	b := &BackgroundTaskManager{
+		service:   service,
+		saver:     saver,
+		scheduler: NewScheduler(clk),
+		scheduled: make(map[string]*ScheduledJob),
+	}
+
+	return b
+}
+
+func (b *BackgroundTaskManager) StartBurrowUpdater(cancellableCtx context.Context, wg *sync.WaitGroup, job Job) {
+	b.start(cancellableCtx, wg, job, b.updateBurrows)
 }
 
-func NewBackgroundTaskManager(service services.GopherService) *BackgroundTaskManager {
-	return &BackgroundTaskManager{
-		service: service,
+func (b *BackgroundTaskManager) StartPeriodicSaver(cancellableCtx context.Context, wg *sync.WaitGroup, job Job) {
+	b.start(cancellableCtx, wg, job, b.saveState)
+}
+
+func (b *BackgroundTaskManager) StartReportGenerator(cancellableCtx context.Context, wg *sync.WaitGroup, job Job) {
+	b.start(cancellableCtx, wg, job, b.saveReport)
+}
+
+func (b *BackgroundTaskManager) StartHoldExpirer(cancellableCtx context.Context, wg *sync.WaitGroup, job Job) {
+	b.start(cancellableCtx, wg, job, b.releaseExpiredHolds)
+}
+
+func (b *BackgroundTaskManager) StartUsageAccruer(cancellableCtx context.Context, wg *sync.WaitGroup, job Job) {
+	b.start(cancellableCtx, wg, job, b.accrueUsage)
+}
+
+func (b *BackgroundTaskManager) start(cancellableCtx context.Context, wg *sync.WaitGroup, job Job, task Task) {
+	scheduled := b.scheduler.Schedule(cancellableCtx, wg, job, task)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.scheduled[job.Name] = scheduled
+}
+
+// UpdateJob replaces the schedule of a started job by the schedule of job, with the same name.
+func (b *BackgroundTaskManager) UpdateJob(job Job) error {
+	b.mu.Lock()
+	scheduled, ok := b.scheduled[job.Name]
+	b.mu.Unlock()
+	if !ok {
+		return errors.WithMessage(ErrUnknownJob, job.Name)
 	}
+
+	scheduled.Update(job)
+
+	return nil
 }
 
-func (b *BackgroundTaskManager) StartBurrowUpdater(cancellableCtx context.Context, wg *sync.WaitGroup, interval time.Duration) {
-	wg.Add(1)
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ticker.C:
-				logmgr.GetLogger().LogDebug(cancellableCtx, "updating burrows ....")
-				b.service.UpdateBurrows()
-			case <-cancellableCtx.Done():
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
-
-func (b *BackgroundTaskManager) StartPeriodicSaver(cancellableCtx context.Context, wg *sync.WaitGroup, interval time.Duration) {
-	wg.Add(1)
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ticker.C:
-				logmgr.GetLogger().LogDebug(cancellableCtx, "saving state ....")
-				err := b.service.SaveState()
-				if err != nil {
-					log.Printf("Error saving state: %v", err)
-				}
-			case <-cancellableCtx.Done():
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
-
-func (b *BackgroundTaskManager) StartReportGenerator(cancellableCtx context.Context, wg *sync.WaitGroup, interval time.Duration) {
-	wg.Add(1)
-	ticker := time.NewTicker(interval)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case <-ticker.C:
-				logmgr.GetLogger().LogDebug(cancellableCtx, "saving report ....")
-				err := b.service.SaveReport()
-				if err != nil {
-					log.Printf("Error generating report: %v", err)
-				}
-			case <-cancellableCtx.Done():
-				ticker.Stop()
-				return
-			}
-		}
-	}()
+// RunJob starts a run of the named job now, outside its schedule, with the guard of its scheduled
+// runs. The returned channel gets the error of the run once it is done.
+func (b *BackgroundTaskManager) RunJob(name string) (<-chan error, error) {
+	scheduled, err := b.job(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduled.Run()
+}
+
+// JobStatus returns the status of the last run of the named job.
+func (b *BackgroundTaskManager) JobStatus(name string) (JobStatus, error) {
+	scheduled, err := b.job(name)
+	if err != nil {
+		return JobStatus{}, err
+	}
+
+	return scheduled.Status(), nil
+}
+
+func (b *BackgroundTaskManager) job(name string) (*ScheduledJob, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	scheduled, ok := b.scheduled[name]
+	if !ok {
+		return nil, errors.WithMessage(ErrUnknownJob, name)
+	}
+
+	return scheduled, nil
+}
+
+func (b *BackgroundTaskManager) updateBurrows(ctx context.Context) error {
+	logmgr.GetLogger().LogDebug(ctx, "updating burrows ....")
+	b.service.UpdateBurrows()
+
+	return nil
+}
+
+func (b *BackgroundTaskManager) saveState(ctx context.Context) error {
+	logmgr.GetLogger().LogDebug(ctx, "saving state ....")
+
+	return errors.WithMessage(b.saver.Save(ctx), "saving state")
+}
+
+func (b *BackgroundTaskManager) saveReport(ctx context.Context) error {
+	logmgr.GetLogger().LogDebug(ctx, "saving report ....")
+
+	return errors.WithMessage(b.service.SaveReport(), "generating report")
+}
+
+func (b *BackgroundTaskManager) releaseExpiredHolds(ctx context.Context) error {
+	logmgr.GetLogger().LogDebug(ctx, "releasing expired holds ....")
+	if expired := b.service.ReleaseExpiredHolds(); len(expired) > 0 {
+		logmgr.GetLogger().LogInfo(ctx, fmt.Sprintf("Released %d expired hold(s)", len(expired)))
+	}
+
+	return nil
+}
+
+func (b *BackgroundTaskManager) accrueUsage(ctx context.Context) error {
+	logmgr.GetLogger().LogDebug(ctx, "accruing the usage of the rentals ....")
+	if charges := b.service.AccrueUsage(); len(charges) > 0 {
+		logmgr.GetLogger().LogInfo(ctx, fmt.Sprintf("Charged %d rental(s)", len(charges)))
+	}
+
+	return nil
 }
@@ -20,8 +20,134 @@ func (m *MockGopherService) GetAllBurrows() []*models.Burrow {
 	return args.Get(0).([]*models.Burrow)
 }
 
-func (m *MockGopherService) RentBurrow(name string) error {
+func (m *MockGopherService) GetBurrow(name string) (*models.Burrow, error) {
 	args := m.Called(name)
+	burrow, _ := args.Get(0).(*models.Burrow)
+	return burrow, args.Error(1)
+}
+
+func (m *MockGopherService) RentBurrow(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockGopherService) ReleaseBurrow(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockGopherService) RentBurrows(names []string, renter string) []error {
+	args := m.Called(names, renter)
+	return args.Get(0).([]error)
+}
+
+func (m *MockGopherService) ReleaseBurrows(names []string, renter string) []error {
+	args := m.Called(names, renter)
+	return args.Get(0).([]error)
+}
+
+func (m *MockGopherService) HoldBurrow(name, renter string) (*models.Hold, error) {
+	args := m.Called(name, renter)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockGopherService) ConfirmHold(id string) (*models.Hold, error) {
+	args := m.Called(id)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockGopherService) ReleaseExpiredHolds() []*models.Hold {
+	args := m.Called()
+	holds, _ := args.Get(0).([]*models.Hold)
+	return holds
+}
+
+func (m *MockGopherService) JoinWaitlist(name, renter string) (*models.WaitlistPosition, error) {
+	args := m.Called(name, renter)
+	position, _ := args.Get(0).(*models.WaitlistPosition)
+	return position, args.Error(1)
+}
+
+func (m *MockGopherService) GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error) {
+	args := m.Called(name, renter)
+	position, _ := args.Get(0).(*models.WaitlistPosition)
+	return position, args.Error(1)
+}
+
+func (m *MockGopherService) LeaveWaitlist(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockGopherService) QuoteBurrow(name string) (*models.Quote, error) {
+	args := m.Called(name)
+	quote, _ := args.Get(0).(*models.Quote)
+	return quote, args.Error(1)
+}
+
+func (m *MockGopherService) AccrueUsage() []*models.LedgerEntry {
+	args := m.Called()
+	entries, _ := args.Get(0).([]*models.LedgerEntry)
+	return entries
+}
+
+func (m *MockGopherService) AddLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error) {
+	args := m.Called(entry)
+	appended, _ := args.Get(0).(*models.LedgerEntry)
+	return appended, args.Error(1)
+}
+
+func (m *MockGopherService) LedgerEntries(renter string) []*models.LedgerEntry {
+	args := m.Called(renter)
+	entries, _ := args.Get(0).([]*models.LedgerEntry)
+	return entries
+}
+
+func (m *MockGopherService) AddRenter(renter *models.Renter) (*models.Renter, error) {
+	args := m.Called(renter)
+	added, _ := args.Get(0).(*models.Renter)
+	return added, args.Error(1)
+}
+
+func (m *MockGopherService) GetRenter(id string) (*models.Renter, error) {
+	args := m.Called(id)
+	renter, _ := args.Get(0).(*models.Renter)
+	return renter, args.Error(1)
+}
+
+func (m *MockGopherService) GetAllRenters() []*models.Renter {
+	args := m.Called()
+	renters, _ := args.Get(0).([]*models.Renter)
+	return renters
+}
+
+func (m *MockGopherService) UpdateRenter(renter *models.Renter) (*models.Renter, error) {
+	args := m.Called(renter)
+	updated, _ := args.Get(0).(*models.Renter)
+	return updated, args.Error(1)
+}
+
+func (m *MockGopherService) DeleteRenter(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockGopherService) GetRentals(renter string) ([]*models.Rental, error) {
+	args := m.Called(renter)
+	rentals, _ := args.Get(0).([]*models.Rental)
+	return rentals, args.Error(1)
+}
+
+func (m *MockGopherService) GetBurrowRentals(name string) ([]*models.Rental, error) {
+	args := m.Called(name)
+	rentals, _ := args.Get(0).([]*models.Rental)
+	return rentals, args.Error(1)
+}
+
+func (m *MockGopherService) AddBurrow(burrow *models.Burrow) error {
+	args := m.Called(burrow)
 	return args.Error(0)
 }
 
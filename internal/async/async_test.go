@@ -6,26 +6,76 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
 	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/models"
 )
 
 // MockGopherService already defined above
 
+func newIntervalJob(t *testing.T, interval time.Duration) async.Job {
+	schedule, err := async.Every(interval)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return async.Job{Name: t.Name(), Schedule: schedule}
+}
+
+func newTaskManager(service *MockGopherService) (*async.BackgroundTaskManager, *clock.Fake) {
+	clk := clock.NewFake(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC))
+	saver := async.NewStateSaver(service, config.Persistence{}, clk, events.NewBus())
+
+	return async.NewBackgroundTaskManager(service, saver, clk), clk
+}
+
+// signalOf returns a channel receiving a value when the mocked call runs.
+func signalOf(call *mock.Call) <-chan struct{} {
+	ran := make(chan struct{}, 1)
+	call.Run(func(mock.Arguments) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	})
+
+	return ran
+}
+
+// tick advances clk by d once the scheduler has armed its timer, and waits for ran.
+func tick(t *testing.T, clk *clock.Fake, d time.Duration, ran <-chan struct{}) {
+	waitForTimer(t, clk)
+	clk.Advance(d)
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("the job did not run")
+	}
+}
+
 func TestBackgroundTaskManager_StartBurrowUpdater(t *testing.T) {
 	mockService := new(MockGopherService)
-	mockService.On("UpdateBurrows").Return()
+	ran := signalOf(mockService.On("UpdateBurrows").Return())
 
-	taskManager := async.NewBackgroundTaskManager(mockService)
+	taskManager, clk := newTaskManager(mockService)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
-	interval := 10 * time.Millisecond
+	job := newIntervalJob(t, 10*time.Millisecond)
 
 	// Start the BurrowUpdater
-	taskManager.StartBurrowUpdater(ctx, &wg, interval)
+	taskManager.StartBurrowUpdater(ctx, &wg, job)
 
-	// Wait for the task to run at least once
-	time.Sleep(25 * time.Millisecond)
+	// Wait for the task to run once
+	tick(t, clk, 10*time.Millisecond, ran)
 
 	// Cancel the context to stop the goroutine
 	cancel()
@@ -39,19 +89,19 @@ func TestBackgroundTaskManager_StartBurrowUpdater(t *testing.T) {
 
 func TestBackgroundTaskManager_StartPeriodicSaver(t *testing.T) {
 	mockService := new(MockGopherService)
-	mockService.On("SaveState").Return(nil)
+	ran := signalOf(mockService.On("SaveState").Return(nil))
 
-	taskManager := async.NewBackgroundTaskManager(mockService)
+	taskManager, clk := newTaskManager(mockService)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
-	interval := 10 * time.Millisecond
+	job := newIntervalJob(t, 10*time.Millisecond)
 
 	// Start the PeriodicSaver
-	taskManager.StartPeriodicSaver(ctx, &wg, interval)
+	taskManager.StartPeriodicSaver(ctx, &wg, job)
 
-	// Wait for the task to run at least once
-	time.Sleep(25 * time.Millisecond)
+	// Wait for the task to run once
+	tick(t, clk, 10*time.Millisecond, ran)
 
 	// Cancel the context to stop the goroutine
 	cancel()
@@ -65,19 +115,19 @@ func TestBackgroundTaskManager_StartPeriodicSaver(t *testing.T) {
 
 func TestBackgroundTaskManager_StartReportGenerator(t *testing.T) {
 	mockService := new(MockGopherService)
-	mockService.On("SaveReport").Return(nil)
+	ran := signalOf(mockService.On("SaveReport").Return(nil))
 
-	taskManager := async.NewBackgroundTaskManager(mockService)
+	taskManager, clk := newTaskManager(mockService)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
-	interval := 10 * time.Millisecond
+	job := newIntervalJob(t, 10*time.Millisecond)
 
 	// Start the ReportGenerator
-	taskManager.StartReportGenerator(ctx, &wg, interval)
+	taskManager.StartReportGenerator(ctx, &wg, job)
 
-	// Wait for the task to run at least once
-	time.Sleep(25 * time.Millisecond)
+	// Wait for the task to run once
+	tick(t, clk, 10*time.Millisecond, ran)
 
 	// Cancel the context to stop the goroutine
 	cancel()
@@ -88,3 +138,75 @@ func TestBackgroundTaskManager_StartReportGenerator(t *testing.T) {
 	// Verify that the SaveReport method was called
 	mockService.AssertCalled(t, "SaveReport")
 }
+
+func TestBackgroundTaskManager_RunJob(t *testing.T) {
+	mockService := new(MockGopherService)
+	mockService.On("SaveReport").Return(nil).Once()
+	mockService.On("SaveReport").Return(errors.New("disk full")).Once()
+
+	taskManager, _ := newTaskManager(mockService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	job := newIntervalJob(t, time.Hour)
+	job.Name = async.ReportGeneratorJob
+	taskManager.StartReportGenerator(ctx, &wg, job)
+
+	done, err := taskManager.RunJob(async.ReportGeneratorJob)
+	require.NoError(t, err)
+	assert.NoError(t, <-done)
+
+	status, err := taskManager.JobStatus(async.ReportGeneratorJob)
+	require.NoError(t, err)
+	assert.Equal(t, async.JobSucceeded, status.State)
+
+	// The error of the run is returned and reported by the status.
+	done, err = taskManager.RunJob(async.ReportGeneratorJob)
+	require.NoError(t, err)
+	assert.ErrorContains(t, <-done, "disk full")
+
+	status, err = taskManager.JobStatus(async.ReportGeneratorJob)
+	require.NoError(t, err)
+	assert.Equal(t, async.JobFailed, status.State)
+	assert.Contains(t, status.Error, "disk full")
+
+	_, err = taskManager.RunJob("unknown")
+	assert.ErrorIs(t, err, async.ErrUnknownJob)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBackgroundTaskManager_StartHoldExpirer(t *testing.T) {
+	mockService := new(MockGopherService)
+	ran := signalOf(mockService.On("ReleaseExpiredHolds").Return([]*models.Hold{{ID: "hold-1", Burrow: "Burrow1"}}))
+
+	taskManager, clk := newTaskManager(mockService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	taskManager.StartHoldExpirer(ctx, &wg, newIntervalJob(t, 10*time.Millisecond))
+
+	tick(t, clk, 10*time.Millisecond, ran)
+	cancel()
+	wg.Wait()
+
+	mockService.AssertCalled(t, "ReleaseExpiredHolds")
+}
+
+func TestBackgroundTaskManager_StartUsageAccruer(t *testing.T) {
+	mockService := new(MockGopherService)
+	ran := signalOf(mockService.On("AccrueUsage").Return([]*models.LedgerEntry{{ID: "charge-1", Renter: "alice", Amount: 12}}))
+
+	taskManager, clk := newTaskManager(mockService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	taskManager.StartUsageAccruer(ctx, &wg, newIntervalJob(t, 10*time.Millisecond))
+
+	tick(t, clk, 10*time.Millisecond, ran)
+	cancel()
+	wg.Wait()
+
+	mockService.AssertCalled(t, "AccrueUsage")
+}
@@ -0,0 +1,225 @@
+package async
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule computes the next activation time of a job.
+type Schedule interface {
+	// Next returns the first activation time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+// ParseSchedule parses a schedule specification. Accepted formats are:
+//   - a Go duration ("30s", "5m")
+//   - "@every <duration>"
+//   - the descriptors "@yearly", "@monthly", "@weekly", "@daily" (or "@midnight") and "@hourly"
+//   - a standard 5 fields cron expression ("minute hour day-of-month month day-of-week"),
+//     evaluated in the local time zone.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("empty schedule")
+	}
+
+	if d, err := time.ParseDuration(spec); err == nil {
+		return Every(d)
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid schedule %q", spec)
+		}
+		return Every(d)
+	}
+
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	return parseCron(spec)
+}
+
+// IntervalSchedule activates a job at a fixed interval.
+type IntervalSchedule struct {
+	Interval time.Duration
+}
+
+// Every returns a schedule that fires every d.
+func Every(d time.Duration) (Schedule, error) {
+	if d <= 0 {
+		return nil, errors.Errorf("interval must be positive, got %s", d)
+	}
+
+	return IntervalSchedule{Interval: d}, nil
+}
+
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Interval)
+}
+
+// CronSchedule activates a job on the minutes matched by a cron expression.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record a day field starting with "*" ("*" or "*/n"): when both day
+	// fields are restricted a day matches if either of them matches, as in the classic cron.
+	domStar, dowStar bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day-of-month", 1, 31},
+	{"month", 1, 12},
+	{"day-of-week", 0, 6},
+}
+
+func parseCron(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, errors.Errorf("invalid schedule %q: expected a duration or 5 cron fields, got %d fields", spec, len(parts))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		b, err := parseCronField(parts[i], field)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid schedule %q", spec)
+		}
+		bits[i] = b
+	}
+
+	// Sunday can be written both as 0 and 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	schedule := &CronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}
+
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, errors.Errorf("invalid schedule %q: it never fires", spec)
+	}
+
+	return schedule, nil
+}
+
+func parseCronField(expr string, field cronField) (uint64, error) {
+	max := field.max
+	if field.name == "day-of-week" {
+		max = 7
+	}
+
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("%s: invalid step in %q", field.name, item)
+			}
+			rangeExpr, step = item[:i], s
+		}
+
+		lo, hi := field.min, max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("%s: invalid range %q", field.name, item)
+			}
+		default:
+			v, err := strconv.Atoi(rangeExpr)
+			if err != nil {
+				return 0, fmt.Errorf("%s: invalid value %q", field.name, item)
+			}
+			lo, hi = v, v
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < field.min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%s: %q out of range [%d-%d]", field.name, item, field.min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// maxCronSearch bounds the search of the next activation for expressions that never match (e.g. "0 0 31 2 *").
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
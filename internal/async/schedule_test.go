@@ -0,0 +1,57 @@
+package async_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marcodd23/gopernet/internal/async"
+)
+
+func TestParseSchedule_Durations(t *testing.T) {
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, spec := range []string{"30s", "@every 30s"} {
+		schedule, err := async.ParseSchedule(spec)
+		assert.NoError(t, err)
+		assert.Equal(t, start.Add(30*time.Second), schedule.Next(start), spec)
+	}
+}
+
+func TestParseSchedule_Cron(t *testing.T) {
+	start := time.Date(2024, 5, 10, 12, 0, 30, 0, time.UTC) // Friday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 6 * * *", time.Date(2024, 5, 11, 6, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 5, 10, 12, 15, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 5, 10, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 15 * 1", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 1,6", time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC)},
+		// A stepped "*" leaves its day field unrestricted: both must match.
+		{"0 0 */2 * 1", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * */2", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := async.ParseSchedule(tt.spec)
+		assert.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.want, schedule.Next(start), tt.spec)
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "-5s", "@every nope", "* * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "0 0 31 2 *"} {
+		_, err := async.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
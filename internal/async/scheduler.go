@@ -0,0 +1,237 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// Job describes when a background task runs.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter adds a random delay in [0, Jitter) to every activation, to avoid
+	// several instances hitting shared resources at the same instant.
+	Jitter time.Duration
+	// SkipIfRunning skips an activation when the previous run has not finished yet.
+	SkipIfRunning bool
+}
+
+// NewJob builds a Job from its configuration. The defaultSchedule is used when the
+// configuration does not define a schedule.
+func NewJob(name string, cfg config.Job, defaultSchedule string) (Job, error) {
+	spec := cfg.Schedule
+	if spec == "" {
+		spec = defaultSchedule
+	}
+
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return Job{}, errors.WithMessagef(err, "job %s", name)
+	}
+
+	if cfg.Jitter < 0 {
+		return Job{}, errors.Errorf("job %s: jitter must not be negative", name)
+	}
+
+	return Job{
+		Name:          name,
+		Schedule:      schedule,
+		Jitter:        cfg.Jitter,
+		SkipIfRunning: cfg.SkipIfRunning,
+	}, nil
+}
+
+// Scheduler runs tasks according to their Job schedule.
+type Scheduler struct {
+	clock clock.Clock
+	mu    sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewScheduler creates a Scheduler driven by the given clock.
+func NewScheduler(clk clock.Clock) *Scheduler {
+	return &Scheduler{
+		clock: clk,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Task is the work of a job. The error of a run is logged and reported by the job status.
+type Task func(ctx context.Context) error
+
+// States of a job, reported by JobStatus.
+const (
+	JobIdle      = "idle"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobStatus reports the last run of a job.
+type JobStatus struct {
+	Job        string     `json:"job"`
+	State      string     `json:"state"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// ScheduledJob is a job started by Scheduler.Schedule, whose schedule can be updated.
+type ScheduledJob struct {
+	clock   clock.Clock
+	ctx     context.Context
+	wg      *sync.WaitGroup
+	task    Task
+	updated chan struct{}
+
+	mu      sync.Mutex
+	job     Job
+	running int
+	status  JobStatus
+}
+
+// Job returns the current job.
+func (sj *ScheduledJob) Job() Job {
+	sj.mu.Lock()
+	defer sj.mu.Unlock()
+
+	return sj.job
+}
+
+// Status returns the status of the last run of the job.
+func (sj *ScheduledJob) Status() JobStatus {
+	sj.mu.Lock()
+	defer sj.mu.Unlock()
+
+	return sj.status
+}
+
+// Update replaces the job, its next activation following the new schedule.
+func (sj *ScheduledJob) Update(job Job) {
+	sj.mu.Lock()
+	sj.job = job
+	sj.mu.Unlock()
+
+	select {
+	case sj.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts a run of the task now, with the guard of the scheduled activations: while a run of a
+// SkipIfRunning job is in flight, it returns ErrJobRunning. The run receives the context of the
+// scheduler and is tracked by its wait group; the returned channel gets its error once it is done.
+func (sj *ScheduledJob) Run() (<-chan error, error) {
+	if err := sj.ctx.Err(); err != nil {
+		return nil, errors.Wrapf(err, "job %s is stopped", sj.Job().Name)
+	}
+
+	sj.mu.Lock()
+	if sj.job.SkipIfRunning && sj.running > 0 {
+		sj.mu.Unlock()
+		return nil, errors.WithMessage(ErrJobRunning, sj.job.Name)
+	}
+	startedAt := sj.clock.Now()
+	sj.running++
+	sj.status = JobStatus{Job: sj.job.Name, State: JobRunning, StartedAt: &startedAt}
+	sj.mu.Unlock()
+
+	done := make(chan error, 1)
+	sj.wg.Add(1)
+	go func() {
+		defer sj.wg.Done()
+		err := sj.task(sj.ctx)
+		sj.finish(startedAt, err)
+		done <- err
+	}()
+
+	return done, nil
+}
+
+// finish records the end of the run started at startedAt.
+func (sj *ScheduledJob) finish(startedAt time.Time, err error) {
+	sj.mu.Lock()
+	defer sj.mu.Unlock()
+
+	sj.running--
+	if err != nil {
+		logmgr.GetLogger().LogError(sj.ctx, fmt.Sprintf("job %s failed", sj.job.Name), err)
+	}
+	if !sj.status.StartedAt.Equal(startedAt) {
+		// A later run started meanwhile, the status reports it.
+		return
+	}
+
+	finishedAt := sj.clock.Now()
+	sj.status.FinishedAt = &finishedAt
+	sj.status.State = JobSucceeded
+	if err != nil {
+		sj.status.State = JobFailed
+		sj.status.Error = err.Error()
+	}
+}
+
+// Schedule starts a goroutine running task at every activation of job until cancellableCtx is done.
+// Every run is tracked by wg, so waiting on wg after cancellation also waits for in-flight runs.
+func (s *Scheduler) Schedule(cancellableCtx context.Context, wg *sync.WaitGroup, job Job, task Task) *ScheduledJob {
+	scheduled := &ScheduledJob{
+		clock:   s.clock,
+		ctx:     cancellableCtx,
+		wg:      wg,
+		task:    task,
+		updated: make(chan struct{}, 1),
+		job:     job,
+		status:  JobStatus{Job: job.Name, State: JobIdle},
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			job := scheduled.Job()
+			next := s.next(job)
+			if next.IsZero() {
+				logmgr.GetLogger().LogWarning(cancellableCtx, fmt.Sprintf("job %s has no next activation, stopping it", job.Name))
+				return
+			}
+
+			timer := s.clock.NewTimer(next.Sub(s.clock.Now()))
+			select {
+			case <-timer.C():
+			case <-scheduled.updated:
+				timer.Stop()
+				continue
+			case <-cancellableCtx.Done():
+				timer.Stop()
+				return
+			}
+
+			if _, err := scheduled.Run(); errors.Is(err, ErrJobRunning) {
+				logmgr.GetLogger().LogWarning(cancellableCtx, fmt.Sprintf("job %s is still running, skipping this run", job.Name))
+			}
+		}
+	}()
+
+	return scheduled
+}
+
+func (s *Scheduler) next(job Job) time.Time {
+	next := job.Schedule.Next(s.clock.Now())
+	if next.IsZero() || job.Jitter <= 0 {
+		return next
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return next.Add(time.Duration(s.rand.Int63n(int64(job.Jitter))))
+}
@@ -0,0 +1,141 @@
+package async_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/clock"
+)
+
+// waitForTimer waits until the scheduler goroutine has armed its next timer.
+func waitForTimer(t *testing.T, clk *clock.Fake) {
+	assert.Eventually(t, func() bool { return clk.PendingTimers() > 0 }, time.Second, time.Millisecond)
+}
+
+func TestScheduler_RunsOnSchedule(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 10, 5, 59, 0, 0, time.UTC))
+	scheduler := async.NewScheduler(clk)
+
+	schedule, err := async.ParseSchedule("0 6 * * *")
+	assert.NoError(t, err)
+
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	scheduler.Schedule(ctx, &wg, async.Job{Name: "report", Schedule: schedule}, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	waitForTimer(t, clk)
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, int32(0), runs.Load())
+
+	clk.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestScheduler_SkipIfRunning(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC))
+	scheduler := async.NewScheduler(clk)
+
+	schedule, err := async.Every(time.Second)
+	assert.NoError(t, err)
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	scheduler.Schedule(ctx, &wg, async.Job{Name: "save", Schedule: schedule, SkipIfRunning: true}, func(ctx context.Context) error {
+		runs.Add(1)
+		<-release
+		return nil
+	})
+
+	// The first run blocks, the following activations must be skipped.
+	for i := 0; i < 3; i++ {
+		waitForTimer(t, clk)
+		clk.Advance(time.Second)
+	}
+	waitForTimer(t, clk)
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+
+	close(release)
+	cancel()
+	wg.Wait()
+}
+
+func TestScheduler_Update(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC))
+	scheduler := async.NewScheduler(clk)
+
+	hourly, err := async.Every(time.Hour)
+	assert.NoError(t, err)
+	everySecond, err := async.Every(time.Second)
+	assert.NoError(t, err)
+
+	var runs atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	scheduled := scheduler.Schedule(ctx, &wg, async.Job{Name: "update", Schedule: hourly}, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	waitForTimer(t, clk)
+	scheduled.Update(async.Job{Name: "update", Schedule: everySecond})
+
+	// The pending hourly activation is replaced by the new schedule.
+	assert.Eventually(t, func() bool {
+		clk.Advance(time.Second)
+		return runs.Load() > 0
+	}, time.Second, time.Millisecond)
+	assert.Less(t, clk.Now().Sub(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)), time.Hour)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestScheduledJob_RunSkipsIfRunning(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC))
+	scheduler := async.NewScheduler(clk)
+
+	schedule, err := async.Every(time.Hour)
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	scheduled := scheduler.Schedule(ctx, &wg, async.Job{Name: "save", Schedule: schedule, SkipIfRunning: true}, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	assert.Equal(t, async.JobIdle, scheduled.Status().State)
+
+	done, err := scheduled.Run()
+	require.NoError(t, err)
+	assert.Equal(t, async.JobRunning, scheduled.Status().State)
+
+	// A run on demand is refused while the previous one is in flight.
+	_, err = scheduled.Run()
+	assert.ErrorIs(t, err, async.ErrJobRunning)
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, async.JobSucceeded, scheduled.Status().State)
+	assert.NotNil(t, scheduled.Status().FinishedAt)
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,146 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/resilience"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// Defaults applied to the zero values of the persistence configuration.
+const (
+	defaultRetryInitialInterval = time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMultiplier      = 2
+	defaultRetryJitter          = 0.2
+	defaultRetryMaxAttempts     = 5
+	defaultFailureThreshold     = 5
+	defaultOpenTimeout          = time.Minute
+	// finalSaveAttempts is the number of attempts the final save spreads over the time left
+	// before its deadline, at least.
+	finalSaveAttempts = 5
+)
+
+// StateSaver persists the service state, retrying failed saves with an exponential backoff.
+// Repeated failures open a circuit breaker: while it is open, saves are rejected, the
+// readiness check fails and an alert event is published.
+type StateSaver struct {
+	service services.GopherService
+	clock   clock.Clock
+	backoff resilience.Backoff
+	breaker *resilience.CircuitBreaker
+	bus     *events.Bus
+}
+
+func NewStateSaver(service services.GopherService, cfg config.Persistence, clk clock.Clock, bus *events.Bus) *StateSaver {
+	s := &StateSaver{
+		service: service,
+		clock:   clk,
+		bus:     bus,
+		backoff: resilience.Backoff{
+			InitialInterval: valueOrDefault(cfg.Retry.InitialInterval, defaultRetryInitialInterval),
+			MaxInterval:     valueOrDefault(cfg.Retry.MaxInterval, defaultRetryMaxInterval),
+			Multiplier:      valueOrDefault(cfg.Retry.Multiplier, defaultRetryMultiplier),
+			Jitter:          valueOrDefault(cfg.Retry.Jitter, defaultRetryJitter),
+			MaxAttempts:     valueOrDefault(cfg.Retry.MaxAttempts, defaultRetryMaxAttempts),
+		},
+	}
+
+	s.breaker = resilience.NewCircuitBreaker(
+		clk,
+		valueOrDefault(cfg.CircuitBreaker.FailureThreshold, defaultFailureThreshold),
+		valueOrDefault(cfg.CircuitBreaker.OpenTimeout, defaultOpenTimeout),
+		s.onStateChange,
+	)
+
+	return s
+}
+
+// Save saves the state through the circuit breaker, retrying the failed attempts.
+func (s *StateSaver) Save(ctx context.Context) error {
+	return resilience.Retry(ctx, s.clock, s.backoff, func(ctx context.Context) error {
+		if err := s.breaker.Allow(); err != nil {
+			return resilience.Permanent(err)
+		}
+
+		return s.attempt()
+	})
+}
+
+// SaveFinal saves the state retrying the failed attempts until ctx is done, even if the
+// circuit is open, so ctx must be bounded. The delays between the attempts are shortened to
+// fit several of them before the deadline of ctx. It is meant for the last save before shutdown.
+func (s *StateSaver) SaveFinal(ctx context.Context) error {
+	policy := s.backoff
+	policy.MaxAttempts = -1
+	if deadline, ok := ctx.Deadline(); ok {
+		if maxInterval := deadline.Sub(s.clock.Now()) / finalSaveAttempts; maxInterval < policy.MaxInterval {
+			policy.MaxInterval = maxInterval
+		}
+		if policy.InitialInterval > policy.MaxInterval {
+			policy.InitialInterval = policy.MaxInterval
+		}
+	}
+
+	return resilience.Retry(ctx, s.clock, policy, func(ctx context.Context) error {
+		return s.attempt()
+	})
+}
+
+// Ready is a health.Check failing while the circuit is open.
+func (s *StateSaver) Ready(_ context.Context) error {
+	if state := s.breaker.State(); state != resilience.StateClosed {
+		return errors.Errorf("state persistence circuit is %s", state)
+	}
+
+	return nil
+}
+
+func (s *StateSaver) attempt() error {
+	if err := s.service.SaveState(); err != nil {
+		s.breaker.Failure()
+		return err
+	}
+
+	s.breaker.Success()
+
+	return nil
+}
+
+func (s *StateSaver) onStateChange(from, to resilience.State) {
+	ctx := context.Background()
+
+	switch to {
+	case resilience.StateOpen:
+		logmgr.GetLogger().LogError(ctx, fmt.Sprintf("state persistence circuit opened (was %s)", from))
+		s.bus.Publish(events.Event{
+			Type:    events.AlertPersistenceFailing,
+			Time:    s.clock.Now(),
+			Message: "Saving the state keeps failing, the persistence circuit is open",
+		})
+	case resilience.StateClosed:
+		logmgr.GetLogger().LogInfo(ctx, "state persistence circuit closed")
+		s.bus.Publish(events.Event{
+			Type:    events.AlertPersistenceRecovered,
+			Time:    s.clock.Now(),
+			Message: "Saving the state succeeded again, the persistence circuit is closed",
+		})
+	}
+}
+
+func valueOrDefault[T comparable](value, defaultValue T) T {
+	var zero T
+	if value == zero {
+		return defaultValue
+	}
+
+	return value
+}
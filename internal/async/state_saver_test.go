@@ -0,0 +1,100 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/resilience"
+)
+
+func testPersistenceConfig() config.Persistence {
+	return config.Persistence{
+		Retry: config.Retry{
+			InitialInterval: time.Millisecond,
+			MaxInterval:     2 * time.Millisecond,
+			MaxAttempts:     3,
+		},
+		CircuitBreaker: config.CircuitBreaker{
+			FailureThreshold: 3,
+			OpenTimeout:      time.Hour,
+		},
+	}
+}
+
+func TestStateSaver_RetriesUntilSuccess(t *testing.T) {
+	mockService := new(MockGopherService)
+	mockService.On("SaveState").Return(errors.New("disk full")).Twice()
+	mockService.On("SaveState").Return(nil).Once()
+
+	saver := async.NewStateSaver(mockService, testPersistenceConfig(), clock.New(), events.NewBus())
+
+	assert.NoError(t, saver.Save(context.Background()))
+	assert.NoError(t, saver.Ready(context.Background()))
+	mockService.AssertNumberOfCalls(t, "SaveState", 3)
+}
+
+func TestStateSaver_OpensCircuitAfterRepeatedFailures(t *testing.T) {
+	mockService := new(MockGopherService)
+	mockService.On("SaveState").Return(errors.New("permission denied"))
+
+	bus := events.NewBus()
+	alerts, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+
+	saver := async.NewStateSaver(mockService, testPersistenceConfig(), clock.New(), bus)
+
+	err := saver.Save(context.Background())
+	assert.Error(t, err)
+	assert.Error(t, saver.Ready(context.Background()))
+
+	alert := <-alerts
+	assert.Equal(t, events.AlertPersistenceFailing, alert.Type)
+
+	// While the circuit is open the saves are rejected without touching the disk.
+	err = saver.Save(context.Background())
+	assert.True(t, errors.Is(err, resilience.ErrCircuitOpen))
+	mockService.AssertNumberOfCalls(t, "SaveState", 3)
+}
+
+func TestStateSaver_SaveFinalIgnoresOpenCircuit(t *testing.T) {
+	mockService := new(MockGopherService)
+	mockService.On("SaveState").Return(errors.New("disk full")).Times(3)
+	mockService.On("SaveState").Return(nil)
+
+	bus := events.NewBus()
+	alerts, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+
+	saver := async.NewStateSaver(mockService, testPersistenceConfig(), clock.New(), bus)
+	assert.Error(t, saver.Save(context.Background()))
+
+	assert.NoError(t, saver.SaveFinal(context.Background()))
+	assert.NoError(t, saver.Ready(context.Background()))
+
+	assert.Equal(t, events.AlertPersistenceFailing, (<-alerts).Type)
+	assert.Equal(t, events.AlertPersistenceRecovered, (<-alerts).Type)
+}
+
+func TestStateSaver_SaveFinalRetriesUntilDeadline(t *testing.T) {
+	mockService := new(MockGopherService)
+	mockService.On("SaveState").Return(errors.New("disk full")).Times(2)
+	mockService.On("SaveState").Return(nil)
+
+	// A single attempt and retry delays longer than the deadline: the final save still retries.
+	cfg := testPersistenceConfig()
+	cfg.Retry = config.Retry{InitialInterval: 10 * time.Second, MaxAttempts: 1}
+	saver := async.NewStateSaver(mockService, cfg, clock.New(), events.NewBus())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, saver.SaveFinal(ctx))
+	mockService.AssertNumberOfCalls(t, "SaveState", 3)
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// Authentication methods recorded on the Principal.
+const (
+	MethodAPIKey = "api-key"
+	MethodJWT    = "jwt"
+	// MethodNone marks the anonymous caller of a service running without authentication.
+	MethodNone = "none"
+)
+
+// AnonymousSubject is the subject of every caller when authentication is disabled.
+const AnonymousSubject = "anonymous"
+
+var (
+	// ErrMissingCredentials is returned when the request carries no credentials.
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is returned when the credentials are not valid.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Roles known by the authorization policy.
+const (
+	RoleRenter  = "renter"
+	RoleManager = "manager"
+	RoleAdmin   = "admin"
+)
+
+// RolesClaim is the JWT claim carrying the roles of the subject,
+// either as an array or as a space separated string.
+const RolesClaim = "roles"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Method  string
+	Roles   []string
+}
+
+// HasAnyRole reports whether the principal has at least one of the given roles.
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	for _, granted := range p.Roles {
+		for _, role := range roles {
+			if granted == role {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Anonymous returns the principal standing for every caller when authentication is disabled: a
+// renter, who cannot call the manager and admin operations.
+func Anonymous() *Principal {
+	return &Principal{Subject: AnonymousSubject, Method: MethodNone, Roles: []string{RoleRenter}}
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Authenticator validates static API keys and HS256/RS256 JWT bearer tokens.
+type Authenticator struct {
+	apiKeys   []apiKey
+	hmacKey   []byte
+	rsaKey    *rsa.PublicKey
+	issuer    string
+	audience  string
+	jwtParser *jwt.Parser
+}
+
+type apiKey struct {
+	hash    [sha256.Size]byte
+	subject string
+	roles   []string
+}
+
+// NewAuthenticator builds an Authenticator from the configuration, loading the RSA public key if configured.
+func NewAuthenticator(cfg config.Auth) (*Authenticator, error) {
+	a := &Authenticator{
+		issuer:   cfg.JWT.Issuer,
+		audience: cfg.JWT.Audience,
+	}
+
+	for _, key := range cfg.APIKeys {
+		if key.Key == "" || key.Subject == "" {
+			return nil, errors.New("api keys require both key and subject")
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{hash: sha256.Sum256([]byte(key.Key)), subject: key.Subject, roles: key.Roles})
+	}
+
+	var methods []string
+	if cfg.JWT.HMACSecret != "" {
+		a.hmacKey = []byte(cfg.JWT.HMACSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.JWT.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.JWT.RSAPublicKeyFile)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to read the JWT RSA public key")
+		}
+
+		a.rsaKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to parse the JWT RSA public key")
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if a.issuer != "" {
+		options = append(options, jwt.WithIssuer(a.issuer))
+	}
+	if a.audience != "" {
+		options = append(options, jwt.WithAudience(a.audience))
+	}
+	a.jwtParser = jwt.NewParser(options...)
+
+	return a, nil
+}
+
+// AuthenticateAPIKey returns the principal owning the given API key.
+func (a *Authenticator) AuthenticateAPIKey(key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	// Compare against every key so that the time taken does not leak which key matched.
+	var match *apiKey
+	for i := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], a.apiKeys[i].hash[:]) == 1 {
+			match = &a.apiKeys[i]
+		}
+	}
+
+	if match == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{Subject: match.subject, Method: MethodAPIKey, Roles: match.roles}, nil
+}
+
+// AuthenticateBearer validates a JWT and returns the principal identified by its subject.
+func (a *Authenticator) AuthenticateBearer(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	if a.hmacKey == nil && a.rsaKey == nil {
+		return nil, errors.WithMessage(ErrInvalidCredentials, "bearer tokens are not enabled")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.jwtParser.ParseWithClaims(token, claims, a.verificationKey)
+	if err != nil {
+		return nil, errors.WithMessage(ErrInvalidCredentials, err.Error())
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || strings.TrimSpace(subject) == "" {
+		return nil, errors.WithMessage(ErrInvalidCredentials, "token has no subject")
+	}
+
+	return &Principal{Subject: subject, Method: MethodJWT, Roles: rolesFromClaims(claims)}, nil
+}
+
+func rolesFromClaims(claims jwt.MapClaims) []string {
+	switch value := claims[RolesClaim].(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		roles := make([]string, 0, len(value))
+		for _, role := range value {
+			if r, ok := role.(string); ok {
+				roles = append(roles, r)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
+
+func (a *Authenticator) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.hmacKey, nil
+	case jwt.SigningMethodRS256.Alg():
+		return a.rsaKey, nil
+	default:
+		return nil, errors.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
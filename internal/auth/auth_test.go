@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+const hmacSecret = "test-secret"
+
+func writeRSAPublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwt.pub.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	return path
+}
+
+func newAuthenticator(t *testing.T, rsaKey *rsa.PrivateKey) *auth.Authenticator {
+	authenticator, err := auth.NewAuthenticator(config.Auth{
+		Enabled: true,
+		APIKeys: []config.APIKey{{Key: "secret-key", Subject: "ops-script", Roles: []string{auth.RoleAdmin}}},
+		JWT: config.JWT{
+			Issuer:           "gophernet",
+			HMACSecret:       hmacSecret,
+			RSAPublicKeyFile: writeRSAPublicKey(t, rsaKey),
+		},
+	})
+	require.NoError(t, err)
+
+	return authenticator
+}
+
+func claims(subject, issuer string, expiresIn time.Duration) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": subject,
+		"iss": issuer,
+		"exp": time.Now().Add(expiresIn).Unix(),
+	}
+}
+
+func TestAuthenticator_APIKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	authenticator := newAuthenticator(t, rsaKey)
+
+	principal, err := authenticator.AuthenticateAPIKey("secret-key")
+	assert.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Subject: "ops-script", Method: auth.MethodAPIKey, Roles: []string{auth.RoleAdmin}}, principal)
+
+	_, err = authenticator.AuthenticateAPIKey("wrong-key")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authenticator.AuthenticateAPIKey("")
+	assert.ErrorIs(t, err, auth.ErrMissingCredentials)
+}
+
+func TestAuthenticator_Bearer(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	authenticator := newAuthenticator(t, rsaKey)
+
+	sign := func(method jwt.SigningMethod, key interface{}, c jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, c).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+
+	hs256 := sign(jwt.SigningMethodHS256, []byte(hmacSecret), claims("alice", "gophernet", time.Hour))
+	principal, err := authenticator.AuthenticateBearer(hs256)
+	assert.NoError(t, err)
+	assert.Equal(t, &auth.Principal{Subject: "alice", Method: auth.MethodJWT}, principal)
+
+	rsClaims := claims("bob", "gophernet", time.Hour)
+	rsClaims[auth.RolesClaim] = []string{auth.RoleRenter, auth.RoleManager}
+	rs256 := sign(jwt.SigningMethodRS256, rsaKey, rsClaims)
+	principal, err = authenticator.AuthenticateBearer(rs256)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", principal.Subject)
+	assert.Equal(t, []string{auth.RoleRenter, auth.RoleManager}, principal.Roles)
+
+	invalid := map[string]string{
+		"expired":       sign(jwt.SigningMethodHS256, []byte(hmacSecret), claims("alice", "gophernet", -time.Hour)),
+		"wrong issuer":  sign(jwt.SigningMethodHS256, []byte(hmacSecret), claims("alice", "someone-else", time.Hour)),
+		"wrong secret":  sign(jwt.SigningMethodHS256, []byte("other-secret"), claims("alice", "gophernet", time.Hour)),
+		"wrong rsa key": sign(jwt.SigningMethodRS256, otherKey, claims("alice", "gophernet", time.Hour)),
+		"no subject":    sign(jwt.SigningMethodHS256, []byte(hmacSecret), claims("", "gophernet", time.Hour)),
+		"unsupported":   sign(jwt.SigningMethodHS512, []byte(hmacSecret), claims("alice", "gophernet", time.Hour)),
+		"malformed":     "not-a-token",
+	}
+	for name, token := range invalid {
+		_, err := authenticator.AuthenticateBearer(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials, name)
+	}
+}
@@ -0,0 +1,207 @@
+// Package billing groups the ledger entries of a renter into monthly invoices, and writes them as
+// JSON, CSV and HTML.
+package billing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// PeriodLayout is the layout of the monthly periods of the invoices.
+const PeriodLayout = "2006-01"
+
+// Format is an encoding of a list of invoices.
+type Format string
+
+const (
+	// FormatJSON is a JSON array of invoices.
+	FormatJSON Format = "json"
+	// FormatCSV is a row per entry, followed by a total row per invoice.
+	FormatCSV Format = "csv"
+	// FormatHTML is a printable HTML page.
+	FormatHTML Format = "html"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatJSON, FormatCSV, FormatHTML}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(s) {
+			return format, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown format %q, expected one of %v", s, Formats)
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Invoice lists the ledger entries of a renter in a currency during a month. Total is the sum of
+// their amounts, in minor units of Currency.
+type Invoice struct {
+	Number   string                `json:"number"`
+	Renter   string                `json:"renter"`
+	Period   string                `json:"period"` // month of the entries, as PeriodLayout
+	Currency string                `json:"currency,omitempty"`
+	Entries  []*models.LedgerEntry `json:"entries"`
+	Total    int64                 `json:"total"`
+}
+
+// Invoices groups the ledger entries of the renter into an invoice per month, in UTC, and
+// currency, sorted by period then currency.
+func Invoices(renter string, entries []*models.LedgerEntry) []*Invoice {
+	byKey := make(map[string]*Invoice)
+	var invoices []*Invoice
+	for _, entry := range entries {
+		period := entry.CreatedAt.UTC().Format(PeriodLayout)
+		key := period + "/" + entry.Currency
+
+		invoice, exists := byKey[key]
+		if !exists {
+			invoice = &Invoice{Number: invoiceNumber(renter, period, entry.Currency), Renter: renter, Period: period, Currency: entry.Currency}
+			byKey[key] = invoice
+			invoices = append(invoices, invoice)
+		}
+		invoice.Entries = append(invoice.Entries, entry)
+		invoice.Total += entry.Amount
+	}
+
+	sort.SliceStable(invoices, func(i, j int) bool {
+		if invoices[i].Period != invoices[j].Period {
+			return invoices[i].Period < invoices[j].Period
+		}
+		return invoices[i].Currency < invoices[j].Currency
+	})
+
+	return invoices
+}
+
+func invoiceNumber(renter, period, currency string) string {
+	number := renter + "-" + strings.ReplaceAll(period, "-", "")
+	if currency != "" {
+		number += "-" + currency
+	}
+
+	return number
+}
+
+// Write writes the invoices to w in the format.
+func Write(w io.Writer, format Format, invoices []*Invoice) error {
+	if invoices == nil {
+		invoices = []*Invoice{}
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, invoices)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, invoices)
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(invoices)
+	}
+}
+
+// csvColumns are the columns of the CSV format. The total rows only have the invoice columns,
+// the kind "total" and the amount.
+var csvColumns = []string{"invoice", "period", "currency", "createdAt", "kind", "rentalId", "burrow", "description", "amount"}
+
+func writeCSV(w io.Writer, invoices []*Invoice) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, invoice := range invoices {
+		for _, entry := range invoice.Entries {
+			row := []string{invoice.Number, invoice.Period, invoice.Currency, entry.CreatedAt.UTC().Format(time.RFC3339),
+				string(entry.Kind), entry.RentalID, entry.Burrow, entry.Description, strconv.FormatInt(entry.Amount, 10)}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+
+		total := []string{invoice.Number, invoice.Period, invoice.Currency, "", "total", "", "", "", strconv.FormatInt(invoice.Total, 10)}
+		if err := writer.Write(total); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// FormatAmount writes an amount in minor units as a decimal amount of its currency, assuming two
+// decimals: 1234 EUR is "12.34 EUR".
+func FormatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s%d.%02d %s", sign, amount/100, amount%100, currency))
+}
+
+var htmlTemplate = template.Must(template.New("invoices").Funcs(template.FuncMap{
+	"amount": FormatAmount,
+	"date":   func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>GopherNet invoices</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { border-bottom: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.amount, th.amount { text-align: right; }
+tfoot td { font-weight: bold; }
+@media print { section { page-break-after: always; } }
+</style>
+</head>
+<body>
+{{- range .}}
+<section>
+<h1>Invoice {{.Number}}</h1>
+<p>Renter: {{.Renter}}<br>Period: {{.Period}}</p>
+<table>
+<thead><tr><th>Date</th><th>Kind</th><th>Burrow</th><th>Description</th><th class="amount">Amount</th></tr></thead>
+<tbody>
+{{- $currency := .Currency}}
+{{- range .Entries}}
+<tr><td>{{date .CreatedAt}}</td><td>{{.Kind}}</td><td>{{.Burrow}}</td><td>{{.Description}}</td><td class="amount">{{amount .Amount $currency}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot><tr><td colspan="4">Total</td><td class="amount">{{amount .Total .Currency}}</td></tr></tfoot>
+</table>
+</section>
+{{- else}}
+<p>No invoices.</p>
+{{- end}}
+</body>
+</html>
+`))
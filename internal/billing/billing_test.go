@@ -0,0 +1,65 @@
+package billing_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/billing"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+var entries = []*models.LedgerEntry{
+	{Renter: "alice", Kind: models.EntryCharge, Amount: 1200, Currency: "EUR", Burrow: "The Deep Den", CreatedAt: time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC)},
+	{Renter: "alice", Kind: models.EntryCharge, Amount: 800, Currency: "EUR", Burrow: "The Deep Den", CreatedAt: time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC)},
+	{Renter: "alice", Kind: models.EntryRefund, Amount: -150, Currency: "EUR", Description: "Flooded <tunnel>", CreatedAt: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+	{Renter: "alice", Kind: models.EntryAdjustment, Amount: 500, Currency: "CHF", CreatedAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+}
+
+func TestInvoices(t *testing.T) {
+	invoices := billing.Invoices("alice", entries)
+	require.Len(t, invoices, 3)
+
+	// An invoice per month and currency.
+	assert.Equal(t, "alice-202401-CHF", invoices[0].Number)
+	assert.Equal(t, int64(500), invoices[0].Total)
+	assert.Equal(t, "alice-202401-EUR", invoices[1].Number)
+	assert.Equal(t, int64(1200), invoices[1].Total)
+	assert.Equal(t, "2024-02", invoices[2].Period)
+	assert.Len(t, invoices[2].Entries, 2)
+	assert.Equal(t, int64(650), invoices[2].Total)
+
+	assert.Empty(t, billing.Invoices("alice", nil))
+}
+
+func TestWrite(t *testing.T) {
+	invoices := billing.Invoices("alice", entries)
+
+	var buf bytes.Buffer
+	require.NoError(t, billing.Write(&buf, billing.FormatCSV, invoices))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 1+4+3)
+	assert.Equal(t, "invoice", rows[0][0])
+	assert.Equal(t, []string{"alice-202402-EUR", "2024-02", "EUR", "", "total", "", "", "", "650"}, rows[len(rows)-1])
+
+	buf.Reset()
+	require.NoError(t, billing.Write(&buf, billing.FormatHTML, invoices))
+	assert.Contains(t, buf.String(), "Invoice alice-202402-EUR")
+	assert.Contains(t, buf.String(), "-1.50 EUR")
+	assert.Contains(t, buf.String(), "Flooded &lt;tunnel&gt;")
+
+	buf.Reset()
+	require.NoError(t, billing.Write(&buf, billing.FormatJSON, nil))
+	assert.JSONEq(t, `[]`, buf.String())
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "12.34 EUR", billing.FormatAmount(1234, "EUR"))
+	assert.Equal(t, "-0.05 EUR", billing.FormatAmount(-5, "EUR"))
+	assert.Equal(t, "7.00", billing.FormatAmount(700, ""))
+}
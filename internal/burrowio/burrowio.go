@@ -0,0 +1,349 @@
+// Package burrowio reads and writes burrows as JSON (the format of the state file), CSV and NDJSON.
+package burrowio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// Format is an encoding of a list of burrows.
+type Format string
+
+const (
+	// FormatJSON is a JSON array of burrows, as in the state file.
+	FormatJSON Format = "json"
+	// FormatCSV is a CSV file with a header row naming the columns.
+	FormatCSV Format = "csv"
+	// FormatNDJSON is a JSON burrow per line.
+	FormatNDJSON Format = "ndjson"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatJSON, FormatCSV, FormatNDJSON}
+
+// CSV columns. The name, depth and width columns are required.
+const (
+	ColumnName     = "name"
+	ColumnDepth    = "depth"
+	ColumnWidth    = "width"
+	ColumnOccupied = "occupied"
+	ColumnAge      = "age"
+	ColumnRentedBy = "rentedBy"
+)
+
+// csvColumns are the columns written to CSV, in order.
+var csvColumns = []string{ColumnName, ColumnDepth, ColumnWidth, ColumnOccupied, ColumnAge, ColumnRentedBy}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(s) {
+			return format, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown format %q, expected one of %v", s, Formats)
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/json"
+	}
+}
+
+// Writer writes burrows one at a time, so that large lists are streamed.
+type Writer interface {
+	Write(burrow *models.Burrow) error
+	// Close completes the document. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a Writer of the format writing to w.
+func NewWriter(w io.Writer, format Format) Writer {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}
+	default:
+		return &jsonWriter{w: w}
+	}
+}
+
+// WriteAll writes the burrows to w in the format.
+func WriteAll(w io.Writer, format Format, burrows []*models.Burrow) error {
+	writer := NewWriter(w, format)
+	for _, burrow := range burrows {
+		if err := writer.Write(burrow); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// jsonWriter writes an indented JSON array, as the state file.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(burrow *models.Burrow) error {
+	data, err := json.MarshalIndent(burrow, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "[\n  "
+	}
+	j.count++
+
+	_, err = io.WriteString(j.w, separator+string(data))
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(burrow *models.Burrow) error {
+	return n.encoder.Encode(burrow)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(burrow *models.Burrow) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		burrow.Name,
+		strconv.FormatFloat(burrow.Depth, 'f', -1, 64),
+		strconv.FormatFloat(burrow.Width, 'f', -1, 64),
+		strconv.FormatBool(burrow.Occupied),
+		strconv.Itoa(burrow.Age),
+		burrow.RentedBy,
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	return c.w.Write(csvColumns)
+}
+
+// Record is a burrow read from a document, or the error of its row.
+// Row is the line of the burrow for CSV (the header being line 1) and NDJSON, and its 1-based
+// position in the array for JSON.
+type Record struct {
+	Row    int
+	Burrow *models.Burrow
+	Err    error
+}
+
+// ReadAll reads the burrows of a document in the format. Rows that cannot be decoded are returned
+// as records with an error, while an error is returned when the document itself is unreadable:
+// a malformed JSON array, or a CSV header missing required columns.
+func ReadAll(r io.Reader, format Format) ([]Record, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatNDJSON:
+		return readNDJSON(r)
+	default:
+		return readJSON(r)
+	}
+}
+
+func readJSON(r io.Reader) ([]Record, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.WithMessage(err, "malformed JSON array")
+	}
+
+	records := make([]Record, 0, len(raw))
+	for i, data := range raw {
+		records = append(records, decodeJSONRecord(i+1, data))
+	}
+
+	return records, nil
+}
+
+func readNDJSON(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		records = append(records, decodeJSONRecord(line, data))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithMessage(err, "failed to read NDJSON")
+	}
+
+	return records, nil
+}
+
+func decodeJSONRecord(row int, data []byte) Record {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var burrow models.Burrow
+	if err := decoder.Decode(&burrow); err != nil {
+		return Record{Row: row, Err: errors.WithMessage(err, "malformed burrow")}
+	}
+
+	return Record{Row: row, Burrow: &burrow}
+}
+
+func readCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "malformed CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+		if !isColumn(column) {
+			return nil, errors.Errorf("unknown CSV column %q, expected %v", column, csvColumns)
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{ColumnName, ColumnDepth, ColumnWidth} {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.Errorf("missing CSV column %q", column)
+		}
+	}
+
+	var records []Record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				records = append(records, Record{Row: parseErr.StartLine, Err: errors.WithMessage(err, "malformed row")})
+				continue
+			}
+			return nil, errors.WithMessage(err, "failed to read CSV")
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if len(fields) != len(header) {
+			records = append(records, Record{Row: line, Err: errors.Errorf("expected %d fields, got %d", len(header), len(fields))})
+			continue
+		}
+
+		burrow, err := parseCSVRow(columns, fields)
+		records = append(records, Record{Row: line, Burrow: burrow, Err: err})
+	}
+}
+
+func parseCSVRow(columns map[string]int, fields []string) (*models.Burrow, error) {
+	field := func(column string) (string, bool) {
+		i, ok := columns[column]
+		if !ok {
+			return "", false
+		}
+		return strings.TrimSpace(fields[i]), true
+	}
+
+	burrow := &models.Burrow{}
+	burrow.Name, _ = field(ColumnName)
+
+	var err error
+	depth, _ := field(ColumnDepth)
+	if burrow.Depth, err = strconv.ParseFloat(depth, 64); err != nil {
+		return nil, errors.Errorf("%s: %q is not a number", ColumnDepth, depth)
+	}
+	width, _ := field(ColumnWidth)
+	if burrow.Width, err = strconv.ParseFloat(width, 64); err != nil {
+		return nil, errors.Errorf("%s: %q is not a number", ColumnWidth, width)
+	}
+	if occupied, ok := field(ColumnOccupied); ok && occupied != "" {
+		if burrow.Occupied, err = strconv.ParseBool(occupied); err != nil {
+			return nil, errors.Errorf("%s: %q is not a boolean", ColumnOccupied, occupied)
+		}
+	}
+	if age, ok := field(ColumnAge); ok && age != "" {
+		if burrow.Age, err = strconv.Atoi(age); err != nil {
+			return nil, errors.Errorf("%s: %q is not an integer", ColumnAge, age)
+		}
+	}
+	burrow.RentedBy, _ = field(ColumnRentedBy)
+
+	return burrow, nil
+}
+
+func isColumn(name string) bool {
+	for _, column := range csvColumns {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,87 @@
+package burrowio_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/burrowio"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+var burrows = []*models.Burrow{
+	{Name: "The Molehole", Depth: 3.0, Width: 1.3, Occupied: true, Age: 50, RentedBy: "alice"},
+	{Name: "Den, \"the deep\"", Depth: 2.25, Width: 1.2, Age: 40},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range burrowio.Formats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, burrowio.WriteAll(&buf, format, burrows))
+
+			records, err := burrowio.ReadAll(&buf, format)
+			require.NoError(t, err)
+			require.Len(t, records, len(burrows))
+			for i, record := range records {
+				require.NoError(t, record.Err)
+				assert.Equal(t, burrows[i], record.Burrow)
+			}
+		})
+	}
+}
+
+func TestWriteAll_JSONMatchesStateFile(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, burrowio.WriteAll(&buf, burrowio.FormatJSON, burrows))
+
+	expected, err := json.MarshalIndent(burrows, "", "  ")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected)+"\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, burrowio.WriteAll(&buf, burrowio.FormatJSON, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestReadAll_RowErrors(t *testing.T) {
+	records, err := burrowio.ReadAll(strings.NewReader(
+		"name,depth,width\n"+
+			"Good,1.5,1\n"+
+			"Bad depth,deep,1\n"+
+			"Short,1\n"), burrowio.FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, &models.Burrow{Name: "Good", Depth: 1.5, Width: 1}, records[0].Burrow)
+	assert.Equal(t, 3, records[1].Row)
+	assert.ErrorContains(t, records[1].Err, "depth")
+	assert.Equal(t, 4, records[2].Row)
+	assert.Error(t, records[2].Err)
+
+	records, err = burrowio.ReadAll(strings.NewReader(
+		`{"name":"Good","depth":1,"width":1}`+"\n\n"+
+			`{"name":"Typo","dept":1}`+"\n"), burrowio.FormatNDJSON)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, 3, records[1].Row)
+	assert.Error(t, records[1].Err)
+}
+
+func TestReadAll_UnreadableDocuments(t *testing.T) {
+	_, err := burrowio.ReadAll(strings.NewReader("name,depth\nA,1\n"), burrowio.FormatCSV)
+	assert.ErrorContains(t, err, `missing CSV column "width"`)
+
+	_, err = burrowio.ReadAll(strings.NewReader("name,depth,width,color\n"), burrowio.FormatCSV)
+	assert.ErrorContains(t, err, `unknown CSV column "color"`)
+
+	_, err = burrowio.ReadAll(strings.NewReader(`{"name":"A"}`), burrowio.FormatJSON)
+	assert.Error(t, err)
+
+	_, err = burrowio.ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/burrowio"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// DefaultDataFile is the default state file.
+const DefaultDataFile = config.DefaultStateFile
+
+// maxSimulatedMinutes bounds the simulations (30 days).
+const maxSimulatedMinutes = 30 * 24 * 60
+
+// Formats of the report command.
+const (
+	reportFormatText = "text"
+	reportFormatJSON = "json"
+)
+
+// formatTable is the default format of the list and simulate commands.
+const formatTable = "table"
+
+// openState loads the state file into a service. The offline commands changing the state file go
+// through updateState, which refuses to run against the state file of a running server.
+func openState(dataFile string) (*services.DefaultBurrowService, error) {
+	return openLimitedState(dataFile, models.RenterLimits{})
+}
+
+// openLimitedState loads the state file into a service limiting the renters without an account to
+// limits.
+func openLimitedState(dataFile string, limits models.RenterLimits) (*services.DefaultBurrowService, error) {
+	repo := repository.NewMemoryRepository(dataFile, "")
+	repo.SetDefaultLimits(limits)
+	service := services.NewGopherNetService(repo)
+	if err := service.LoadInitialState(); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func addDataFileFlag(fs *flag.FlagSet) *string {
+	return fs.String("dataFile", DefaultDataFile, "Path to the state file")
+}
+
+// runList prints the burrows of the state file or of the server.
+func runList(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	format := fs.String("format", formatTable, "Output format: table, json, csv or ndjson")
+	server := addServerFlags(e, fs)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var burrows []*models.Burrow
+	if server.online() {
+		var err error
+		if burrows, err = server.client().listBurrows(context.Background()); err != nil {
+			return err
+		}
+	} else {
+		service, err := openState(*dataFile)
+		if err != nil {
+			return err
+		}
+		burrows = service.GetAllBurrows()
+	}
+
+	return writeBurrows(e.stdout, *format, burrows)
+}
+
+// runRent rents a burrow in the state file, or on the server on behalf of the authenticated caller.
+func runRent(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	renter := fs.String("renter", "", "Renter recorded on the burrow, offline only (online, the authenticated caller)")
+	maxConcurrentBurrows := fs.Int("maxConcurrentBurrows", 0, "Burrows a renter without an account rents at most, offline only (0 is unlimited)")
+	server := addServerFlags(e, fs)
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+	name := fs.Arg(0)
+
+	if server.online() {
+		if err := server.client().rentBurrow(context.Background(), name); err != nil {
+			return err
+		}
+	} else {
+		limits := models.RenterLimits{MaxConcurrentBurrows: *maxConcurrentBurrows}
+		err := updateLimitedState(*dataFile, limits, func(service *services.DefaultBurrowService) error {
+			return service.RentBurrow(name, *renter)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "Rented %q\n", name)
+	return nil
+}
+
+// runRelease releases a burrow in the state file or on the server.
+func runRelease(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	server := addServerFlags(e, fs)
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+	name := fs.Arg(0)
+
+	if server.online() {
+		if err := server.client().releaseBurrow(context.Background(), name); err != nil {
+			return err
+		}
+	} else {
+		err := updateState(*dataFile, func(service *services.DefaultBurrowService) error {
+			return service.ReleaseBurrow(name, "")
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(e.stdout, "Released %q\n", name)
+	return nil
+}
+
+// runReport prints the report of the state file or of the server.
+func runReport(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	format := fs.String("format", reportFormatText, "Output format: text or json")
+	server := addServerFlags(e, fs)
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *format != reportFormatText && *format != reportFormatJSON {
+		return errors.Errorf("unknown format %q, expected %s or %s", *format, reportFormatText, reportFormatJSON)
+	}
+
+	var report string
+	if server.online() {
+		var err error
+		if report, err = server.client().report(context.Background()); err != nil {
+			return err
+		}
+	} else {
+		service, err := openState(*dataFile)
+		if err != nil {
+			return err
+		}
+		if report, err = service.GenerateReport(); err != nil {
+			return err
+		}
+	}
+
+	if *format == reportFormatJSON {
+		encoder := json.NewEncoder(e.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			Report string `json:"report"`
+		}{Report: report})
+	}
+
+	_, err := io.WriteString(e.stdout, report)
+	return err
+}
+
+// runValidate checks that every burrow of a state file would be accepted by the service, reporting
+// every problem.
+func runValidate(e *env, args []string) error {
+	fs := newFlagSet(e)
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+	file := fs.Arg(0)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	data, err = repository.StateBurrows(data)
+	if err != nil {
+		return errors.WithMessage(err, file)
+	}
+
+	records, err := burrowio.ReadAll(bytes.NewReader(data), burrowio.FormatJSON)
+	if err != nil {
+		return errors.WithMessage(err, file)
+	}
+
+	// Add the burrows to an empty service, which rejects invalid and duplicate burrows.
+	service := services.NewGopherNetService(repository.NewMemoryRepository("", ""))
+	problems := addRecords(e, service, records)
+	if problems > 0 {
+		return errors.Errorf("%s: %d invalid burrow(s)", file, problems)
+	}
+
+	fmt.Fprintf(e.stdout, "%s: %d burrow(s), valid\n", file, len(records))
+	return nil
+}
+
+// runImport adds the burrows of a file to the state file. Invalid burrows are reported and
+// skipped, existing ones are handled as --mode says, and the others are saved.
+func runImport(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	formatName := fs.String("format", "", "Format of the file: json, csv or ndjson (default from the file extension)")
+	modeName := fs.String("mode", string(services.ImportCreate), "Existing burrows: create (fail), upsert or skip-existing")
+	dryRun := fs.Bool("dry-run", false, "Report what would be imported without changing the state file")
+	if err := parse(fs, args, 1); err != nil {
+		return err
+	}
+	file := fs.Arg(0)
+
+	format, err := importFormat(*formatName, file)
+	if err != nil {
+		return err
+	}
+	mode, err := services.ParseImportMode(*modeName)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	records, err := burrowio.ReadAll(input, format)
+	if err != nil {
+		return errors.WithMessage(err, file)
+	}
+
+	var result services.ImportResult
+	importRecords := func(service *services.DefaultBurrowService) error {
+		var err error
+		result, err = service.ImportBurrows(context.Background(), records, mode, *dryRun)
+		return err
+	}
+	if *dryRun {
+		service, err := openState(*dataFile)
+		if err != nil {
+			return err
+		}
+		if err := importRecords(service); err != nil {
+			return err
+		}
+	} else if err := updateState(*dataFile, importRecords); err != nil {
+		return err
+	}
+
+	failures := result.Failures()
+	for _, row := range failures {
+		fmt.Fprintf(e.stderr, "row %d: %v\n", row.Row, row.Err)
+	}
+
+	if *dryRun {
+		fmt.Fprint(e.stdout, "Dry run: ")
+	}
+	imported := result.Count(services.ImportCreated) + result.Count(services.ImportUpdated)
+	fmt.Fprintf(e.stdout, "Imported %d burrow(s), %d skipped, %d failed\n", imported, result.Count(services.ImportSkipped), len(failures))
+	if len(failures) > 0 {
+		return errors.Errorf("%d burrow(s) not imported", len(failures))
+	}
+
+	return nil
+}
+
+// runExport writes the burrows of the state file.
+func runExport(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	formatName := fs.String("format", string(burrowio.FormatJSON), "Output format: json, csv or ndjson")
+	output := fs.String("output", "-", "Output file, - for the standard output")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	format, err := burrowio.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+
+	service, err := openState(*dataFile)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		return burrowio.WriteAll(e.stdout, format, service.GetAllBurrows())
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := burrowio.WriteAll(f, format, service.GetAllBurrows()); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// runSimulate applies the periodic update of the burrows, once per minute, and prints the result.
+func runSimulate(e *env, args []string) error {
+	fs := newFlagSet(e)
+	dataFile := addDataFileFlag(fs)
+	minutes := fs.Int("minutes", 60, "Number of minutes to simulate")
+	format := fs.String("format", formatTable, "Output format: table, json, csv or ndjson")
+	save := fs.Bool("save", false, "Save the simulated state to the state file")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *minutes < 0 || *minutes > maxSimulatedMinutes {
+		return errors.Errorf("minutes must be between 0 and %d", maxSimulatedMinutes)
+	}
+
+	if *save {
+		unlock, err := lockState(*dataFile)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	service, err := openState(*dataFile)
+	if err != nil {
+		return err
+	}
+
+	collapsed := countCollapsed(service.GetAllBurrows())
+	for i := 0; i < *minutes; i++ {
+		service.UpdateBurrows()
+	}
+
+	burrows := service.GetAllBurrows()
+	if err := writeBurrows(e.stdout, *format, burrows); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "After %d minute(s): %d burrow(s) collapsed, %d newly\n", *minutes, countCollapsed(burrows), countCollapsed(burrows)-collapsed)
+
+	if *save {
+		return service.SaveState()
+	}
+
+	return nil
+}
+
+// updateState applies update to the state file and saves it.
+func updateState(dataFile string, update func(service *services.DefaultBurrowService) error) error {
+	return updateLimitedState(dataFile, models.RenterLimits{}, update)
+}
+
+// updateLimitedState applies update to the state file, limiting the renters without an account to
+// limits, and saves it.
+func updateLimitedState(dataFile string, limits models.RenterLimits, update func(service *services.DefaultBurrowService) error) error {
+	unlock, err := lockState(dataFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	service, err := openLimitedState(dataFile, limits)
+	if err != nil {
+		return err
+	}
+
+	if err := update(service); err != nil {
+		return err
+	}
+
+	return service.SaveState()
+}
+
+// addRecords adds the burrows of the records through the service, printing every failed record,
+// and returns the number of failures.
+func addRecords(e *env, service *services.DefaultBurrowService, records []burrowio.Record) int {
+	problems := 0
+	for _, record := range records {
+		err := record.Err
+		if err == nil {
+			err = service.AddBurrow(record.Burrow)
+		}
+		if err != nil {
+			problems++
+			fmt.Fprintf(e.stderr, "row %d: %v\n", record.Row, err)
+		}
+	}
+
+	return problems
+}
+
+// importFormat returns the named format or, without a name, the format of the file extension.
+func importFormat(name, file string) (burrowio.Format, error) {
+	if name != "" {
+		return burrowio.ParseFormat(name)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return burrowio.FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return burrowio.FormatNDJSON, nil
+	case ".json":
+		return burrowio.FormatJSON, nil
+	}
+
+	return "", errors.Errorf("cannot guess the format of %q, set --format", file)
+}
+
+// writeBurrows writes the burrows as a table, or in a burrowio format.
+func writeBurrows(w io.Writer, format string, burrows []*models.Burrow) error {
+	if format != formatTable {
+		f, err := burrowio.ParseFormat(format)
+		if err != nil {
+			return err
+		}
+		return burrowio.WriteAll(w, f, burrows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tDEPTH\tWIDTH\tAGE\tSTATUS\tRENTED BY")
+	for _, burrow := range burrows {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%d\t%s\t%s\n", burrow.Name, burrow.Depth, burrow.Width, burrow.Age, status(burrow), burrow.RentedBy)
+	}
+
+	return tw.Flush()
+}
+
+func status(burrow *models.Burrow) string {
+	switch {
+	case burrow.HasCollapsed():
+		return "collapsed"
+	case burrow.Occupied:
+		return "occupied"
+	default:
+		return "available"
+	}
+}
+
+func countCollapsed(burrows []*models.Burrow) int {
+	count := 0
+	for _, burrow := range burrows {
+		if burrow.HasCollapsed() {
+			count++
+		}
+	}
+
+	return count
+}
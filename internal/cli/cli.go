@@ -0,0 +1,156 @@
+// Package cli implements the gophernet command line: the serve command starting the service, and
+// the admin commands working offline on a state file or online against a running server.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// Exit codes of Run.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// Environment variables providing the defaults of the online flags.
+const (
+	EnvServer = "GOPHERNET_SERVER"
+	EnvAPIKey = "GOPHERNET_API_KEY"
+	EnvToken  = "GOPHERNET_TOKEN"
+)
+
+// errUsage reports invalid arguments, the usage of the command having been printed.
+var errUsage = errors.New("invalid usage")
+
+// command is a gophernet subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// env is the environment of a command run.
+type env struct {
+	command *command
+	stdout  io.Writer
+	stderr  io.Writer
+	getenv  func(string) string
+}
+
+var commands = []command{
+	{name: "serve", args: "[flags]", summary: "Start the service", run: runServe},
+	{name: "config", args: "check [flags]", summary: "Check the configuration the service would start with", run: runConfig},
+	{name: "list", args: "[flags]", summary: "List the burrows", run: runList},
+	{name: "rent", args: "[flags] <name>", summary: "Rent a burrow", run: runRent},
+	{name: "release", args: "[flags] <name>", summary: "Release a rented burrow", run: runRelease},
+	{name: "report", args: "[flags]", summary: "Print the burrows report", run: runReport},
+	{name: "validate", args: "<file>", summary: "Check a state file", run: runValidate},
+	{name: "import", args: "[flags] <file>", summary: "Add the burrows of a JSON, CSV or NDJSON file to the state file", run: runImport},
+	{name: "export", args: "[flags]", summary: "Write the burrows of the state file as JSON, CSV or NDJSON", run: runExport},
+	{name: "simulate", args: "[flags]", summary: "Print the burrows after some minutes of periodic updates", run: runSimulate},
+}
+
+// Run runs the command line args, without the program name, and returns the exit code.
+// Without a command, or when the first argument is a flag, the service is started.
+func Run(args []string, stdout, stderr io.Writer) int {
+	e := &env{stdout: stdout, stderr: stderr, getenv: os.Getenv}
+
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return ExitOK
+	}
+
+	for i := range commands {
+		if commands[i].name != name {
+			continue
+		}
+
+		e.command = &commands[i]
+		err := e.command.run(e, args)
+		switch {
+		case err == nil || errors.Is(err, flag.ErrHelp):
+			return ExitOK
+		case errors.Is(err, errUsage):
+			return ExitUsage
+		default:
+			fmt.Fprintf(stderr, "gophernet %s: %v\n", name, err)
+			return ExitError
+		}
+	}
+
+	fmt.Fprintf(stderr, "gophernet: unknown command %q\n\n", name)
+	printUsage(stderr)
+
+	return ExitUsage
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gophernet <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "gophernet <command> -h" for the flags of a command.`)
+	fmt.Fprintf(w, "list, rent, release and report work on the state file, or on a running server when --server (or %s) is set.\n", EnvServer)
+}
+
+// newFlagSet returns the flag set of the running command, printing its usage to the stderr of env.
+func newFlagSet(e *env) *flag.FlagSet {
+	cmd := e.command
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: gophernet %s %s\n\n%s.\n", cmd.name, cmd.args, cmd.summary)
+		if hasFlags(fs) {
+			fmt.Fprintln(e.stderr, "\nFlags:")
+			fs.PrintDefaults()
+		}
+	}
+
+	return fs
+}
+
+// parse parses the flags and checks the number of positional arguments.
+func parse(fs *flag.FlagSet, args []string, positional int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	if fs.NArg() != positional {
+		fmt.Fprintf(fs.Output(), "expected %d argument(s), got %d\n", positional, fs.NArg())
+		fs.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	has := false
+	fs.VisitAll(func(*flag.Flag) { has = true })
+
+	return has
+}
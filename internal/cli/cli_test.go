@@ -0,0 +1,255 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/marcodd23/go-micro-core/pkg/configmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/cli"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+const state = `[
+  {"name": "The Molehole", "depth": 3.0, "width": 1.3, "occupied": true, "age": 50, "rentedBy": "alice"},
+  {"name": "The Deep Den", "depth": 2.2, "width": 1.2, "occupied": false, "age": 40}
+]`
+
+// run runs the command line and returns its exit code, standard output and error output.
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := cli.Run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+func readState(t *testing.T, path string) []*models.Burrow {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	data, err = repository.StateBurrows(data)
+	require.NoError(t, err)
+
+	var burrows []*models.Burrow
+	require.NoError(t, json.Unmarshal(data, &burrows))
+
+	return burrows
+}
+
+func TestOffline_RentAndRelease(t *testing.T) {
+	dataFile := writeFile(t, "state.json", state)
+
+	code, stdout, _ := run("list", "--dataFile", dataFile)
+	require.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "The Deep Den")
+	assert.Contains(t, stdout, "available")
+
+	code, _, _ = run("rent", "--dataFile", dataFile, "--renter", "bob", "The Deep Den")
+	require.Equal(t, cli.ExitOK, code)
+	assert.Equal(t, "bob", readState(t, dataFile)[1].RentedBy)
+
+	code, _, stderr := run("rent", "--dataFile", dataFile, "The Deep Den")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, models.ErrBurrowUnavailable.Message)
+
+	code, _, _ = run("release", "--dataFile", dataFile, "The Molehole")
+	require.Equal(t, cli.ExitOK, code)
+	assert.False(t, readState(t, dataFile)[0].Occupied)
+
+	code, stdout, _ = run("report", "--dataFile", dataFile, "--format", "json")
+	require.Equal(t, cli.ExitOK, code)
+	var report struct{ Report string }
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Contains(t, report.Report, "GopherNet Burrow Report")
+}
+
+func TestOffline_RentBeyondThreeBurrows(t *testing.T) {
+	dataFile := writeFile(t, "state.json", `[
+  {"name": "Burrow1", "depth": 1.0, "width": 1.0},
+  {"name": "Burrow2", "depth": 1.0, "width": 1.0},
+  {"name": "Burrow3", "depth": 1.0, "width": 1.0},
+  {"name": "Burrow4", "depth": 1.0, "width": 1.0},
+  {"name": "Burrow5", "depth": 1.0, "width": 1.0},
+  {"name": "Burrow6", "depth": 1.0, "width": 1.0}
+]`)
+
+	// Renters without an account, and rentals without a renter, are not limited by default.
+	for _, name := range []string{"Burrow1", "Burrow2", "Burrow3", "Burrow4"} {
+		code, _, stderr := run("rent", "--dataFile", dataFile, name)
+		require.Equal(t, cli.ExitOK, code, stderr)
+	}
+	code, _, stderr := run("rent", "--dataFile", dataFile, "--renter", "bob", "Burrow5")
+	require.Equal(t, cli.ExitOK, code, stderr)
+
+	code, _, stderr = run("rent", "--dataFile", dataFile, "--renter", "bob", "--maxConcurrentBurrows", "1", "Burrow6")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, models.ErrRenterOverQuota.Message)
+	assert.False(t, readState(t, dataFile)[5].Occupied)
+}
+
+func TestOffline_RefusesTheStateFileOfARunningServer(t *testing.T) {
+	dataFile := writeFile(t, "state.json", state)
+
+	// A running server holds the lock of its state file.
+	lock, err := os.OpenFile(dataFile+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	require.NoError(t, err)
+	defer lock.Close()
+	require.NoError(t, syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB))
+
+	code, _, stderr := run("rent", "--dataFile", dataFile, "--renter", "bob", "The Deep Den")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "in use by a running server")
+	assert.False(t, readState(t, dataFile)[1].Occupied)
+
+	// Reading the state file is still allowed.
+	code, _, _ = run("list", "--dataFile", dataFile)
+	assert.Equal(t, cli.ExitOK, code)
+
+	require.NoError(t, syscall.Flock(int(lock.Fd()), syscall.LOCK_UN))
+	code, _, _ = run("rent", "--dataFile", dataFile, "--renter", "bob", "The Deep Den")
+	assert.Equal(t, cli.ExitOK, code)
+}
+
+func TestOffline_ValidateImportExport(t *testing.T) {
+	code, stdout, _ := run("validate", writeFile(t, "state.json", state))
+	require.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "2 burrow(s), valid")
+
+	// Every problem is reported.
+	invalid := writeFile(t, "invalid.json", `[
+  {"name": "A", "depth": 1, "width": 1},
+  {"name": "A", "depth": 1, "width": 1},
+  {"name": "B", "depth": -1, "width": 1},
+  {"name": "C", "dept": 1}
+]`)
+	code, _, stderr := run("validate", invalid)
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "row 2: ")
+	assert.Contains(t, stderr, "row 3: ")
+	assert.Contains(t, stderr, "row 4: ")
+	assert.Contains(t, stderr, "3 invalid burrow(s)")
+
+	dataFile := writeFile(t, "state.json", state)
+	csvFile := writeFile(t, "survey.csv", "name,depth,width\nNew Den,1.5,1.1\nThe Molehole,1,1\n")
+	code, stdout, stderr = run("import", "--dataFile", dataFile, csvFile)
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stdout, "Imported 1 burrow(s), 0 skipped, 1 failed")
+	assert.Contains(t, stderr, "row 3: ")
+	require.Len(t, readState(t, dataFile), 3)
+
+	// Existing burrows are updated in upsert mode, and nothing changes in a dry run.
+	code, stdout, _ = run("import", "--dataFile", dataFile, "--mode", "upsert", "--dry-run", csvFile)
+	require.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "Dry run: Imported 2 burrow(s)")
+	assert.Equal(t, 3.0, readState(t, dataFile)[0].Depth)
+
+	code, _, _ = run("import", "--dataFile", dataFile, "--mode", "upsert", csvFile)
+	require.Equal(t, cli.ExitOK, code)
+	assert.Equal(t, 1.0, readState(t, dataFile)[0].Depth)
+	assert.True(t, readState(t, dataFile)[0].Occupied)
+
+	code, stdout, _ = run("export", "--dataFile", dataFile, "--format", "ndjson")
+	require.Equal(t, cli.ExitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"name":"New Den","depth":1.5,"width":1.1,"occupied":false,"age":0}`, lines[2])
+}
+
+func TestOffline_Simulate(t *testing.T) {
+	dataFile := writeFile(t, "state.json", state)
+
+	code, stdout, stderr := run("simulate", "--dataFile", dataFile, "--minutes", "10", "--format", "json")
+	require.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stderr, "After 10 minute(s)")
+
+	var burrows []*models.Burrow
+	require.NoError(t, json.Unmarshal([]byte(stdout), &burrows))
+	assert.Equal(t, 60, burrows[0].Age)
+
+	// The state file is only changed with --save.
+	assert.Equal(t, 50, readState(t, dataFile)[0].Age)
+	code, _, _ = run("simulate", "--dataFile", dataFile, "--minutes", "10", "--save")
+	require.Equal(t, cli.ExitOK, code)
+	assert.Equal(t, 60, readState(t, dataFile)[0].Age)
+}
+
+func TestOnline(t *testing.T) {
+	var cfg config.ServiceConfig
+	require.NoError(t, configmgr.ReadConfiguration("../../property.yaml", &cfg))
+
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2, Age: 40}))
+	server, err := api.NewServer(services.NewGopherNetService(repo), health.NewChecker(), nil, events.NewBus(), clock.New(), config.NewStore(&cfg))
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(server.Handler)
+	defer httpServer.Close()
+
+	code, stdout, _ := run("list", "--server", httpServer.URL, "--api-key", "local-dev-key", "--format", "csv")
+	require.Equal(t, cli.ExitOK, code)
+	assert.Equal(t, "name,depth,width,occupied,age,rentedBy\nThe Deep Den,2.2,1.2,false,40,\n", stdout)
+
+	code, _, _ = run("rent", "--server", httpServer.URL, "--api-key", "local-dev-key", "The Deep Den")
+	require.Equal(t, cli.ExitOK, code)
+	assert.True(t, repo.GetAllBurrows()[0].Occupied)
+
+	// Problems are reported with their code.
+	code, _, stderr := run("rent", "--server", httpServer.URL, "--api-key", "local-dev-key", "The Deep Den")
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, "("+models.ErrBurrowUnavailable.Code+")")
+
+	code, _, _ = run("release", "--server", httpServer.URL, "--api-key", "local-dev-key", "The Deep Den")
+	require.Equal(t, cli.ExitOK, code)
+	assert.False(t, repo.GetAllBurrows()[0].Occupied)
+
+	code, stdout, _ = run("report", "--server", httpServer.URL, "--api-key", "local-dev-key")
+	require.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "GopherNet Burrow Report")
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := run("rent")
+	assert.Equal(t, cli.ExitUsage, code)
+	assert.Contains(t, stderr, "Usage: gophernet rent")
+
+	code, _, stderr = run("dig")
+	assert.Equal(t, cli.ExitUsage, code)
+	assert.Contains(t, stderr, `unknown command "dig"`)
+
+	code, stdout, _ := run("help")
+	assert.Equal(t, cli.ExitOK, code)
+	assert.Contains(t, stdout, "simulate")
+}
+
+func TestConfigCheck(t *testing.T) {
+	code, _, stderr := run("config", "check", "--port", "0", "--dataFile", filepath.Join(t.TempDir(), "missing", "state.json"))
+	assert.Equal(t, cli.ExitError, code)
+	assert.Contains(t, stderr, `server.port: "0" is not a port between 1 and 65535`)
+	assert.Contains(t, stderr, "runtime.stateFile: directory")
+
+	code, _, stderr = run("config")
+	assert.Equal(t, cli.ExitUsage, code)
+	assert.Contains(t, stderr, "Usage: gophernet config check")
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// Paths of the endpoints called by the online commands, as in the default property.yaml.
+const (
+	burrowsPath       = "/burrows"
+	rentBurrowPath    = "/burrows/rent"
+	releaseBurrowPath = "/burrows/release"
+	reportPath        = "/report"
+)
+
+// defaultRequestTimeout bounds the calls to the server.
+const defaultRequestTimeout = 30 * time.Second
+
+// serverFlags are the flags selecting and authenticating against a running server.
+type serverFlags struct {
+	server  string
+	apiKey  string
+	token   string
+	timeout time.Duration
+}
+
+func addServerFlags(e *env, fs *flag.FlagSet) *serverFlags {
+	f := &serverFlags{}
+	fs.StringVar(&f.server, "server", e.getenv(EnvServer), "Base URL of a running server, such as http://localhost:8080 (default $"+EnvServer+")")
+	fs.StringVar(&f.apiKey, "api-key", e.getenv(EnvAPIKey), "API key sent to the server (default $"+EnvAPIKey+")")
+	fs.StringVar(&f.token, "token", e.getenv(EnvToken), "Bearer token sent to the server (default $"+EnvToken+")")
+	fs.DurationVar(&f.timeout, "timeout", defaultRequestTimeout, "Timeout of the calls to the server")
+
+	return f
+}
+
+// online reports whether the command must call the server instead of working on the state file.
+func (f *serverFlags) online() bool {
+	return f.server != ""
+}
+
+func (f *serverFlags) client() *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(f.server, "/"),
+		apiKey:  f.apiKey,
+		token:   f.token,
+		http:    &http.Client{Timeout: f.timeout},
+	}
+}
+
+// apiClient calls the REST API of a running server.
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	token   string
+	http    *http.Client
+}
+
+func (c *apiClient) listBurrows(ctx context.Context) ([]*models.Burrow, error) {
+	var burrows []*models.Burrow
+	err := c.do(ctx, http.MethodGet, burrowsPath, nil, &burrows)
+
+	return burrows, err
+}
+
+func (c *apiClient) rentBurrow(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, rentBurrowPath, api.RentBurrowRequest{Name: name}, nil)
+}
+
+func (c *apiClient) releaseBurrow(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, releaseBurrowPath, api.ReleaseBurrowRequest{Name: name}, nil)
+}
+
+func (c *apiClient) report(ctx context.Context) (string, error) {
+	var report string
+	err := c.do(ctx, http.MethodGet, reportPath, nil, &report)
+
+	return report, err
+}
+
+// do sends the request with the JSON encoding of body, when not nil, and decodes the data of the
+// response into data, when not nil. Problem responses are returned as errors.
+func (c *apiClient) do(ctx context.Context, method, path string, body, data interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return errors.WithMessage(err, "invalid server URL")
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(api.APIKeyHeader, c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return errors.WithMessage(err, "failed to call the server")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var problem api.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil || problem.Code == "" {
+			return errors.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return problemError(problem)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return errors.WithMessage(err, "malformed response")
+	}
+	if data == nil {
+		return nil
+	}
+
+	return errors.WithMessage(json.Unmarshal(envelope.Data, data), "malformed response data")
+}
+
+// problemError formats a problem returned by the server.
+func problemError(problem api.Problem) error {
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	for _, fieldErr := range problem.Errors {
+		message += fmt.Sprintf("; %s %s", fieldErr.Field, fieldErr.Message)
+	}
+
+	return errors.Errorf("%s (%s)", message, problem.Code)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// runConfig runs the config subcommands. "config check" loads the configuration the serve command
+// would start with, from the same file, environment and flags, and reports every problem.
+func runConfig(e *env, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		fs := newFlagSet(e)
+		fs.Usage()
+		return errUsage
+	}
+
+	fs := newFlagSet(e)
+	addSettingFlags(fs)
+	if err := parse(fs, args[1:], 0); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfiguration(settingOverrides(fs))
+	if err := cfg.Validate(); err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			for _, problem := range validationErr.Problems {
+				fmt.Fprintln(e.stderr, problem)
+			}
+			return errors.Errorf("%d configuration problem(s)", len(validationErr.Problems))
+		}
+		return err
+	}
+
+	fmt.Fprintln(e.stdout, "Configuration is valid")
+	return nil
+}
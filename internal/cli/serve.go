@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/marcodd23/go-micro-core/pkg/shutdown"
+
+	"github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/async"
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/grpcapi"
+	"github.com/marcodd23/gopernet/internal/health"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// runServe starts the service and blocks until it is shut down by SIGINT or SIGTERM.
+// The flags of the settings override property.yaml and the environment.
+func runServe(e *env, args []string) error {
+	fs := newFlagSet(e)
+	addSettingFlags(fs)
+	printConfig := fs.Bool("print-config", false, "Print the effective settings and their source, then exit")
+	if err := parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	rootCtx := context.Background()
+
+	overrides := settingOverrides(fs)
+	cfg := config.LoadConfiguration(overrides)
+
+	if *printConfig {
+		return writeSettings(e.stdout, overrides)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	logmgr.SetupLogger(cfg)
+	models.SetLifecycle(lifecycle(cfg.Burrows))
+	store := config.NewStore(cfg)
+
+	// Lock the state file against the offline commands
+	unlockState, err := lockState(cfg.Runtime.StateFile)
+	if err != nil {
+		return err
+	}
+	defer unlockState()
+
+	// Initialize the repository
+	memoryRepo := repository.NewMemoryRepository(cfg.Runtime.StateFile, cfg.Runtime.ReportFile)
+
+	// Initialize the service
+	gopherNetService := services.NewGopherNetService(memoryRepo)
+
+	// Load the initial state using the repository
+	if err := gopherNetService.LoadInitialState(); err != nil {
+		logmgr.GetLogger().LogError(rootCtx, "Failed to load initial state", err)
+	}
+
+	// Initialize the clock, the event bus and the readiness checks
+	clk := clock.New()
+	eventBus := events.NewBus()
+	readiness := health.NewChecker()
+	gopherNetService.SetEventBus(eventBus)
+	gopherNetService.SetHoldTTL(holdTTL(cfg.Holds))
+	gopherNetService.SetReportFormat(reportFormat(cfg.Report))
+	memoryRepo.SetClock(clk)
+	memoryRepo.SetDefaultLimits(renterLimits(cfg.Renters))
+
+	// Initialize the pricing of the rentals
+	pricingEngine, err := pricing.NewEngine(cfg.Pricing, clk)
+	if err != nil {
+		return err
+	}
+	gopherNetService.SetPricing(pricingEngine)
+
+	// Initialize the state saver, retrying failed saves behind a circuit breaker
+	stateSaver := async.NewStateSaver(gopherNetService, cfg.Persistence, clk, eventBus)
+	readiness.Register("persistence", stateSaver.Ready)
+
+	// Initialize background task manager
+	backgroundTasks := async.NewBackgroundTaskManager(gopherNetService, stateSaver, clk)
+
+	// Set up cancelCtx and wait-group for managing goroutines
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	// Build the background jobs schedules from the configuration
+	burrowUpdaterJob := mustBuildJob(rootCtx, cfg, async.BurrowUpdaterJob)
+	periodicSaverJob := mustBuildJob(rootCtx, cfg, async.PeriodicSaverJob)
+	reportGeneratorJob := mustBuildJob(rootCtx, cfg, async.ReportGeneratorJob)
+	holdExpirerJob := mustBuildJob(rootCtx, cfg, async.HoldExpirerJob)
+	usageAccruerJob := mustBuildJob(rootCtx, cfg, async.UsageAccruerJob)
+
+	// Start background tasks
+	backgroundTasks.StartBurrowUpdater(cancelCtx, &wg, burrowUpdaterJob)
+	backgroundTasks.StartPeriodicSaver(cancelCtx, &wg, periodicSaverJob)
+	backgroundTasks.StartReportGenerator(cancelCtx, &wg, reportGeneratorJob)
+	backgroundTasks.StartHoldExpirer(cancelCtx, &wg, holdExpirerJob)
+	backgroundTasks.StartUsageAccruer(cancelCtx, &wg, usageAccruerJob)
+
+	// Create the server and define routes
+	server, err := api.NewServer(gopherNetService, readiness, backgroundTasks, eventBus, clk, store)
+	if err != nil {
+		logmgr.GetLogger().LogFatal(rootCtx, "Failed to create the server", err)
+	}
+	go func() {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logmgr.GetLogger().LogFatal(rootCtx, fmt.Sprintf("Could not listen on :%s \n", cfg.Server.Port), err)
+		}
+	}()
+
+	// Create the gRPC server, sharing the TLS configuration and the authentication of the HTTP server
+	var grpcServer *grpcapi.Server
+	if cfg.GRPC.Enabled {
+		var authenticator *auth.Authenticator
+		if cfg.Auth.Enabled {
+			if authenticator, err = auth.NewAuthenticator(cfg.Auth); err != nil {
+				logmgr.GetLogger().LogFatal(rootCtx, "Failed to set up the gRPC authentication", err)
+			}
+		}
+		grpcServer = grpcapi.NewServer(gopherNetService, eventBus, server.TLSConfig, authenticator, clk, store)
+
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.Port))
+		if err != nil {
+			logmgr.GetLogger().LogFatal(rootCtx, fmt.Sprintf("Could not listen on :%s \n", cfg.GRPC.Port), err)
+		}
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logmgr.GetLogger().LogFatal(rootCtx, "gRPC server failed", err)
+			}
+		}()
+	}
+
+	// Apply the changes of property.yaml that are safe while running, the others require a restart
+	store.OnChange(func(old, new *config.ServiceConfig) {
+		applyReload(rootCtx, memoryRepo, gopherNetService, pricingEngine, backgroundTasks, old, new)
+	})
+	store.Watch(func(restart []string) {
+		for _, setting := range restart {
+			logmgr.GetLogger().LogWarning(rootCtx, fmt.Sprintf("Configuration %s changed, requires restart", setting))
+		}
+		logmgr.GetLogger().LogInfo(rootCtx, "Configuration reloaded")
+	}, func(err error) {
+		logmgr.GetLogger().LogError(rootCtx, "Ignoring the changed configuration", err)
+	})
+
+	shutdown.WaitForShutdown(rootCtx, cfg.Runtime.ShutdownTimeout.Milliseconds(), func(timeoutCtx context.Context) {
+		logmgr.GetLogger().LogInfo(timeoutCtx, "Shutting down server...")
+		cancel() // Signal all goroutines to stop
+
+		// Stop the HTTP server, closing the WebSocket connections
+		if err := server.Shutdown(timeoutCtx); err != nil {
+			logmgr.GetLogger().LogError(timeoutCtx, "Error shutting down the HTTP server", err)
+		}
+
+		// Stop the gRPC server, letting the pending calls complete
+		if grpcServer != nil {
+			grpcServer.Shutdown(timeoutCtx)
+		}
+
+		// Wait for all goroutines to finish
+		wg.Wait()
+
+		// Save state one last time before shutdown
+		logmgr.GetLogger().LogInfo(timeoutCtx, "Saving the state before shutdown ... ")
+
+		err := stateSaver.SaveFinal(timeoutCtx)
+		if err != nil {
+			logmgr.GetLogger().LogError(timeoutCtx, "Error saving state during shutdown", err)
+		}
+
+		logmgr.GetLogger().LogInfo(timeoutCtx, "Server shut down gracefully")
+	})
+
+	return nil
+}
+
+// addSettingFlags adds the flags of the settings.
+func addSettingFlags(fs *flag.FlagSet) {
+	for _, setting := range config.Settings {
+		fs.String(setting.Flag, setting.Default, fmt.Sprintf("%s (%s)", setting.Usage, setting.Key))
+	}
+}
+
+// settingOverrides returns the values of the setting flags set on the command line, by setting key.
+func settingOverrides(fs *flag.FlagSet) map[string]string {
+	overrides := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		for _, setting := range config.Settings {
+			if setting.Flag == f.Name {
+				overrides[setting.Key] = f.Value.String()
+			}
+		}
+	})
+
+	return overrides
+}
+
+// writeSettings writes the effective settings, with the flag, the environment variable or the
+// file setting them.
+func writeSettings(w io.Writer, overrides map[string]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, value := range config.EffectiveSettings(overrides) {
+		source := value.Source
+		switch source {
+		case config.SourceOverride:
+			source = "flag -" + value.Flag
+		case config.SourceEnv:
+			source = "env " + value.Env()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", value.Key, value.Value, source)
+	}
+
+	return tw.Flush()
+}
+
+// jobs are the background jobs, scheduled by default as their setting.
+var jobs = []string{
+	async.BurrowUpdaterJob,
+	async.PeriodicSaverJob,
+	async.ReportGeneratorJob,
+	async.HoldExpirerJob,
+	async.UsageAccruerJob,
+}
+
+// applyReload applies the live settings that changed from old to new: the log level, the burrows
+// lifecycle, the holds duration, the limits of the renters, the pricing rules and the job schedules.
+func applyReload(ctx context.Context, repo *repository.MemoryRepository, service *services.DefaultBurrowService, engine *pricing.Engine, tasks *async.BackgroundTaskManager, old, new *config.ServiceConfig) {
+	if old.GetLoggingConfig() == nil || new.GetLoggingConfig() == nil || old.Logging.Level != new.Logging.Level {
+		logmgr.SetupLogger(new)
+	}
+
+	if old.Burrows != new.Burrows {
+		models.SetLifecycle(lifecycle(new.Burrows))
+	}
+
+	if old.Holds != new.Holds {
+		service.SetHoldTTL(holdTTL(new.Holds))
+	}
+
+	if old.Renters != new.Renters {
+		repo.SetDefaultLimits(renterLimits(new.Renters))
+	}
+
+	if old.Report != new.Report {
+		service.SetReportFormat(reportFormat(new.Report))
+	}
+
+	if !reflect.DeepEqual(old.Pricing, new.Pricing) {
+		if err := engine.SetRules(new.Pricing); err != nil {
+			logmgr.GetLogger().LogError(ctx, "Failed to apply the pricing rules", err)
+		}
+	}
+
+	for _, name := range jobs {
+		if old.Jobs[name] == new.Jobs[name] {
+			continue
+		}
+
+		job, err := async.NewJob(name, new.Jobs[name], config.DefaultJobSchedule(name))
+		if err == nil {
+			err = tasks.UpdateJob(job)
+		}
+		if err != nil {
+			logmgr.GetLogger().LogError(ctx, "Failed to reschedule the job", err)
+		}
+	}
+}
+
+// lifecycle returns the burrows lifecycle of the configuration.
+func lifecycle(cfg config.Burrows) models.Lifecycle {
+	l := models.DefaultLifecycle
+	if cfg.GrowthRate > 0 {
+		l.GrowthRate = cfg.GrowthRate
+	}
+	if cfg.MinGrowth > 0 {
+		l.MinGrowth = cfg.MinGrowth
+	}
+	if cfg.CollapseAge > 0 {
+		l.CollapseAge = int(cfg.CollapseAge / time.Minute)
+	}
+
+	return l
+}
+
+// holdTTL returns the duration of the holds of the configuration.
+func holdTTL(cfg config.Holds) time.Duration {
+	if cfg.TTL > 0 {
+		return cfg.TTL
+	}
+
+	return models.DefaultHoldTTL
+}
+
+// renterLimits returns the limits of the renters without an account of the configuration.
+func renterLimits(cfg config.Renters) models.RenterLimits {
+	return models.RenterLimits{MaxConcurrentBurrows: cfg.MaxConcurrentBurrows}
+}
+
+// reportFormat returns the format of the saved reports of the configuration.
+func reportFormat(cfg config.Report) string {
+	if cfg.Format != "" {
+		return cfg.Format
+	}
+
+	return models.ReportFormatText
+}
+
+// mustBuildJob builds the named job from the configuration, exiting if its schedule is invalid.
+func mustBuildJob(ctx context.Context, cfg *config.ServiceConfig, name string) async.Job {
+	job, err := async.NewJob(name, cfg.Jobs[name], config.DefaultJobSchedule(name))
+	if err != nil {
+		logmgr.GetLogger().LogFatal(ctx, "Invalid job configuration", err)
+	}
+
+	return job
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package cli
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// lockState takes the advisory lock of the state file, held by a running server for its lifetime,
+// so that the offline commands do not rewrite the state file of a running server. The lock is on
+// a separate file next to the state file, which may not exist yet.
+func lockState(dataFile string) (unlock func(), err error) {
+	f, err := os.OpenFile(dataFile+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the lock of the state file")
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errors.Errorf("the state file %s is in use by a running server, use --server to go through it", dataFile)
+		}
+		return nil, errors.Wrap(err, "failed to lock the state file")
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cli
+
+// lockState does not lock the state file on the platforms without flock.
+func lockState(dataFile string) (unlock func(), err error) {
+	return func() {}, nil
+}
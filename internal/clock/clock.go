@@ -0,0 +1,146 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passage of time so that time dependent components
+// (schedulers, limiters, expirations) can be driven by a fake clock in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of time.Timer used by the application.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// New returns a Clock backed by the system time.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Fake is a manually driven Clock. Time only moves when Advance or Set is called.
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFake creates a Fake clock starting at the given time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTimer{clock: f, deadline: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.fire(f.now)
+		return t
+	}
+
+	f.timers = append(f.timers, t)
+
+	return t
+}
+
+// Advance moves the clock forward and fires every timer whose deadline has passed.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to the given time and fires every timer whose deadline has passed.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if !t.deadline.After(now) {
+			t.fire(now)
+			continue
+		}
+		pending = append(pending, t)
+	}
+	f.timers = pending
+}
+
+// PendingTimers returns the number of timers waiting to fire.
+// Useful in tests to wait until a goroutine has armed its timer.
+func (f *Fake) PendingTimers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.timers)
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	deadline time.Time
+	ch       chan time.Time
+	fired    bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, pending := range t.clock.timers {
+		if pending == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// fire must be called with the clock lock held.
+func (t *fakeTimer) fire(now time.Time) {
+	if t.fired {
+		return
+	}
+	t.fired = true
+	t.ch <- now
+}
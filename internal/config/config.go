@@ -2,31 +2,252 @@ package config
 
 import (
 	"github.com/marcodd23/go-micro-core/pkg/configmgr"
+	"github.com/spf13/viper"
 	"log"
+	"time"
 )
 
 // ServiceConfig - Application Level config.
 // embed configmgr.BaseConfig
 type ServiceConfig struct {
 	configmgr.BaseConfig `mapstructure:",squash"`
-	Rest                 Rest `yaml:"rest"`
+	// HTTP holds the HTTP server settings not covered by configmgr.ServerConfig.
+	// It is read from the same "server" section.
+	HTTP        HTTPServer     `mapstructure:"server" yaml:"server"`
+	Runtime     Runtime        `yaml:"runtime"`
+	Rest        Rest           `yaml:"rest"`
+	GRPC        GRPC           `yaml:"grpc"`
+	GraphQL     GraphQL        `yaml:"graphql"`
+	WebSocket   WebSocket      `yaml:"websocket"`
+	Jobs        map[string]Job `yaml:"jobs"`
+	Burrows     Burrows        `yaml:"burrows"`
+	Report      Report         `yaml:"report"`
+	Holds       Holds          `yaml:"holds"`
+	Renters     Renters        `yaml:"renters"`
+	Pricing     Pricing        `yaml:"pricing"`
+	Persistence Persistence    `yaml:"persistence"`
+	Auth        Auth           `yaml:"auth"`
+}
+
+// Runtime configuration of the files of the service and of its shutdown.
+// ShutdownTimeout bounds the time given to the cleanup, the final save included.
+type Runtime struct {
+	StateFile       string        `yaml:"stateFile"`
+	ReportFile      string        `yaml:"reportFile"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+}
+
+// HTTPServer configuration of the HTTP server timeouts. Zero values keep the defaults of the server.
+type HTTPServer struct {
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+	ReadTimeout       time.Duration `yaml:"readTimeout"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout"`
+	TLS               TLS           `yaml:"tls"`
+}
+
+// TLS configuration of the HTTP server.
+// MinVersion is "1.0", "1.1", "1.2" (default) or "1.3". When ClientCAFile is set, clients must
+// authenticate with a certificate signed by one of its CAs (mutual TLS).
+// The files are checked for changes at most once per ReloadInterval (10s by default).
+type TLS struct {
+	Enabled        bool          `yaml:"enabled"`
+	CertFile       string        `yaml:"certFile"`
+	KeyFile        string        `yaml:"keyFile"`
+	MinVersion     string        `yaml:"minVersion"`
+	ClientCAFile   string        `yaml:"clientCAFile"`
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
+}
+
+// GRPC configuration of the gRPC server, served next to the HTTP server.
+// It uses the TLS configuration of the HTTP server when server.tls is enabled.
+type GRPC struct {
+	Enabled bool   `yaml:"enabled"`
+	Port    string `yaml:"port"`
+}
+
+// GraphQL configuration of the limits of the GraphQL queries. The depth of a query is its deepest
+// level of nested fields, and its complexity the number of fields it selects.
+type GraphQL struct {
+	MaxDepth      int `yaml:"maxDepth"`
+	MaxComplexity int `yaml:"maxComplexity"`
+}
+
+// WebSocket configuration of the live feed connections. Clients are pinged every PingInterval and
+// disconnected when no pong arrives within PongTimeout, or when SendBuffer messages are waiting
+// to be written to them. When AllowedOrigins is empty, only same origin browser clients are accepted.
+type WebSocket struct {
+	SendBuffer     int           `yaml:"sendBuffer"`
+	PingInterval   time.Duration `yaml:"pingInterval"`
+	PongTimeout    time.Duration `yaml:"pongTimeout"`
+	WriteTimeout   time.Duration `yaml:"writeTimeout"`
+	AllowedOrigins []string      `yaml:"allowedOrigins"`
 }
 
 // Rest configuration
+// MaxBodyBytes limits the size of the request bodies, unless an endpoint sets its own limit.
+// When RateLimit.Requests is positive, every client IP is rate limited across the endpoints.
 type Rest struct {
-	Endpoints map[string]Endpoint `yaml:"endpoints"`
+	Endpoints    map[string]Endpoint `yaml:"endpoints"`
+	MaxBodyBytes int64               `yaml:"maxBodyBytes"`
+	RateLimit    RateLimit           `yaml:"rateLimit"`
 }
 
 // Endpoint configuration
+// Public endpoints are served without authentication. The others only authorize the callers
+// having at least one of the Roles, which must not be empty.
+// A positive Timeout bounds the time the handler has to complete.
+// When RateLimit.Requests is positive, every client is rate limited.
 type Endpoint struct {
-	Method string `yaml:"method"`
-	Path   string `yaml:"path"`
+	Method       string        `yaml:"method"`
+	Path         string        `yaml:"path"`
+	Public       bool          `yaml:"public"`
+	Roles        []string      `yaml:"roles"`
+	MaxBodyBytes int64         `yaml:"maxBodyBytes"`
+	Timeout      time.Duration `yaml:"timeout"`
+	RateLimit    RateLimit     `yaml:"rateLimit"`
+}
+
+// RateLimit configuration of a token bucket: every client can make Requests requests per Period
+// (1s by default), with bursts of up to Burst requests (Requests by default).
+type RateLimit struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Job configuration of a background job.
+// Schedule accepts a duration ("30s"), "@every <duration>", a descriptor like "@daily"
+// or a 5 fields cron expression ("0 6 * * *").
+type Job struct {
+	Schedule      string        `yaml:"schedule"`
+	Jitter        time.Duration `yaml:"jitter"`
+	SkipIfRunning bool          `yaml:"skipIfRunning"`
+}
+
+// Burrows configuration of the lifecycle of the burrows. Every minute, occupied burrows deepen by
+// GrowthRate of their depth, or by MinGrowth when they have no depth, and burrows collapse once
+// they reach CollapseAge. Zero values keep the defaults.
+type Burrows struct {
+	GrowthRate  float64       `yaml:"growthRate"`
+	MinGrowth   float64       `yaml:"minGrowth"`
+	CollapseAge time.Duration `yaml:"collapseAge"`
+}
+
+// Report configuration of the report saved by the report-generator job in runtime.reportFile.
+// Format is "text" (default) or "json".
+type Report struct {
+	Format string `yaml:"format"`
+}
+
+// Holds configuration of the reservations of the burrows during checkout. A hold expires after
+// TTL (15m when zero) unless it is confirmed into a rental.
+type Holds struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// Renters configuration of the renters without an account, who rent and hold at most
+// MaxConcurrentBurrows burrows at once (unlimited when zero).
+type Renters struct {
+	MaxConcurrentBurrows int `yaml:"maxConcurrentBurrows"`
+}
+
+// Pricing configuration of the price of the rentals, in minor units of Currency per day.
+// A burrow costs BasePrice, plus PerCubicMeter of its volume and PerMeterDepth of its depth,
+// discounted by AgeDiscount and scaled by its remaining lifetime, down to MinLifetimeFactor when
+// it is about to collapse. The matching season and occupancy surges multiply the price.
+type Pricing struct {
+	Currency          string           `yaml:"currency"`
+	BasePrice         int64            `yaml:"basePrice"`
+	PerCubicMeter     int64            `yaml:"perCubicMeter"`
+	PerMeterDepth     int64            `yaml:"perMeterDepth"`
+	AgeDiscount       AgeDiscount      `yaml:"ageDiscount"`
+	MinLifetimeFactor float64          `yaml:"minLifetimeFactor"`
+	Seasons           []SeasonSurge    `yaml:"seasons"`
+	Occupancy         []OccupancySurge `yaml:"occupancy"`
+}
+
+// AgeDiscount configuration of the discount of the old burrows: PerDay of the price for every day
+// of age, up to Max.
+type AgeDiscount struct {
+	PerDay float64 `yaml:"perDay"`
+	Max    float64 `yaml:"max"`
+}
+
+// SeasonSurge configuration of a surge multiplier applying from one day of the year to another,
+// both included and written "MM-DD". A season may span the new year.
+type SeasonSurge struct {
+	Name       string  `yaml:"name"`
+	From       string  `yaml:"from"`
+	To         string  `yaml:"to"`
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// OccupancySurge configuration of a surge multiplier applying while the share of the standing
+// burrows that are rented is above Above. Only the highest matching surge applies.
+type OccupancySurge struct {
+	Above      float64 `yaml:"above"`
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// Persistence configuration of the state saving.
+type Persistence struct {
+	Retry          Retry          `yaml:"retry"`
+	CircuitBreaker CircuitBreaker `yaml:"circuitBreaker"`
+}
+
+// Retry configuration of an exponential backoff.
+type Retry struct {
+	InitialInterval time.Duration `yaml:"initialInterval"`
+	MaxInterval     time.Duration `yaml:"maxInterval"`
+	Multiplier      float64       `yaml:"multiplier"`
+	Jitter          float64       `yaml:"jitter"`
+	MaxAttempts     int           `yaml:"maxAttempts"`
+}
+
+// CircuitBreaker configuration.
+type CircuitBreaker struct {
+	FailureThreshold int           `yaml:"failureThreshold"`
+	OpenTimeout      time.Duration `yaml:"openTimeout"`
+}
+
+// Auth configuration.
+// When enabled, every non public endpoint requires an API key (X-API-Key header)
+// or a JWT bearer token signed with HS256 (HMACSecret) or RS256 (RSAPublicKeyFile).
+type Auth struct {
+	Enabled bool     `yaml:"enabled"`
+	APIKeys []APIKey `yaml:"apiKeys"`
+	JWT     JWT      `yaml:"jwt"`
+}
+
+// APIKey configuration of a static API key.
+type APIKey struct {
+	Key     string   `yaml:"key"`
+	Subject string   `yaml:"subject"`
+	Roles   []string `yaml:"roles"`
+}
+
+// JWT configuration of the bearer tokens validation.
+type JWT struct {
+	Issuer           string `yaml:"issuer"`
+	Audience         string `yaml:"audience"`
+	HMACSecret       string `yaml:"hmacSecret"`
+	RSAPublicKeyFile string `yaml:"rsaPublicKeyFile"`
 }
 
 // LoadConfiguration - It load the property-<ENV>.yaml into the ServiceConfig struct.
-func LoadConfiguration() *ServiceConfig {
+// The Settings take, by increasing precedence, their default value, the value of the file,
+// of their environment variable, and of overrides, keyed by setting key.
+func LoadConfiguration(overrides map[string]string) *ServiceConfig {
 	var cfg ServiceConfig
 
+	for _, setting := range Settings {
+		viper.SetDefault(setting.Key, setting.Default)
+	}
+	for key, value := range overrides {
+		viper.Set(key, value)
+	}
+
 	err := configmgr.LoadConfigForEnv(&cfg)
 	if err != nil {
 		log.Panicf("error loading property files: %+v", err)
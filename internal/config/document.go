@@ -0,0 +1,124 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RedactedValue replaces the secrets in Redacted configurations.
+const RedactedValue = "[REDACTED]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Redacted returns a copy of the configuration with the API keys and the JWT secret replaced by RedactedValue.
+func (cfg *ServiceConfig) Redacted() *ServiceConfig {
+	redacted := *cfg
+
+	redacted.Auth.APIKeys = make([]APIKey, len(cfg.Auth.APIKeys))
+	for i, key := range cfg.Auth.APIKeys {
+		if key.Key != "" {
+			key.Key = RedactedValue
+		}
+		redacted.Auth.APIKeys[i] = key
+	}
+	if redacted.Auth.JWT.HMACSecret != "" {
+		redacted.Auth.JWT.HMACSecret = RedactedValue
+	}
+
+	return &redacted
+}
+
+// Document returns the configuration as nested maps keyed like property.yaml, durations written
+// as strings, ready to be encoded.
+func Document(cfg *ServiceConfig) map[string]interface{} {
+	return toDocument(reflect.ValueOf(cfg)).(map[string]interface{})
+}
+
+func toDocument(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return v.Interface().(time.Duration).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toDocument(v.Elem())
+	case reflect.Struct:
+		document := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, squash := documentKey(field)
+			value := toDocument(v.Field(i))
+			if squash {
+				for key, sub := range value.(map[string]interface{}) {
+					merge(document, key, sub)
+				}
+				continue
+			}
+			merge(document, name, value)
+		}
+		return document
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		document := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			document[iter.Key().String()] = toDocument(iter.Value())
+		}
+		return document
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = toDocument(v.Index(i))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// documentKey returns the key of a field, from its yaml or mapstructure tag, or its name in
+// lower camel case, and whether its fields are squashed into its parent.
+func documentKey(field reflect.StructField) (string, bool) {
+	for _, tag := range []string{"yaml", "mapstructure"} {
+		name, options, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if options == "squash" || options == "inline" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+
+	runes := []rune(field.Name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes), false
+}
+
+// merge sets document[key], merging the maps of the fields sharing a section, like
+// server for configmgr.ServerConfig and HTTPServer.
+func merge(document map[string]interface{}, key string, value interface{}) {
+	existing, ok := document[key].(map[string]interface{})
+	sub, isMap := value.(map[string]interface{})
+	if !ok || !isMap {
+		document[key] = value
+		return
+	}
+
+	for subKey, subValue := range sub {
+		existing[subKey] = subValue
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Defaults of the runtime settings.
+const (
+	DefaultPort            = "8080"
+	DefaultStateFile       = "data/state.json"
+	DefaultReportFile      = "data/report.txt"
+	DefaultShutdownTimeout = "500ms"
+)
+
+// Sources of the setting values.
+const (
+	SourceDefault  = "default"
+	SourceFile     = "file"
+	SourceEnv      = "env"
+	SourceOverride = "flag"
+)
+
+// Setting is a runtime setting, that can be set in property.yaml, by its environment variable and
+// by its command line flag.
+type Setting struct {
+	// Key is the path of the setting in property.yaml.
+	Key string
+	// Flag is the name of the command line flag of the serve command.
+	Flag    string
+	Default string
+	Usage   string
+}
+
+// Settings are the runtime settings.
+var Settings = []Setting{
+	{Key: "server.port", Flag: "port", Default: DefaultPort, Usage: "Port of the HTTP server"},
+	{Key: "runtime.stateFile", Flag: "dataFile", Default: DefaultStateFile, Usage: "Path to the state file"},
+	{Key: "runtime.reportFile", Flag: "reportFile", Default: DefaultReportFile, Usage: "Path to the report file"},
+	{Key: "runtime.shutdownTimeout", Flag: "shutdownTimeout", Default: DefaultShutdownTimeout, Usage: "Time given to the cleanup at shutdown"},
+	{Key: "jobs.burrow-updater.schedule", Flag: "burrowUpdaterSchedule", Default: "1m", Usage: "Schedule of the burrow updates"},
+	{Key: "jobs.periodic-saver.schedule", Flag: "periodicSaverSchedule", Default: "5m", Usage: "Schedule of the state saves"},
+	{Key: "jobs.report-generator.schedule", Flag: "reportGeneratorSchedule", Default: "5m", Usage: "Schedule of the report generation"},
+	{Key: "jobs.hold-expirer.schedule", Flag: "holdExpirerSchedule", Default: "30s", Usage: "Schedule of the release of the expired holds"},
+	{Key: "jobs.usage-accruer.schedule", Flag: "usageAccruerSchedule", Default: "1m", Usage: "Schedule of the usage charges"},
+}
+
+// DefaultJobSchedule returns the default schedule of the named job, or "" when it has none.
+func DefaultJobSchedule(name string) string {
+	key := "jobs." + name + ".schedule"
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting.Default
+		}
+	}
+
+	return ""
+}
+
+// Env returns the environment variable of the setting: its key in upper case, with dots and
+// dashes replaced by underscores.
+func (s Setting) Env() string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(s.Key))
+}
+
+// SettingValue is the effective value of a setting and where it comes from.
+type SettingValue struct {
+	Setting
+	Value  string
+	Source string
+}
+
+// EffectiveSettings returns the values of the settings once LoadConfiguration has run with overrides.
+func EffectiveSettings(overrides map[string]string) []SettingValue {
+	values := make([]SettingValue, 0, len(Settings))
+	for _, setting := range Settings {
+		source := SourceDefault
+		if _, ok := overrides[setting.Key]; ok {
+			source = SourceOverride
+		} else if _, ok := os.LookupEnv(setting.Env()); ok {
+			source = SourceEnv
+		} else if viper.InConfig(setting.Key) {
+			source = SourceFile
+		}
+
+		values = append(values, SettingValue{Setting: setting, Value: viper.GetString(setting.Key), Source: source})
+	}
+
+	return values
+}
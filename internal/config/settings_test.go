@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+func TestLoadConfiguration_Precedence(t *testing.T) {
+	// testdata/property.yaml sets some settings, overridden by the environment and the overrides.
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir("testdata"))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	t.Setenv("RUNTIME_REPORTFILE", "/tmp/env-report.txt")
+	t.Setenv("SERVER_PORT", "7070")
+	overrides := map[string]string{
+		"server.port":                  "9999",
+		"jobs.periodic-saver.schedule": "1h",
+		"jobs.usage-accruer.schedule":  "2m",
+	}
+
+	cfg := config.LoadConfiguration(overrides)
+
+	assert.Equal(t, "9999", cfg.Server.Port)
+	assert.Equal(t, "/tmp/file-state.json", cfg.Runtime.StateFile)
+	assert.Equal(t, "/tmp/env-report.txt", cfg.Runtime.ReportFile)
+	assert.Equal(t, 500*time.Millisecond, cfg.Runtime.ShutdownTimeout)
+	assert.Equal(t, "1m", cfg.Jobs["burrow-updater"].Schedule)
+	assert.Equal(t, "1h", cfg.Jobs["periodic-saver"].Schedule)
+	assert.Equal(t, "45s", cfg.Jobs["hold-expirer"].Schedule)
+	assert.Equal(t, "2m", cfg.Jobs["usage-accruer"].Schedule)
+
+	sources := make(map[string]string)
+	for _, value := range config.EffectiveSettings(overrides) {
+		sources[value.Key] = value.Source
+	}
+	assert.Equal(t, config.SourceOverride, sources["server.port"])
+	assert.Equal(t, config.SourceEnv, sources["runtime.reportFile"])
+	assert.Equal(t, config.SourceFile, sources["runtime.stateFile"])
+	assert.Equal(t, config.SourceFile, sources["jobs.hold-expirer.schedule"])
+	assert.Equal(t, config.SourceOverride, sources["jobs.usage-accruer.schedule"])
+	assert.Equal(t, config.SourceDefault, sources["runtime.shutdownTimeout"])
+}
+
+func TestDefaultJobSchedule(t *testing.T) {
+	assert.Equal(t, "30s", config.DefaultJobSchedule("hold-expirer"))
+	assert.Equal(t, "5m", config.DefaultJobSchedule("periodic-saver"))
+	assert.Empty(t, config.DefaultJobSchedule("unknown"))
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// Store holds the active configuration. Reloads apply the settings that can change while the
+// service runs and keep the others, which require a restart.
+type Store struct {
+	mu        sync.RWMutex
+	current   *ServiceConfig
+	listeners []func(old, new *ServiceConfig)
+}
+
+// NewStore creates a Store holding cfg.
+func NewStore(cfg *ServiceConfig) *Store {
+	return &Store{current: cfg}
+}
+
+// Current returns the active configuration. It must not be modified.
+func (s *Store) Current() *ServiceConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.current
+}
+
+// OnChange registers a listener called after every reload changing the active configuration.
+func (s *Store) OnChange(listener func(old, new *ServiceConfig)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// Reload applies the live settings of cfg and returns the settings that changed but require a
+// restart. The listeners are called when a live setting changed.
+func (s *Store) Reload(cfg *ServiceConfig) []string {
+	s.mu.Lock()
+	old := s.current
+	active, restart := applyLive(old, cfg)
+	changed := !reflect.DeepEqual(old, active)
+	if changed {
+		s.current = active
+	}
+	listeners := append([]func(old, new *ServiceConfig){}, s.listeners...)
+	s.mu.Unlock()
+
+	if changed {
+		for _, listener := range listeners {
+			listener(old, active)
+		}
+	}
+
+	return restart
+}
+
+// Watch reloads the configuration whenever property.yaml changes, once LoadConfiguration has read it.
+// onReload receives the settings requiring a restart, and onError the invalid configurations,
+// which are ignored.
+func (s *Store) Watch(onReload func(restart []string), onError func(err error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		var cfg ServiceConfig
+		if err := viper.Unmarshal(&cfg); err != nil {
+			onError(errors.WithMessage(err, "unable to decode the configuration"))
+			return
+		}
+		if err := cfg.Validate(); err != nil {
+			onError(err)
+			return
+		}
+
+		onReload(s.Reload(&cfg))
+	})
+	viper.WatchConfig()
+}
+
+// applyLive returns a copy of old with the live settings of cfg, and the other settings of cfg
+// that differ from old. The live settings are the log level, the jobs, the rate limits,
+// the burrows lifecycle, the report format, the holds, the renters and the pricing. A shorter collapse age
+// is not live, as it would collapse the rented burrows at once.
+func applyLive(old, cfg *ServiceConfig) (*ServiceConfig, []string) {
+	active := *old
+	if cfg.Logging != nil {
+		logging := *cfg.Logging
+		active.Logging = &logging
+	}
+	active.Jobs = cfg.Jobs
+	active.Burrows = cfg.Burrows
+	if collapseAge(cfg.Burrows) < collapseAge(old.Burrows) {
+		active.Burrows.CollapseAge = old.Burrows.CollapseAge
+	}
+	active.Report = cfg.Report
+	active.Holds = cfg.Holds
+	active.Renters = cfg.Renters
+	active.Pricing = cfg.Pricing
+	active.Rest.RateLimit = cfg.Rest.RateLimit
+
+	active.Rest.Endpoints = make(map[string]Endpoint, len(old.Rest.Endpoints))
+	for key, endpoint := range old.Rest.Endpoints {
+		if updated, ok := cfg.Rest.Endpoints[key]; ok {
+			endpoint.RateLimit = updated.RateLimit
+		}
+		active.Rest.Endpoints[key] = endpoint
+	}
+
+	// Everything else of cfg must match the active configuration.
+	rest := *cfg
+	rest.Logging = active.Logging
+	rest.Jobs = active.Jobs
+	rest.Burrows = active.Burrows
+	rest.Burrows.CollapseAge = cfg.Burrows.CollapseAge
+	rest.Report = active.Report
+	rest.Holds = active.Holds
+	rest.Renters = active.Renters
+	rest.Pricing = active.Pricing
+	rest.Rest.RateLimit = active.Rest.RateLimit
+	rest.Rest.Endpoints = make(map[string]Endpoint, len(cfg.Rest.Endpoints))
+	for key, endpoint := range cfg.Rest.Endpoints {
+		if current, ok := active.Rest.Endpoints[key]; ok {
+			endpoint.RateLimit = current.RateLimit
+		}
+		rest.Rest.Endpoints[key] = endpoint
+	}
+
+	return &active, diff("", Document(&active), Document(&rest))
+}
+
+// collapseAge returns the age at which the burrows collapse, the default one when not set.
+func collapseAge(burrows Burrows) time.Duration {
+	if burrows.CollapseAge > 0 {
+		return burrows.CollapseAge
+	}
+
+	return time.Duration(models.DefaultLifecycle.CollapseAge) * time.Minute
+}
+
+// diff returns the paths of the settings that differ between two documents.
+func diff(prefix string, a, b map[string]interface{}) []string {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	var paths []string
+	for key := range keys {
+		path := prefix + key
+		subA, okA := a[key].(map[string]interface{})
+		subB, okB := b[key].(map[string]interface{})
+		switch {
+		case okA && okB:
+			paths = append(paths, diff(path+".", subA, subB)...)
+		case !reflect.DeepEqual(a[key], b[key]):
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
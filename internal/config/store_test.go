@@ -0,0 +1,94 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+func TestStore_Reload(t *testing.T) {
+	cfg := loadConfig(t)
+	store := config.NewStore(cfg)
+
+	var changes int
+	store.OnChange(func(old, new *config.ServiceConfig) {
+		changes++
+		assert.Same(t, cfg, old)
+	})
+
+	next := loadConfig(t)
+	next.Runtime = cfg.Runtime
+	next.Logging.Level = "warn"
+	next.Jobs["burrow-updater"] = config.Job{Schedule: "30s"}
+	next.Burrows.CollapseAge = 700 * time.Hour
+	next.Report.Format = "json"
+	next.Renters.MaxConcurrentBurrows = 5
+	endpoint := next.Rest.Endpoints["get-burrows"]
+	endpoint.RateLimit = config.RateLimit{Requests: 1}
+	next.Rest.Endpoints["get-burrows"] = endpoint
+	// Not applied live.
+	next.Server.Port = "1" + cfg.Server.Port
+	endpoint = next.Rest.Endpoints["get-report"]
+	endpoint.Path = "/reports"
+	next.Rest.Endpoints["get-report"] = endpoint
+
+	restart := store.Reload(next)
+	assert.Equal(t, []string{"rest.endpoints.get-report.path", "server.port"}, restart)
+	assert.Equal(t, 1, changes)
+
+	active := store.Current()
+	assert.Equal(t, "warn", active.Logging.Level)
+	assert.Equal(t, "30s", active.Jobs["burrow-updater"].Schedule)
+	assert.Equal(t, 700*time.Hour, active.Burrows.CollapseAge)
+	assert.Equal(t, "json", active.Report.Format)
+	assert.Equal(t, 5, active.Renters.MaxConcurrentBurrows)
+	assert.Equal(t, 1, active.Rest.Endpoints["get-burrows"].RateLimit.Requests)
+	assert.Equal(t, cfg.Server.Port, active.Server.Port)
+	assert.Equal(t, "/report", active.Rest.Endpoints["get-report"].Path)
+	// The previous configuration is left untouched.
+	assert.Equal(t, 0, cfg.Rest.Endpoints["get-burrows"].RateLimit.Requests)
+
+	// Reloading the same settings changes nothing.
+	store.Reload(next)
+	assert.Equal(t, 1, changes)
+}
+
+func TestStore_Reload_ShorterCollapseAgeRequiresRestart(t *testing.T) {
+	cfg := loadConfig(t)
+	store := config.NewStore(cfg)
+
+	next := loadConfig(t)
+	next.Runtime = cfg.Runtime
+	next.Burrows.GrowthRate = 2 * cfg.Burrows.GrowthRate
+	next.Burrows.CollapseAge = time.Hour
+
+	assert.Equal(t, []string{"burrows.collapseAge"}, store.Reload(next))
+	active := store.Current()
+	assert.Equal(t, cfg.Burrows.CollapseAge, active.Burrows.CollapseAge)
+	assert.Equal(t, next.Burrows.GrowthRate, active.Burrows.GrowthRate)
+}
+
+func TestDocument_Redacted(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Auth.JWT.HMACSecret = "secret"
+
+	document := config.Document(cfg.Redacted())
+
+	server := document["server"].(map[string]interface{})
+	assert.Equal(t, cfg.Server.Port, server["port"])
+	assert.Equal(t, "30s", server["readTimeout"])
+
+	auth := document["auth"].(map[string]interface{})
+	apiKeys := auth["apiKeys"].([]interface{})
+	require.Len(t, apiKeys, 2)
+	assert.Equal(t, config.RedactedValue, apiKeys[0].(map[string]interface{})["key"])
+	assert.Equal(t, "local-dev", apiKeys[0].(map[string]interface{})["subject"])
+	assert.Equal(t, config.RedactedValue, auth["jwt"].(map[string]interface{})["hmacSecret"])
+
+	assert.Equal(t, "local-dev-key", cfg.Auth.APIKeys[0].Key)
+	assert.Equal(t, "secret", cfg.Auth.JWT.HMACSecret)
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// endpointKeys are the keys of the endpoints served by the API, declared by RegisterEndpoints:
+// rest.endpoints must configure every one of them, and only them.
+var endpointKeys []string
+
+// RegisterEndpoints declares the keys of the endpoints served by the API, which Validate checks
+// rest.endpoints against. The api package, that config cannot import, registers its routes when it
+// is loaded; without registered keys, the endpoints are not checked against the routes.
+func RegisterEndpoints(keys ...string) {
+	endpointKeys = append(endpointKeys, keys...)
+}
+
+// EndpointKeys returns the keys declared by RegisterEndpoints.
+func EndpointKeys() []string {
+	return append([]string(nil), endpointKeys...)
+}
+
+// HTTPMethods are the HTTP methods the endpoints can be bound to.
+var HTTPMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// DevelopmentEnvironments are the environments where authentication can be disabled.
+var DevelopmentEnvironments = []string{"local", "dev", "development"}
+
+// IsDevelopment reports whether the service runs in one of the DevelopmentEnvironments.
+func (cfg *ServiceConfig) IsDevelopment() bool {
+	for _, environment := range DevelopmentEnvironments {
+		if strings.EqualFold(cfg.Environment, environment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MonthDayLayout is the layout of the days of the pricing seasons.
+const MonthDayLayout = "01-02"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var tlsVersions = map[string]bool{"": true, "1.0": true, "1.1": true, "1.2": true, "1.3": true}
+
+// Problem is an invalid setting, identified by its path in property.yaml.
+type Problem struct {
+	Field   string
+	Message string
+}
+
+func (p Problem) String() string {
+	return p.Field + ": " + p.Message
+}
+
+// ValidationError reports every problem of a configuration.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid configuration, %d problem(s):", len(e.Problems)))
+	for _, problem := range e.Problems {
+		lines = append(lines, "  "+problem.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// problems collects the problems found by Validate.
+type problems []Problem
+
+func (p *problems) add(field, format string, args ...interface{}) {
+	*p = append(*p, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the configuration and returns a *ValidationError listing every problem, or nil.
+// The directories of the state and report files must exist and be writable.
+func (cfg *ServiceConfig) Validate() error {
+	var p problems
+
+	port := ""
+	if cfg.Server != nil {
+		port = cfg.Server.Port
+	}
+	p.checkPort("server.port", port)
+	if cfg.GRPC.Enabled {
+		p.checkPort("grpc.port", cfg.GRPC.Port)
+		if cfg.GRPC.Port != "" && cfg.GRPC.Port == port {
+			p.add("grpc.port", "must differ from server.port")
+		}
+		if !cfg.HTTP.TLS.Enabled && !cfg.IsDevelopment() {
+			p.add("grpc.enabled", "requires server.tls in the %q environment, plaintext gRPC is only served in %s",
+				cfg.Environment, strings.Join(DevelopmentEnvironments, ", "))
+		}
+	}
+
+	if cfg.HTTP.TLS.Enabled {
+		p.checkRequired("server.tls.certFile", cfg.HTTP.TLS.CertFile)
+		p.checkRequired("server.tls.keyFile", cfg.HTTP.TLS.KeyFile)
+	}
+	if !tlsVersions[cfg.HTTP.TLS.MinVersion] {
+		p.add("server.tls.minVersion", "unsupported version %q, expected one of 1.0, 1.1, 1.2, 1.3", cfg.HTTP.TLS.MinVersion)
+	}
+
+	p.checkDataFile("runtime.stateFile", cfg.Runtime.StateFile)
+	p.checkDataFile("runtime.reportFile", cfg.Runtime.ReportFile)
+	if cfg.Runtime.ShutdownTimeout <= 0 {
+		p.add("runtime.shutdownTimeout", "must be positive")
+	}
+
+	p.checkEndpoints(cfg.Rest.Endpoints)
+	p.checkRateLimit("rest.rateLimit", cfg.Rest.RateLimit)
+
+	if cfg.Burrows.GrowthRate < 0 {
+		p.add("burrows.growthRate", "must not be negative")
+	}
+	if cfg.Burrows.MinGrowth < 0 {
+		p.add("burrows.minGrowth", "must not be negative")
+	}
+	if cfg.Burrows.CollapseAge < 0 {
+		p.add("burrows.collapseAge", "must not be negative")
+	}
+	if cfg.Report.Format != "" && !slices.Contains(models.ReportFormats, cfg.Report.Format) {
+		p.add("report.format", "unknown format %q, expected one of %s", cfg.Report.Format, strings.Join(models.ReportFormats, ", "))
+	}
+	if cfg.Holds.TTL < 0 {
+		p.add("holds.ttl", "must not be negative")
+	}
+	if cfg.Renters.MaxConcurrentBurrows < 0 {
+		p.add("renters.maxConcurrentBurrows", "must not be negative")
+	}
+	p.checkPricing(cfg.Pricing)
+
+	for name, job := range cfg.Jobs {
+		p.checkRequired("jobs."+name+".schedule", job.Schedule)
+	}
+
+	if !cfg.Auth.Enabled && !cfg.IsDevelopment() {
+		p.add("auth.enabled", "must be true in the %q environment, authentication can only be disabled in %s",
+			cfg.Environment, strings.Join(DevelopmentEnvironments, ", "))
+	}
+	if cfg.Auth.Enabled {
+		for i, key := range cfg.Auth.APIKeys {
+			p.checkRequired(fmt.Sprintf("auth.apiKeys[%d].key", i), key.Key)
+			p.checkRequired(fmt.Sprintf("auth.apiKeys[%d].subject", i), key.Subject)
+		}
+	}
+
+	if len(p) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(p, func(i, j int) bool { return p[i].Field < p[j].Field })
+	return &ValidationError{Problems: p}
+}
+
+func (p *problems) checkRequired(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		p.add(field, "is required")
+	}
+}
+
+func (p *problems) checkPort(field, port string) {
+	if port == "" {
+		p.add(field, "is required")
+		return
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		p.add(field, "%q is not a port between 1 and 65535", port)
+	}
+}
+
+// checkDataFile checks that the file is set, and that its directory exists and is writable.
+func (p *problems) checkDataFile(field, file string) {
+	if file == "" {
+		p.add(field, "is required")
+		return
+	}
+
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		p.add(field, "%q is a directory", file)
+		return
+	}
+
+	dir := filepath.Dir(file)
+	info, err := os.Stat(dir)
+	if err != nil {
+		p.add(field, "directory %q does not exist, create it or change the path", dir)
+		return
+	}
+	if !info.IsDir() {
+		p.add(field, "%q is not a directory", dir)
+		return
+	}
+
+	if !writable(dir) {
+		p.add(field, "directory %q is not writable", dir)
+	}
+}
+
+// checkEndpoints checks that the endpoints are the registered ones, with a known method, a path and
+// roles unless public, and that no two endpoints share the same method and path.
+func (p *problems) checkEndpoints(endpoints map[string]Endpoint) {
+	known := make(map[string]bool, len(endpointKeys))
+	for _, key := range endpointKeys {
+		known[key] = true
+		if _, ok := endpoints[key]; !ok {
+			p.add("rest.endpoints."+key, "is missing")
+		}
+	}
+
+	keys := make([]string, 0, len(endpoints))
+	for key := range endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	patterns := make(map[string]string, len(endpoints))
+	for _, key := range keys {
+		field := "rest.endpoints." + key
+		if len(known) > 0 && !known[key] {
+			p.add(field, "unknown endpoint, expected one of %s", strings.Join(endpointKeys, ", "))
+			continue
+		}
+
+		endpoint := endpoints[key]
+		method := strings.ToUpper(strings.TrimSpace(endpoint.Method))
+		if !HTTPMethods[method] {
+			p.add(field+".method", "unknown HTTP method %q", endpoint.Method)
+		}
+
+		if !strings.HasPrefix(endpoint.Path, "/") {
+			p.add(field+".path", "%q must start with /", endpoint.Path)
+			continue
+		}
+
+		// Paths differing only by the names of their parameters are the same path.
+		pattern := method + " " + pathPattern(endpoint.Path)
+		if other, exists := patterns[pattern]; exists {
+			p.add(field+".path", "%s %s is bound to both endpoints %q and %q", method, endpoint.Path, other, key)
+		} else {
+			patterns[pattern] = key
+		}
+
+		if !endpoint.Public && len(endpoint.Roles) == 0 {
+			p.add(field+".roles", "must list the roles allowed to call the endpoint, or the endpoint must be public")
+		}
+
+		p.checkRateLimit(field+".rateLimit", endpoint.RateLimit)
+	}
+}
+
+func (p *problems) checkRateLimit(field string, limit RateLimit) {
+	if limit.Requests < 0 || limit.Burst < 0 || limit.Period < 0 {
+		p.add(field, "must not be negative")
+	}
+}
+
+// checkPricing checks that the prices and discounts are not negative, and that the surges have a
+// positive multiplier and apply to valid days or occupancies.
+func (p *problems) checkPricing(pricing Pricing) {
+	if pricing.Currency != "" && !currencyPattern.MatchString(pricing.Currency) {
+		p.add("pricing.currency", "%q is not a 3 letters currency code", pricing.Currency)
+	}
+	if pricing.BasePrice < 0 || pricing.PerCubicMeter < 0 || pricing.PerMeterDepth < 0 {
+		p.add("pricing", "prices must not be negative")
+	}
+	if pricing.AgeDiscount.PerDay < 0 {
+		p.add("pricing.ageDiscount.perDay", "must not be negative")
+	}
+	if pricing.AgeDiscount.Max < 0 || pricing.AgeDiscount.Max > 1 {
+		p.add("pricing.ageDiscount.max", "must be between 0 and 1")
+	}
+	if pricing.MinLifetimeFactor < 0 || pricing.MinLifetimeFactor > 1 {
+		p.add("pricing.minLifetimeFactor", "must be between 0 and 1")
+	}
+
+	for i, season := range pricing.Seasons {
+		field := fmt.Sprintf("pricing.seasons[%d]", i)
+		for _, day := range []struct{ name, value string }{{"from", season.From}, {"to", season.To}} {
+			if _, err := time.Parse(MonthDayLayout, day.value); err != nil {
+				p.add(field+"."+day.name, "%q is not a MM-DD day", day.value)
+			}
+		}
+		if season.Multiplier <= 0 {
+			p.add(field+".multiplier", "must be positive")
+		}
+	}
+
+	for i, surge := range pricing.Occupancy {
+		field := fmt.Sprintf("pricing.occupancy[%d]", i)
+		if surge.Above < 0 || surge.Above >= 1 {
+			p.add(field+".above", "must be at least 0 and below 1")
+		}
+		if surge.Multiplier <= 0 {
+			p.add(field+".multiplier", "must be positive")
+		}
+	}
+}
+
+func pathPattern(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = "{}"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/configmgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	// The api package registers the keys of its endpoints.
+	_ "github.com/marcodd23/gopernet/internal/api"
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// loadConfig loads the property.yaml shipped with the service, with its data files in a
+// temporary directory.
+func loadConfig(t *testing.T) *config.ServiceConfig {
+	var cfg config.ServiceConfig
+	require.NoError(t, configmgr.ReadConfiguration("../../property.yaml", &cfg))
+
+	dir := t.TempDir()
+	cfg.Runtime.StateFile = filepath.Join(dir, "state.json")
+	cfg.Runtime.ReportFile = filepath.Join(dir, "report.txt")
+
+	return &cfg
+}
+
+func TestValidate_ShippedConfiguration(t *testing.T) {
+	cfg := loadConfig(t)
+	assert.NoError(t, cfg.Validate())
+
+	// Checking that the data directory is writable leaves no file behind.
+	entries, err := os.ReadDir(filepath.Dir(cfg.Runtime.StateFile))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Server.Port = "80800"
+	cfg.GRPC.Enabled = true
+	cfg.GRPC.Port = ""
+	cfg.Runtime.StateFile = filepath.Join(t.TempDir(), "missing", "state.json")
+	cfg.Runtime.ShutdownTimeout = 0 * time.Second
+	cfg.Report.Format = "xml"
+	cfg.Renters.MaxConcurrentBurrows = -1
+
+	endpoint := cfg.Rest.Endpoints["get-burrow"]
+	endpoint.Method = "FETCH"
+	cfg.Rest.Endpoints["get-burrow"] = endpoint
+
+	endpoint = cfg.Rest.Endpoints["add-burrow"]
+	endpoint.Method = "GET"
+	cfg.Rest.Endpoints["add-burrow"] = endpoint
+
+	cfg.Rest.Endpoints["get-burows"] = cfg.Rest.Endpoints["get-burrows"]
+	delete(cfg.Rest.Endpoints, "get-report")
+	cfg.Rest.Endpoints["ws"] = config.Endpoint{Method: "GET"}
+
+	err := cfg.Validate()
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	fields := make([]string, 0, len(validationErr.Problems))
+	for _, problem := range validationErr.Problems {
+		fields = append(fields, problem.Field)
+	}
+	assert.Equal(t, []string{
+		"grpc.port",
+		"renters.maxConcurrentBurrows",
+		"report.format",
+		"rest.endpoints.get-burows",
+		"rest.endpoints.get-burrow.method",
+		"rest.endpoints.get-burrows.path",
+		"rest.endpoints.get-report",
+		"rest.endpoints.ws.path",
+		"runtime.shutdownTimeout",
+		"runtime.stateFile",
+		"server.port",
+	}, fields)
+	assert.Contains(t, err.Error(), "11 problem(s)")
+	assert.Contains(t, err.Error(), `GET /burrows is bound to both endpoints "add-burrow" and "get-burrows"`)
+}
+
+func TestValidate_Pricing(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Pricing.Currency = "euro"
+	cfg.Pricing.PerCubicMeter = -1
+	cfg.Pricing.AgeDiscount.Max = 1.5
+	cfg.Pricing.Seasons = []config.SeasonSurge{{Name: "summer", From: "06-01", To: "08-32", Multiplier: 0}}
+	cfg.Pricing.Occupancy = []config.OccupancySurge{{Above: 1, Multiplier: 2}}
+
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, cfg.Validate(), &validationErr)
+
+	fields := make([]string, 0, len(validationErr.Problems))
+	for _, problem := range validationErr.Problems {
+		fields = append(fields, problem.Field)
+	}
+	assert.Equal(t, []string{
+		"pricing",
+		"pricing.ageDiscount.max",
+		"pricing.currency",
+		"pricing.occupancy[0].above",
+		"pricing.seasons[0].multiplier",
+		"pricing.seasons[0].to",
+	}, fields)
+}
+
+func TestValidate_AuthOnlyDisabledInDevelopment(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.Auth.Enabled = false
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Environment = "production"
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, cfg.Validate(), &validationErr)
+	require.Len(t, validationErr.Problems, 1)
+	assert.Equal(t, "auth.enabled", validationErr.Problems[0].Field)
+
+	cfg.Auth.Enabled = true
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_PlaintextGRPCOnlyInDevelopment(t *testing.T) {
+	cfg := loadConfig(t)
+	cfg.GRPC.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Environment = "production"
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, cfg.Validate(), &validationErr)
+	require.Len(t, validationErr.Problems, 1)
+	assert.Equal(t, "grpc.enabled", validationErr.Problems[0].Field)
+}
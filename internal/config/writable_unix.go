@@ -0,0 +1,10 @@
+//go:build unix
+
+package config
+
+import "golang.org/x/sys/unix"
+
+// writable reports whether the process can create files in the directory.
+func writable(dir string) bool {
+	return unix.Access(dir, unix.W_OK) == nil
+}
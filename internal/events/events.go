@@ -0,0 +1,89 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type identifies the kind of an Event.
+type Type string
+
+const (
+	// AlertPersistenceFailing is raised when the persistence circuit breaker opens.
+	AlertPersistenceFailing Type = "alert.persistence_failing"
+	// AlertPersistenceRecovered is raised when the persistence circuit breaker closes again.
+	AlertPersistenceRecovered Type = "alert.persistence_recovered"
+	// BurrowChanged is raised when a burrow is added, rented or released. Data is the burrow.
+	BurrowChanged Type = "burrow.changed"
+	// BurrowsUpdated is raised when every burrow has been updated by the periodic update, or restored
+	// from a backup.
+	BurrowsUpdated Type = "burrows.updated"
+	// HoldCreated is raised when a burrow is held for a renter. Data is the hold.
+	HoldCreated Type = "hold.created"
+	// HoldExpired is raised when an expired hold is released. Data is the hold.
+	HoldExpired Type = "hold.expired"
+	// WaitlistPromoted is raised when the first renter of the waitlist of a released burrow gets a
+	// hold on it. Data is the hold.
+	WaitlistPromoted Type = "waitlist.promoted"
+	// ReportGenerated is raised when the periodic report has been saved. Data is the report.
+	ReportGenerated Type = "report.generated"
+)
+
+// Event is a notification published on the Bus.
+type Event struct {
+	Type    Type        `json:"type"`
+	Time    time.Time   `json:"time"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Bus is an in-process publish/subscribe hub. Publishing never blocks: events are dropped
+// for subscribers whose buffer is full.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan Event
+	nextID      int
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[int]chan Event)}
+}
+
+// Publish delivers the event to every subscriber. A zero Time is set to the current time.
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber with the given buffer size. The returned function
+// unsubscribes and closes the channel.
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(ch)
+		})
+	}
+}
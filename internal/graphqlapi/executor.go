@@ -0,0 +1,344 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// Default limits of the queries.
+const (
+	DefaultMaxDepth      = 8
+	DefaultMaxComplexity = 200
+)
+
+// defaultListSize is the estimated size of the lists whose size is not known before resolving
+// them, the rentals of a burrow.
+const defaultListSize = 10
+
+// forecastMinutesPerCost is the number of simulated minutes a forecast adds to the complexity.
+const forecastMinutesPerCost = 60
+
+// Request is a GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Executor executes the GraphQL requests against the schema, rejecting the operations that exceed
+// the depth or complexity limits before resolving them.
+//
+// The depth of an operation is its deepest level of nested fields. Its complexity counts every
+// field it selects, fragments included, the fields selected in the items of a list once per item:
+// the burrows lists count the burrows, and the other lists are estimated to hold 10 items.
+// A forecast also counts one per simulated hour. Introspection fields are not counted.
+type Executor struct {
+	service       services.GopherService
+	schema        graphql.Schema
+	maxDepth      int
+	maxComplexity int
+}
+
+// NewExecutor builds the schema resolving through the service, with the limits of cfg.
+func NewExecutor(service services.GopherService, cfg config.GraphQL) (*Executor, error) {
+	schema, err := NewSchema(service)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := &Executor{service: service, schema: schema, maxDepth: cfg.MaxDepth, maxComplexity: cfg.MaxComplexity}
+	if executor.maxDepth <= 0 {
+		executor.maxDepth = DefaultMaxDepth
+	}
+	if executor.maxComplexity <= 0 {
+		executor.maxComplexity = DefaultMaxComplexity
+	}
+
+	return executor, nil
+}
+
+// Execute parses, validates and executes the request.
+func (e *Executor) Execute(ctx context.Context, request Request) *graphql.Result {
+	document, err := parse(request)
+	if err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}
+	}
+
+	validation := graphql.ValidateDocument(&e.schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := e.checkLimits(document, request); err != nil {
+		formatted := gqlerrors.FormatError(err)
+		formatted.Extensions = err.Extensions()
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{formatted}}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           document,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+}
+
+// Mutations returns the mutation fields the request selects, once per selection, so that they
+// can be rate limited before the execution. It returns nothing for queries and invalid requests.
+func (e *Executor) Mutations(request Request) []string {
+	document, err := parse(request)
+	if err != nil {
+		return nil
+	}
+
+	fragments, operation := operationOf(document, request.OperationName)
+	if operation == nil || operation.Operation != ast.OperationTypeMutation {
+		return nil
+	}
+
+	var mutations []string
+	var collect func(selectionSet *ast.SelectionSet, visited map[string]bool)
+	collect = func(selectionSet *ast.SelectionSet, visited map[string]bool) {
+		if selectionSet == nil {
+			return
+		}
+
+		for _, selection := range selectionSet.Selections {
+			switch selection := selection.(type) {
+			case *ast.Field:
+				if !strings.HasPrefix(selection.Name.Value, "__") {
+					mutations = append(mutations, selection.Name.Value)
+				}
+			case *ast.InlineFragment:
+				collect(selection.SelectionSet, visited)
+			case *ast.FragmentSpread:
+				// Fragment cycles are invalid, but the request is not validated yet.
+				if fragment, ok := fragments[selection.Name.Value]; ok && !visited[fragment.Name.Value] {
+					visited[fragment.Name.Value] = true
+					collect(fragment.SelectionSet, visited)
+					delete(visited, fragment.Name.Value)
+				}
+			}
+		}
+	}
+	collect(operation.SelectionSet, make(map[string]bool))
+
+	return mutations
+}
+
+func parse(request Request) (*ast.Document, error) {
+	return parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(request.Query), Name: "GraphQL request"}),
+	})
+}
+
+// operationOf returns the fragments of the document and the operation to execute, nil when it is
+// missing.
+func operationOf(document *ast.Document, operationName string) (map[string]*ast.FragmentDefinition, *ast.OperationDefinition) {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var operation *ast.OperationDefinition
+
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+
+	return fragments, operation
+}
+
+// checkLimits measures the operation to execute. The document must be valid: validation rejects
+// the fragment cycles.
+func (e *Executor) checkLimits(document *ast.Document, request Request) *Error {
+	fragments, operation := operationOf(document, request.OperationName)
+	if operation == nil {
+		// Execution reports the missing operation.
+		return nil
+	}
+
+	root := e.schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = e.schema.MutationType()
+	}
+
+	m := &measurer{
+		schema:    &e.schema,
+		fragments: fragments,
+		variables: request.Variables,
+		burrows:   len(e.service.GetAllBurrows()),
+		measured:  make(map[string][2]int),
+	}
+	depth, complexity := m.measure(root, operation.SelectionSet)
+	if depth > e.maxDepth {
+		return &Error{Code: CodeQueryTooDeep, Message: fmt.Sprintf("the query has a depth of %d, the maximum is %d", depth, e.maxDepth)}
+	}
+	if complexity > e.maxComplexity {
+		return &Error{Code: CodeQueryTooComplex, Message: fmt.Sprintf("the query has a complexity of %d, the maximum is %d", complexity, e.maxComplexity)}
+	}
+
+	return nil
+}
+
+// measurer measures the selection sets of an operation. The measures of the fragments are
+// memoized, so that fragments spread many times are measured once.
+type measurer struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	burrows   int
+	measured  map[string][2]int
+}
+
+// measure returns the depth and the complexity of a selection set on the parent type.
+func (m *measurer) measure(parent graphql.Type, selectionSet *ast.SelectionSet) (depth, complexity int) {
+	if selectionSet == nil {
+		return 0, 0
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var childDepth, childComplexity int
+
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+
+			fieldType, list := m.fieldType(parent, selection.Name.Value)
+			childDepth, childComplexity = m.measure(fieldType, selection.SelectionSet)
+			childDepth++
+			if list {
+				childComplexity *= m.listSize(selection.Name.Value)
+			}
+			childComplexity += m.cost(selection)
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if selection.TypeCondition != nil {
+				fragmentType = m.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			childDepth, childComplexity = m.measure(fragmentType, selection.SelectionSet)
+		case *ast.FragmentSpread:
+			childDepth, childComplexity = m.measureFragment(selection.Name.Value)
+		}
+
+		if childDepth > depth {
+			depth = childDepth
+		}
+		complexity += childComplexity
+	}
+
+	return depth, complexity
+}
+
+func (m *measurer) measureFragment(name string) (depth, complexity int) {
+	if measured, ok := m.measured[name]; ok {
+		return measured[0], measured[1]
+	}
+
+	fragment, ok := m.fragments[name]
+	if !ok {
+		return 0, 0
+	}
+
+	depth, complexity = m.measure(m.schema.Type(fragment.TypeCondition.Name.Value), fragment.SelectionSet)
+	m.measured[name] = [2]int{depth, complexity}
+
+	return depth, complexity
+}
+
+// fieldType returns the named type of the field of parent, and whether the field is a list.
+func (m *measurer) fieldType(parent graphql.Type, name string) (graphql.Type, bool) {
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return nil, false
+	}
+	field, ok := object.Fields()[name]
+	if !ok {
+		return nil, false
+	}
+
+	var fieldType graphql.Type = field.Type
+	list := false
+	for {
+		switch wrapped := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = wrapped.OfType
+		case *graphql.List:
+			fieldType = wrapped.OfType
+			list = true
+		default:
+			return fieldType, list
+		}
+	}
+}
+
+// listSize returns the number of items of the list field, at least one.
+func (m *measurer) listSize(name string) int {
+	size := defaultListSize
+	if name == "burrows" {
+		size = m.burrows
+	}
+	if size < 1 {
+		return 1
+	}
+
+	return size
+}
+
+// cost returns the complexity of the field itself: one, and one per simulated hour for a forecast.
+func (m *measurer) cost(field *ast.Field) int {
+	if field.Name.Value != "forecast" {
+		return 1
+	}
+
+	minutes := m.intArgument(field, "minutes", maxForecastMinutes)
+	if minutes < 0 {
+		minutes = 0
+	}
+
+	return 1 + minutes/forecastMinutesPerCost
+}
+
+// intArgument returns the value of the int argument of the field, or fallback when it is not an int.
+func (m *measurer) intArgument(field *ast.Field, name string, fallback int) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+
+		var value interface{} = argument.Value.GetValue()
+		if variable, ok := argument.Value.(*ast.Variable); ok {
+			value = m.variables[variable.Name.Value]
+		}
+
+		switch value := value.(type) {
+		case string:
+			if n, err := strconv.Atoi(value); err == nil {
+				return n
+			}
+		case int:
+			return value
+		case float64:
+			return int(value)
+		}
+	}
+
+	return fallback
+}
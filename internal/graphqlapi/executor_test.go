@@ -0,0 +1,196 @@
+package graphqlapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/graphqlapi"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+func newExecutor(t *testing.T, cfg config.GraphQL) *graphqlapi.Executor {
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 3.0, Width: 1.3, Occupied: true, Age: 50, RentedBy: "alice"}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2, Age: 40}))
+
+	executor, err := graphqlapi.NewExecutor(services.NewGopherNetService(repo), cfg)
+	require.NoError(t, err)
+
+	return executor
+}
+
+// data returns the JSON encoding of the result data, failing on errors.
+func data(t *testing.T, result *graphql.Result) string {
+	require.Empty(t, result.Errors)
+
+	encoded, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+
+	return string(encoded)
+}
+
+// errorCode returns the code of the first error of the result.
+func errorCode(t *testing.T, result *graphql.Result) string {
+	require.NotEmpty(t, result.Errors)
+	code, _ := result.Errors[0].Extensions["code"].(string)
+
+	return code
+}
+
+func TestExecutor_Queries(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+	ctx := context.Background()
+
+	result := executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows(available: true) { name rentedBy forecast(minutes: 60) { age collapsed } } }`})
+	assert.JSONEq(t, `{"burrows":[{"name":"The Deep Den","rentedBy":null,"forecast":{"age":100,"collapsed":false}}]}`, data(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{
+		Query:     `query Burrow($name: String!) { burrow(name: $name) { name occupied rentedBy } }`,
+		Variables: map[string]interface{}{"name": "The Molehole"},
+	})
+	assert.JSONEq(t, `{"burrow":{"name":"The Molehole","occupied":true,"rentedBy":"alice"}}`, data(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrow(name: "Nowhere") { name } }`})
+	assert.JSONEq(t, `{"burrow":null}`, data(t, result))
+
+	// Only managers and admins read the report.
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ report }`})
+	assert.Equal(t, graphqlapi.CodeUnauthorized, errorCode(t, result))
+	bob := auth.WithPrincipal(ctx, &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}})
+	result = executor.Execute(bob, graphqlapi.Request{Query: `{ report }`})
+	assert.Equal(t, graphqlapi.CodeForbidden, errorCode(t, result))
+	manager := auth.WithPrincipal(ctx, &auth.Principal{Subject: "carol", Roles: []string{auth.RoleManager}})
+	result = executor.Execute(manager, graphqlapi.Request{Query: `{ report }`})
+	assert.Contains(t, data(t, result), "GopherNet Burrow Report")
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrow(name: "The Deep Den") { volume } }`})
+	assert.JSONEq(t, fmt.Sprintf(`{"burrow":{"volume":%v}}`, (&models.Burrow{Depth: 2.2, Width: 1.2}).Volume()), data(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows { forecast(minutes: -1) { depth } } }`})
+	assert.Equal(t, graphqlapi.CodeInvalidArgument, errorCode(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows { unknown } }`})
+	assert.NotEmpty(t, result.Errors)
+}
+
+func TestExecutor_Mutations(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}})
+
+	result := executor.Execute(bob, graphqlapi.Request{Query: `mutation { rentBurrow(name: "The Deep Den") { occupied rentedBy } }`})
+	assert.JSONEq(t, `{"rentBurrow":{"occupied":true,"rentedBy":"bob"}}`, data(t, result))
+
+	result = executor.Execute(bob, graphqlapi.Request{Query: `mutation { rentBurrow(name: "The Molehole") { name } }`})
+	assert.Equal(t, models.ErrBurrowUnavailable.Code, errorCode(t, result))
+
+	// Renters only release their own burrows, managers release any burrow.
+	result = executor.Execute(bob, graphqlapi.Request{Query: `mutation { releaseBurrow(name: "The Molehole") { name } }`})
+	assert.Equal(t, graphqlapi.CodeForbidden, errorCode(t, result))
+
+	result = executor.Execute(bob, graphqlapi.Request{Query: `mutation { releaseBurrow(name: "The Deep Den") { occupied rentedBy } }`})
+	assert.JSONEq(t, `{"releaseBurrow":{"occupied":false,"rentedBy":null}}`, data(t, result))
+
+	manager := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "carol", Roles: []string{auth.RoleManager}})
+	result = executor.Execute(manager, graphqlapi.Request{Query: `mutation { releaseBurrow(name: "The Molehole") { occupied } }`})
+	assert.JSONEq(t, `{"releaseBurrow":{"occupied":false}}`, data(t, result))
+
+	result = executor.Execute(manager, graphqlapi.Request{Query: `mutation { releaseBurrow(name: "The Molehole") { occupied } }`})
+	assert.Equal(t, models.ErrBurrowNotRented.Code, errorCode(t, result))
+}
+
+func TestExecutor_Limits(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{MaxDepth: 2, MaxComplexity: 6})
+	ctx := context.Background()
+
+	result := executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows { name forecast(minutes: 1) { depth } } }`})
+	assert.Equal(t, graphqlapi.CodeQueryTooDeep, errorCode(t, result))
+
+	// Fragments and aliases are counted.
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `
+		{ a: burrows { ...fields } b: burrows { ...fields } }
+		fragment fields on Burrow { name depth }`})
+	assert.Equal(t, graphqlapi.CodeQueryTooComplex, errorCode(t, result))
+
+	// The fields of the burrows count once per burrow.
+	manager := auth.WithPrincipal(ctx, &auth.Principal{Subject: "carol", Roles: []string{auth.RoleManager}})
+	result = executor.Execute(manager, graphqlapi.Request{Query: `{ burrows { name depth } report }`})
+	assert.Empty(t, result.Errors)
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows { name depth width } }`})
+	assert.Equal(t, graphqlapi.CodeQueryTooComplex, errorCode(t, result))
+
+	// Introspection is not limited.
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ __schema { types { name fields { name type { name ofType { name } } } } } }`})
+	assert.Empty(t, result.Errors)
+}
+
+func TestExecutor_LimitsForecasts(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+	ctx := context.Background()
+
+	// A forecast counts one per simulated hour, for every burrow.
+	result := executor.Execute(ctx, graphqlapi.Request{Query: `{ burrow(name: "The Deep Den") { forecast(minutes: 43200) { depth } } }`})
+	assert.Equal(t, graphqlapi.CodeQueryTooComplex, errorCode(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{
+		Query:     `query Forecast($minutes: Int!) { burrows { forecast(minutes: $minutes) { depth } } }`,
+		Variables: map[string]interface{}{"minutes": float64(6000)},
+	})
+	assert.Equal(t, graphqlapi.CodeQueryTooComplex, errorCode(t, result))
+
+	result = executor.Execute(ctx, graphqlapi.Request{Query: `{ burrows { forecast(minutes: 1440) { depth } } }`})
+	assert.Empty(t, result.Errors)
+}
+
+func TestExecutor_Rentals(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "bob", Roles: []string{auth.RoleRenter}})
+	carol := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "carol", Roles: []string{auth.RoleRenter}})
+	manager := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "dave", Roles: []string{auth.RoleManager}})
+
+	data(t, executor.Execute(bob, graphqlapi.Request{Query: `mutation { rentBurrow(name: "The Deep Den") { name } }`}))
+	data(t, executor.Execute(bob, graphqlapi.Request{Query: `mutation { releaseBurrow(name: "The Deep Den") { name } }`}))
+	data(t, executor.Execute(carol, graphqlapi.Request{Query: `mutation { rentBurrow(name: "The Deep Den") { name } }`}))
+
+	query := graphqlapi.Request{Query: `{ burrow(name: "The Deep Den") { rentals { renter charged current: endedAt } } }`}
+	assert.JSONEq(t, `{"burrow":{"rentals":[{"renter":"carol","charged":0,"current":null}]}}`, data(t, executor.Execute(carol, query)))
+
+	result := executor.Execute(manager, graphqlapi.Request{Query: `{ burrow(name: "The Deep Den") { rentals { renter } } }`})
+	assert.JSONEq(t, `{"burrow":{"rentals":[{"renter":"bob"},{"renter":"carol"}]}}`, data(t, result))
+}
+
+func TestExecutor_Mutations_Listed(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+
+	mutations := executor.Mutations(graphqlapi.Request{Query: `
+		mutation { a: rentBurrow(name: "x") { name } ...more }
+		fragment more on Mutation { b: rentBurrow(name: "y") { name } releaseBurrow(name: "z") { name } }`})
+	assert.Equal(t, []string{"rentBurrow", "rentBurrow", "releaseBurrow"}, mutations)
+
+	assert.Empty(t, executor.Mutations(graphqlapi.Request{Query: `{ burrows { name } }`}))
+}
+
+func TestExecutor_LimitsNestedFragments(t *testing.T) {
+	executor := newExecutor(t, config.GraphQL{})
+
+	// Every fragment doubles the complexity of the previous one.
+	query := `{ burrows { ...f0 } } fragment f0 on Burrow { name }`
+	for i := 1; i <= 40; i++ {
+		query += fmt.Sprintf(" fragment f%d on Burrow { ...f%d ...f%d }", i, i-1, i-1)
+	}
+	query = strings.Replace(query, "...f0 }", fmt.Sprintf("...f%d }", 40), 1)
+
+	result := executor.Execute(context.Background(), graphqlapi.Request{Query: query})
+	assert.Equal(t, graphqlapi.CodeQueryTooComplex, errorCode(t, result))
+}
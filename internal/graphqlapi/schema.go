@@ -0,0 +1,338 @@
+// Package graphqlapi defines the GraphQL schema of GopherNet and executes queries within
+// depth and complexity limits.
+package graphqlapi
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// maxForecastMinutes bounds the horizon of the burrow forecasts (30 days).
+const maxForecastMinutes = 30 * 24 * 60
+
+// maxNameLength bounds the length of the burrow names.
+const maxNameLength = 100
+
+// Codes of the errors raised by the GraphQL layer itself. Domain errors use the code of their models.Error.
+const (
+	CodeInvalidArgument = "invalid_argument"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeQueryTooDeep    = "query_too_deep"
+	CodeQueryTooComplex = "query_too_complex"
+	CodeInternalError   = "internal_error"
+)
+
+// Error is a GraphQL error exposing its code in the "extensions" of the response.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError.
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// toError converts a service error into an Error carrying its code. Unexpected errors are hidden.
+func toError(err error) error {
+	var domainErr *models.Error
+	if errors.As(err, &domainErr) {
+		return &Error{Code: domainErr.Code, Message: err.Error()}
+	}
+
+	return &Error{Code: CodeInternalError, Message: "an unexpected error occurred"}
+}
+
+// Forecast is the predicted state of a burrow after some minutes, following the periodic updates.
+type Forecast struct {
+	Minutes   int
+	Depth     float64
+	Age       int
+	Collapsed bool
+}
+
+// forecast simulates the periodic updates of the burrow for the given number of minutes.
+func forecast(burrow models.Burrow, minutes int) Forecast {
+	for i := 0; i < minutes; i++ {
+		burrow.UpdateDepth()
+	}
+
+	return Forecast{Minutes: minutes, Depth: burrow.Depth, Age: burrow.Age, Collapsed: burrow.HasCollapsed()}
+}
+
+// NewSchema builds the GraphQL schema resolving through the service.
+func NewSchema(service services.GopherService) (graphql.Schema, error) {
+	forecastType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Forecast",
+		Description: "Predicted state of a burrow, if it keeps its current occupancy.",
+		Fields: graphql.Fields{
+			"minutes":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"depth":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Depth in meters."},
+			"age":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Age in minutes."},
+			"collapsed": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	rentalType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Rental",
+		Description: "Occupation of a burrow by a renter.",
+		Fields: graphql.Fields{
+			"id":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"renter": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Subject of the renter."},
+			"startedAt": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Start of the rental, in RFC 3339 format.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*models.Rental).StartedAt.Format(time.RFC3339), nil
+				},
+			},
+			"endedAt": &graphql.Field{
+				Type:        graphql.String,
+				Description: "End of the rental, in RFC 3339 format, null while the rental is current.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if endedAt := p.Source.(*models.Rental).EndedAt; endedAt != nil {
+						return endedAt.Format(time.RFC3339), nil
+					}
+					return nil, nil
+				},
+			},
+			"charged": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Usage charged so far, in minor units."},
+		},
+	})
+
+	burrowType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Burrow",
+		Fields: graphql.Fields{
+			"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"depth":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Depth in meters."},
+			"width":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Width in meters."},
+			"occupied": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"age":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Age in minutes."},
+			"rentedBy": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Subject of the renter, when occupied.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if rentedBy := p.Source.(*models.Burrow).RentedBy; rentedBy != "" {
+						return rentedBy, nil
+					}
+					return nil, nil
+				},
+			},
+			"collapsed": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*models.Burrow).HasCollapsed(), nil
+				},
+			},
+			"volume": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Float),
+				Description: "Volume in cubic meters.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*models.Burrow).Volume(), nil
+				},
+			},
+			"rentals": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rentalType))),
+				Description: "Current and past rentals of the burrow, oldest first. Renters only see their own rentals.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					caller, err := callerPrincipal(p)
+					if err != nil {
+						return nil, err
+					}
+
+					rentals, err := service.GetBurrowRentals(p.Source.(*models.Burrow).Name)
+					if err != nil {
+						return nil, toError(err)
+					}
+					if !caller.HasAnyRole(auth.RoleManager, auth.RoleAdmin) {
+						own := rentals[:0]
+						for _, rental := range rentals {
+							if rental.Renter == caller.Subject {
+								own = append(own, rental)
+							}
+						}
+						rentals = own
+					}
+					return rentals, nil
+				},
+			},
+			"forecast": &graphql.Field{
+				Type: graphql.NewNonNull(forecastType),
+				Args: graphql.FieldConfigArgument{
+					"minutes": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					minutes, _ := p.Args["minutes"].(int)
+					if minutes < 0 || minutes > maxForecastMinutes {
+						return nil, &Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("minutes must be between 0 and %d", maxForecastMinutes)}
+					}
+					return forecast(*p.Source.(*models.Burrow), minutes), nil
+				},
+			},
+		},
+	})
+
+	nameArgs := graphql.FieldConfigArgument{
+		"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+	}
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"burrows": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(burrowType))),
+				Description: "Every burrow, or only the available ones (neither occupied nor collapsed) when available is true.",
+				Args: graphql.FieldConfigArgument{
+					"available": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					burrows := service.GetAllBurrows()
+					if available, _ := p.Args["available"].(bool); available {
+						filtered := burrows[:0]
+						for _, burrow := range burrows {
+							if !burrow.Occupied && !burrow.HasCollapsed() {
+								filtered = append(filtered, burrow)
+							}
+						}
+						burrows = filtered
+					}
+					return burrows, nil
+				},
+			},
+			"burrow": &graphql.Field{
+				Type:        burrowType,
+				Description: "The named burrow, or null if it does not exist.",
+				Args:        nameArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name, err := nameArg(p)
+					if err != nil {
+						return nil, err
+					}
+
+					burrow, err := service.GetBurrow(name)
+					if errors.Is(err, models.ErrBurrowNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, toError(err)
+					}
+					return burrow, nil
+				},
+			},
+			"report": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "The burrows report, for managers and admins.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					caller, err := callerPrincipal(p)
+					if err != nil {
+						return nil, err
+					}
+					if !caller.HasAnyRole(auth.RoleManager, auth.RoleAdmin) {
+						return nil, &Error{Code: CodeForbidden, Message: "only managers and admins can read the report"}
+					}
+
+					report, err := service.GenerateReport()
+					if err != nil {
+						return nil, toError(err)
+					}
+					return report, nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"rentBurrow": &graphql.Field{
+				Type:        graphql.NewNonNull(burrowType),
+				Description: "Rents an available burrow on behalf of the caller.",
+				Args:        nameArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name, err := nameArg(p)
+					if err != nil {
+						return nil, err
+					}
+
+					caller, err := callerPrincipal(p)
+					if err != nil {
+						return nil, err
+					}
+
+					if err := service.RentBurrow(name, caller.Subject); err != nil {
+						return nil, toError(err)
+					}
+					return resolveBurrow(service, name)
+				},
+			},
+			"releaseBurrow": &graphql.Field{
+				Type:        graphql.NewNonNull(burrowType),
+				Description: "Frees a rented burrow. Renters can only release the burrows they rent.",
+				Args:        nameArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name, err := nameArg(p)
+					if err != nil {
+						return nil, err
+					}
+
+					caller, err := callerPrincipal(p)
+					if err != nil {
+						return nil, err
+					}
+
+					renter := caller.Subject
+					if caller.HasAnyRole(auth.RoleManager, auth.RoleAdmin) {
+						renter = ""
+					}
+
+					if err := service.ReleaseBurrow(name, renter); err != nil {
+						return nil, toError(err)
+					}
+					return resolveBurrow(service, name)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+}
+
+// callerPrincipal returns the caller of the request, stored in its context by the HTTP layer.
+func callerPrincipal(p graphql.ResolveParams) (*auth.Principal, error) {
+	principal, ok := auth.PrincipalFromContext(p.Context)
+	if !ok {
+		return nil, &Error{Code: CodeUnauthorized, Message: "missing or invalid credentials"}
+	}
+
+	return principal, nil
+}
+
+func nameArg(p graphql.ResolveParams) (string, error) {
+	name, _ := p.Args["name"].(string)
+	if name == "" || len(name) > maxNameLength {
+		return "", &Error{Code: CodeInvalidArgument, Message: fmt.Sprintf("name is required and must be at most %d characters", maxNameLength)}
+	}
+
+	return name, nil
+}
+
+func resolveBurrow(service services.GopherService, name string) (interface{}, error) {
+	burrow, err := service.GetBurrow(name)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return burrow, nil
+}
@@ -0,0 +1,190 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/grpcapi/gophernetv1"
+	"github.com/marcodd23/gopernet/internal/resilience"
+)
+
+// Metadata keys carrying the credentials, as the X-API-Key and Authorization headers of the
+// REST API.
+const (
+	APIKeyMetadata        = "x-api-key"
+	AuthorizationMetadata = "authorization"
+)
+
+// endpointByMethod binds every method to the REST endpoint whose roles and rate limit apply to it.
+// Methods missing from the table are allowed to nobody.
+var endpointByMethod = map[string]string{
+	gophernetv1.BurrowService_ListBurrows_FullMethodName:   "get-burrows",
+	gophernetv1.BurrowService_GetBurrow_FullMethodName:     "get-burrow",
+	gophernetv1.BurrowService_RentBurrow_FullMethodName:    "rent-burrow",
+	gophernetv1.BurrowService_ReleaseBurrow_FullMethodName: "release-burrow",
+	gophernetv1.BurrowService_GetReport_FullMethodName:     "get-report",
+	gophernetv1.BurrowService_WatchBurrows_FullMethodName:  "ws",
+}
+
+func (s *Server) authUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *Server) authStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize applies the policy of the REST API to a call of method: the client IP is rate limited
+// by rest.rateLimit, then the caller is authenticated, authorized against the roles of the
+// endpoint of the method and rate limited by its rateLimit. It returns ctx carrying the principal.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	cfg := s.store.Current()
+	ip := clientIP(ctx)
+
+	if err := s.limiters.allow("", cfg.Rest.RateLimit, "ip:"+ip); err != nil {
+		return nil, err
+	}
+
+	key, ok := endpointByMethod[method]
+	endpoint := cfg.Rest.Endpoints[key]
+	if !ok || len(endpoint.Roles) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "this method is not allowed to any role")
+	}
+
+	principal := auth.Anonymous()
+	if s.authenticator != nil {
+		var err error
+		if principal, err = s.authenticate(ctx); err != nil {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid credentials")
+		}
+	}
+
+	if !principal.HasAnyRole(endpoint.Roles...) {
+		return nil, status.Errorf(codes.PermissionDenied, "this method requires one of the roles %s", strings.Join(endpoint.Roles, ", "))
+	}
+
+	limitKey := "ip:" + ip
+	if principal.Method != auth.MethodNone {
+		limitKey = "principal:" + principal.Subject
+	}
+	if err := s.limiters.allow(key, endpoint.RateLimit, limitKey); err != nil {
+		return nil, err
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// authenticate authenticates the API key or the bearer token of the call metadata.
+func (s *Server) authenticate(ctx context.Context) (*auth.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if keys := md.Get(APIKeyMetadata); len(keys) > 0 && keys[0] != "" {
+		return s.authenticator.AuthenticateAPIKey(keys[0])
+	}
+
+	token := ""
+	if values := md.Get(AuthorizationMetadata); len(values) > 0 {
+		header := values[0]
+		if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+			token = strings.TrimSpace(header[len("Bearer "):])
+		}
+	}
+
+	return s.authenticator.AuthenticateBearer(token)
+}
+
+// callerFromContext returns the principal stored by the interceptors.
+func callerFromContext(ctx context.Context) *auth.Principal {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal
+	}
+
+	return auth.Anonymous()
+}
+
+// clientIP returns the IP address of the client of the call.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// authorizedStream is a server stream whose context carries the principal.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// rateLimiters keeps a rate limiter per endpoint, replaced when the limit of the endpoint changes.
+type rateLimiters struct {
+	clock clock.Clock
+
+	mu       sync.Mutex
+	limits   map[string]config.RateLimit
+	limiters map[string]*resilience.RateLimiter
+}
+
+func newRateLimiters(clk clock.Clock) *rateLimiters {
+	return &rateLimiters{
+		clock:    clk,
+		limits:   make(map[string]config.RateLimit),
+		limiters: make(map[string]*resilience.RateLimiter),
+	}
+}
+
+// allow takes a token from the bucket of client in the limiter of endpoint, returning a
+// ResourceExhausted error when none is left.
+func (l *rateLimiters) allow(endpoint string, limit config.RateLimit, client string) error {
+	if limit.Requests <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	limiter, ok := l.limiters[endpoint]
+	if !ok || l.limits[endpoint] != limit {
+		limiter = resilience.NewRateLimiter(l.clock, limit.Requests, limit.Period, limit.Burst)
+		l.limiters[endpoint] = limiter
+		l.limits[endpoint] = limit
+	}
+	l.mu.Unlock()
+
+	decision := limiter.Allow(client)
+	if !decision.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "too many requests, retry in %s", decision.RetryAfter.Round(time.Second))
+	}
+
+	return nil
+}
@@ -0,0 +1,1057 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.33.0
+// 	protoc        v5.27.1
+// source: gophernet/v1/burrows.proto
+
+package gophernetv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchBurrowsResponse_Kind int32
+
+const (
+	WatchBurrowsResponse_KIND_UNSPECIFIED WatchBurrowsResponse_Kind = 0
+	// The burrow is part of the snapshot sent when watching starts, or after a periodic update.
+	WatchBurrowsResponse_KIND_SNAPSHOT WatchBurrowsResponse_Kind = 1
+	// The burrow has been added, rented or released.
+	WatchBurrowsResponse_KIND_CHANGED WatchBurrowsResponse_Kind = 2
+)
+
+// Enum value maps for WatchBurrowsResponse_Kind.
+var (
+	WatchBurrowsResponse_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_SNAPSHOT",
+		2: "KIND_CHANGED",
+	}
+	WatchBurrowsResponse_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_SNAPSHOT":    1,
+		"KIND_CHANGED":     2,
+	}
+)
+
+func (x WatchBurrowsResponse_Kind) Enum() *WatchBurrowsResponse_Kind {
+	p := new(WatchBurrowsResponse_Kind)
+	*p = x
+	return p
+}
+
+func (x WatchBurrowsResponse_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchBurrowsResponse_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_gophernet_v1_burrows_proto_enumTypes[0].Descriptor()
+}
+
+func (WatchBurrowsResponse_Kind) Type() protoreflect.EnumType {
+	return &file_gophernet_v1_burrows_proto_enumTypes[0]
+}
+
+func (x WatchBurrowsResponse_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchBurrowsResponse_Kind.Descriptor instead.
+func (WatchBurrowsResponse_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type Burrow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Depth in meters.
+	Depth float64 `protobuf:"fixed64,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	// Width in meters.
+	Width    float64 `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
+	Occupied bool    `protobuf:"varint,4,opt,name=occupied,proto3" json:"occupied,omitempty"`
+	// Age in minutes.
+	Age int32 `protobuf:"varint,5,opt,name=age,proto3" json:"age,omitempty"`
+	// Subject of the renter, when occupied.
+	RentedBy  string `protobuf:"bytes,6,opt,name=rented_by,json=rentedBy,proto3" json:"rented_by,omitempty"`
+	Collapsed bool   `protobuf:"varint,7,opt,name=collapsed,proto3" json:"collapsed,omitempty"`
+}
+
+func (x *Burrow) Reset() {
+	*x = Burrow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Burrow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Burrow) ProtoMessage() {}
+
+func (x *Burrow) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Burrow.ProtoReflect.Descriptor instead.
+func (*Burrow) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Burrow) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Burrow) GetDepth() float64 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *Burrow) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Burrow) GetOccupied() bool {
+	if x != nil {
+		return x.Occupied
+	}
+	return false
+}
+
+func (x *Burrow) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *Burrow) GetRentedBy() string {
+	if x != nil {
+		return x.RentedBy
+	}
+	return ""
+}
+
+func (x *Burrow) GetCollapsed() bool {
+	if x != nil {
+		return x.Collapsed
+	}
+	return false
+}
+
+type ListBurrowsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListBurrowsRequest) Reset() {
+	*x = ListBurrowsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBurrowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBurrowsRequest) ProtoMessage() {}
+
+func (x *ListBurrowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBurrowsRequest.ProtoReflect.Descriptor instead.
+func (*ListBurrowsRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{1}
+}
+
+type ListBurrowsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Burrows []*Burrow `protobuf:"bytes,1,rep,name=burrows,proto3" json:"burrows,omitempty"`
+}
+
+func (x *ListBurrowsResponse) Reset() {
+	*x = ListBurrowsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListBurrowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBurrowsResponse) ProtoMessage() {}
+
+func (x *ListBurrowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBurrowsResponse.ProtoReflect.Descriptor instead.
+func (*ListBurrowsResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListBurrowsResponse) GetBurrows() []*Burrow {
+	if x != nil {
+		return x.Burrows
+	}
+	return nil
+}
+
+type GetBurrowRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *GetBurrowRequest) Reset() {
+	*x = GetBurrowRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBurrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBurrowRequest) ProtoMessage() {}
+
+func (x *GetBurrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBurrowRequest.ProtoReflect.Descriptor instead.
+func (*GetBurrowRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBurrowRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetBurrowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Burrow *Burrow `protobuf:"bytes,1,opt,name=burrow,proto3" json:"burrow,omitempty"`
+}
+
+func (x *GetBurrowResponse) Reset() {
+	*x = GetBurrowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBurrowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBurrowResponse) ProtoMessage() {}
+
+func (x *GetBurrowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBurrowResponse.ProtoReflect.Descriptor instead.
+func (*GetBurrowResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetBurrowResponse) GetBurrow() *Burrow {
+	if x != nil {
+		return x.Burrow
+	}
+	return nil
+}
+
+type RentBurrowRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *RentBurrowRequest) Reset() {
+	*x = RentBurrowRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RentBurrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RentBurrowRequest) ProtoMessage() {}
+
+func (x *RentBurrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RentBurrowRequest.ProtoReflect.Descriptor instead.
+func (*RentBurrowRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RentBurrowRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type RentBurrowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Burrow *Burrow `protobuf:"bytes,1,opt,name=burrow,proto3" json:"burrow,omitempty"`
+}
+
+func (x *RentBurrowResponse) Reset() {
+	*x = RentBurrowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RentBurrowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RentBurrowResponse) ProtoMessage() {}
+
+func (x *RentBurrowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RentBurrowResponse.ProtoReflect.Descriptor instead.
+func (*RentBurrowResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RentBurrowResponse) GetBurrow() *Burrow {
+	if x != nil {
+		return x.Burrow
+	}
+	return nil
+}
+
+type ReleaseBurrowRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *ReleaseBurrowRequest) Reset() {
+	*x = ReleaseBurrowRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReleaseBurrowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseBurrowRequest) ProtoMessage() {}
+
+func (x *ReleaseBurrowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseBurrowRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseBurrowRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ReleaseBurrowRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ReleaseBurrowResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Burrow *Burrow `protobuf:"bytes,1,opt,name=burrow,proto3" json:"burrow,omitempty"`
+}
+
+func (x *ReleaseBurrowResponse) Reset() {
+	*x = ReleaseBurrowResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReleaseBurrowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseBurrowResponse) ProtoMessage() {}
+
+func (x *ReleaseBurrowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseBurrowResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseBurrowResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReleaseBurrowResponse) GetBurrow() *Burrow {
+	if x != nil {
+		return x.Burrow
+	}
+	return nil
+}
+
+type GetReportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetReportRequest) Reset() {
+	*x = GetReportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReportRequest) ProtoMessage() {}
+
+func (x *GetReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReportRequest.ProtoReflect.Descriptor instead.
+func (*GetReportRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{9}
+}
+
+type GetReportResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Report string `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+}
+
+func (x *GetReportResponse) Reset() {
+	*x = GetReportResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReportResponse) ProtoMessage() {}
+
+func (x *GetReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReportResponse.ProtoReflect.Descriptor instead.
+func (*GetReportResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetReportResponse) GetReport() string {
+	if x != nil {
+		return x.Report
+	}
+	return ""
+}
+
+type WatchBurrowsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchBurrowsRequest) Reset() {
+	*x = WatchBurrowsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchBurrowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBurrowsRequest) ProtoMessage() {}
+
+func (x *WatchBurrowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBurrowsRequest.ProtoReflect.Descriptor instead.
+func (*WatchBurrowsRequest) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{11}
+}
+
+type WatchBurrowsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind   WatchBurrowsResponse_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=gophernet.v1.WatchBurrowsResponse_Kind" json:"kind,omitempty"`
+	Burrow *Burrow                   `protobuf:"bytes,2,opt,name=burrow,proto3" json:"burrow,omitempty"`
+}
+
+func (x *WatchBurrowsResponse) Reset() {
+	*x = WatchBurrowsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gophernet_v1_burrows_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchBurrowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchBurrowsResponse) ProtoMessage() {}
+
+func (x *WatchBurrowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gophernet_v1_burrows_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchBurrowsResponse.ProtoReflect.Descriptor instead.
+func (*WatchBurrowsResponse) Descriptor() ([]byte, []int) {
+	return file_gophernet_v1_burrows_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchBurrowsResponse) GetKind() WatchBurrowsResponse_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return WatchBurrowsResponse_KIND_UNSPECIFIED
+}
+
+func (x *WatchBurrowsResponse) GetBurrow() *Burrow {
+	if x != nil {
+		return x.Burrow
+	}
+	return nil
+}
+
+var File_gophernet_v1_burrows_proto protoreflect.FileDescriptor
+
+var file_gophernet_v1_burrows_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2f, 0x76, 0x31, 0x2f, 0x62,
+	0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x67, 0x6f,
+	0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x22, 0xb1, 0x01, 0x0a, 0x06, 0x42,
+	0x75, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x70,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x64, 0x65, 0x70, 0x74, 0x68, 0x12,
+	0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
+	0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x63, 0x63, 0x75, 0x70, 0x69, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6f, 0x63, 0x63, 0x75, 0x70, 0x69, 0x65,
+	0x64, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03,
+	0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x6e, 0x74, 0x65, 0x64, 0x42, 0x79,
+	0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6c, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x22, 0x14,
+	0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x22, 0x45, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x62,
+	0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67,
+	0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x52, 0x07, 0x62, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x22, 0x26, 0x0a, 0x10, 0x47,
+	0x65, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x22, 0x41, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x06,
+	0x62, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x22, 0x27, 0x0a, 0x11, 0x52, 0x65, 0x6e, 0x74, 0x42, 0x75,
+	0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22,
+	0x42, 0x0a, 0x12, 0x52, 0x65, 0x6e, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x06, 0x62, 0x75, 0x72,
+	0x72, 0x6f, 0x77, 0x22, 0x2a, 0x0a, 0x14, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x42, 0x75,
+	0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22,
+	0x45, 0x0a, 0x15, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x06,
+	0x62, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2b, 0x0a, 0x11, 0x47, 0x65,
+	0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x22, 0x15, 0x0a, 0x13, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xc4,
+	0x01, 0x0a, 0x14, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x27, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04,
+	0x6b, 0x69, 0x6e, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x62, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x06, 0x62, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x22, 0x41, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x10, 0x4b, 0x49,
+	0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x11, 0x0a, 0x0d, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f,
+	0x54, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x48, 0x41, 0x4e,
+	0x47, 0x45, 0x44, 0x10, 0x02, 0x32, 0x83, 0x04, 0x0a, 0x0d, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x52, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x42,
+	0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x20, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e,
+	0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x47,
+	0x65, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f,
+	0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f,
+	0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0a, 0x52, 0x65, 0x6e,
+	0x74, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x1f, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72,
+	0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6e, 0x74, 0x42, 0x75, 0x72, 0x72, 0x6f,
+	0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65,
+	0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6e, 0x74, 0x42, 0x75, 0x72, 0x72,
+	0x6f, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x58, 0x0a, 0x0d, 0x52, 0x65,
+	0x6c, 0x65, 0x61, 0x73, 0x65, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x12, 0x22, 0x2e, 0x67, 0x6f,
+	0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x23, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x12, 0x1e, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x57, 0x0a, 0x0c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x75, 0x72, 0x72, 0x6f,
+	0x77, 0x73, 0x12, 0x21, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2e, 0x76,
+	0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72, 0x6e, 0x65,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x42, 0x75, 0x72, 0x72, 0x6f, 0x77,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x48, 0x5a, 0x46, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x61, 0x72, 0x63, 0x6f, 0x64,
+	0x64, 0x32, 0x33, 0x2f, 0x67, 0x6f, 0x70, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x6f,
+	0x70, 0x68, 0x65, 0x72, 0x6e, 0x65, 0x74, 0x76, 0x31, 0x3b, 0x67, 0x6f, 0x70, 0x68, 0x65, 0x72,
+	0x6e, 0x65, 0x74, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gophernet_v1_burrows_proto_rawDescOnce sync.Once
+	file_gophernet_v1_burrows_proto_rawDescData = file_gophernet_v1_burrows_proto_rawDesc
+)
+
+func file_gophernet_v1_burrows_proto_rawDescGZIP() []byte {
+	file_gophernet_v1_burrows_proto_rawDescOnce.Do(func() {
+		file_gophernet_v1_burrows_proto_rawDescData = protoimpl.X.CompressGZIP(file_gophernet_v1_burrows_proto_rawDescData)
+	})
+	return file_gophernet_v1_burrows_proto_rawDescData
+}
+
+var file_gophernet_v1_burrows_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gophernet_v1_burrows_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_gophernet_v1_burrows_proto_goTypes = []interface{}{
+	(WatchBurrowsResponse_Kind)(0), // 0: gophernet.v1.WatchBurrowsResponse.Kind
+	(*Burrow)(nil),                 // 1: gophernet.v1.Burrow
+	(*ListBurrowsRequest)(nil),     // 2: gophernet.v1.ListBurrowsRequest
+	(*ListBurrowsResponse)(nil),    // 3: gophernet.v1.ListBurrowsResponse
+	(*GetBurrowRequest)(nil),       // 4: gophernet.v1.GetBurrowRequest
+	(*GetBurrowResponse)(nil),      // 5: gophernet.v1.GetBurrowResponse
+	(*RentBurrowRequest)(nil),      // 6: gophernet.v1.RentBurrowRequest
+	(*RentBurrowResponse)(nil),     // 7: gophernet.v1.RentBurrowResponse
+	(*ReleaseBurrowRequest)(nil),   // 8: gophernet.v1.ReleaseBurrowRequest
+	(*ReleaseBurrowResponse)(nil),  // 9: gophernet.v1.ReleaseBurrowResponse
+	(*GetReportRequest)(nil),       // 10: gophernet.v1.GetReportRequest
+	(*GetReportResponse)(nil),      // 11: gophernet.v1.GetReportResponse
+	(*WatchBurrowsRequest)(nil),    // 12: gophernet.v1.WatchBurrowsRequest
+	(*WatchBurrowsResponse)(nil),   // 13: gophernet.v1.WatchBurrowsResponse
+}
+var file_gophernet_v1_burrows_proto_depIdxs = []int32{
+	1,  // 0: gophernet.v1.ListBurrowsResponse.burrows:type_name -> gophernet.v1.Burrow
+	1,  // 1: gophernet.v1.GetBurrowResponse.burrow:type_name -> gophernet.v1.Burrow
+	1,  // 2: gophernet.v1.RentBurrowResponse.burrow:type_name -> gophernet.v1.Burrow
+	1,  // 3: gophernet.v1.ReleaseBurrowResponse.burrow:type_name -> gophernet.v1.Burrow
+	0,  // 4: gophernet.v1.WatchBurrowsResponse.kind:type_name -> gophernet.v1.WatchBurrowsResponse.Kind
+	1,  // 5: gophernet.v1.WatchBurrowsResponse.burrow:type_name -> gophernet.v1.Burrow
+	2,  // 6: gophernet.v1.BurrowService.ListBurrows:input_type -> gophernet.v1.ListBurrowsRequest
+	4,  // 7: gophernet.v1.BurrowService.GetBurrow:input_type -> gophernet.v1.GetBurrowRequest
+	6,  // 8: gophernet.v1.BurrowService.RentBurrow:input_type -> gophernet.v1.RentBurrowRequest
+	8,  // 9: gophernet.v1.BurrowService.ReleaseBurrow:input_type -> gophernet.v1.ReleaseBurrowRequest
+	10, // 10: gophernet.v1.BurrowService.GetReport:input_type -> gophernet.v1.GetReportRequest
+	12, // 11: gophernet.v1.BurrowService.WatchBurrows:input_type -> gophernet.v1.WatchBurrowsRequest
+	3,  // 12: gophernet.v1.BurrowService.ListBurrows:output_type -> gophernet.v1.ListBurrowsResponse
+	5,  // 13: gophernet.v1.BurrowService.GetBurrow:output_type -> gophernet.v1.GetBurrowResponse
+	7,  // 14: gophernet.v1.BurrowService.RentBurrow:output_type -> gophernet.v1.RentBurrowResponse
+	9,  // 15: gophernet.v1.BurrowService.ReleaseBurrow:output_type -> gophernet.v1.ReleaseBurrowResponse
+	11, // 16: gophernet.v1.BurrowService.GetReport:output_type -> gophernet.v1.GetReportResponse
+	13, // 17: gophernet.v1.BurrowService.WatchBurrows:output_type -> gophernet.v1.WatchBurrowsResponse
+	12, // [12:18] is the sub-list for method output_type
+	6,  // [6:12] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_gophernet_v1_burrows_proto_init() }
+func file_gophernet_v1_burrows_proto_init() {
+	if File_gophernet_v1_burrows_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gophernet_v1_burrows_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Burrow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListBurrowsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListBurrowsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBurrowRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBurrowResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RentBurrowRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RentBurrowResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReleaseBurrowRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReleaseBurrowResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetReportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetReportResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchBurrowsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gophernet_v1_burrows_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchBurrowsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gophernet_v1_burrows_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gophernet_v1_burrows_proto_goTypes,
+		DependencyIndexes: file_gophernet_v1_burrows_proto_depIdxs,
+		EnumInfos:         file_gophernet_v1_burrows_proto_enumTypes,
+		MessageInfos:      file_gophernet_v1_burrows_proto_msgTypes,
+	}.Build()
+	File_gophernet_v1_burrows_proto = out.File
+	file_gophernet_v1_burrows_proto_rawDesc = nil
+	file_gophernet_v1_burrows_proto_goTypes = nil
+	file_gophernet_v1_burrows_proto_depIdxs = nil
+}
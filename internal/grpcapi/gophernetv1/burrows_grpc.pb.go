@@ -0,0 +1,334 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.27.1
+// source: gophernet/v1/burrows.proto
+
+package gophernetv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	BurrowService_ListBurrows_FullMethodName   = "/gophernet.v1.BurrowService/ListBurrows"
+	BurrowService_GetBurrow_FullMethodName     = "/gophernet.v1.BurrowService/GetBurrow"
+	BurrowService_RentBurrow_FullMethodName    = "/gophernet.v1.BurrowService/RentBurrow"
+	BurrowService_ReleaseBurrow_FullMethodName = "/gophernet.v1.BurrowService/ReleaseBurrow"
+	BurrowService_GetReport_FullMethodName     = "/gophernet.v1.BurrowService/GetReport"
+	BurrowService_WatchBurrows_FullMethodName  = "/gophernet.v1.BurrowService/WatchBurrows"
+)
+
+// BurrowServiceClient is the client API for BurrowService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type BurrowServiceClient interface {
+	// ListBurrows returns every burrow.
+	ListBurrows(ctx context.Context, in *ListBurrowsRequest, opts ...grpc.CallOption) (*ListBurrowsResponse, error)
+	// GetBurrow returns a burrow by name.
+	GetBurrow(ctx context.Context, in *GetBurrowRequest, opts ...grpc.CallOption) (*GetBurrowResponse, error)
+	// RentBurrow rents an available burrow.
+	RentBurrow(ctx context.Context, in *RentBurrowRequest, opts ...grpc.CallOption) (*RentBurrowResponse, error)
+	// ReleaseBurrow frees a rented burrow.
+	ReleaseBurrow(ctx context.Context, in *ReleaseBurrowRequest, opts ...grpc.CallOption) (*ReleaseBurrowResponse, error)
+	// GetReport generates the burrows report.
+	GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error)
+	// WatchBurrows streams a snapshot of every burrow, then every change.
+	WatchBurrows(ctx context.Context, in *WatchBurrowsRequest, opts ...grpc.CallOption) (BurrowService_WatchBurrowsClient, error)
+}
+
+type burrowServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBurrowServiceClient(cc grpc.ClientConnInterface) BurrowServiceClient {
+	return &burrowServiceClient{cc}
+}
+
+func (c *burrowServiceClient) ListBurrows(ctx context.Context, in *ListBurrowsRequest, opts ...grpc.CallOption) (*ListBurrowsResponse, error) {
+	out := new(ListBurrowsResponse)
+	err := c.cc.Invoke(ctx, BurrowService_ListBurrows_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *burrowServiceClient) GetBurrow(ctx context.Context, in *GetBurrowRequest, opts ...grpc.CallOption) (*GetBurrowResponse, error) {
+	out := new(GetBurrowResponse)
+	err := c.cc.Invoke(ctx, BurrowService_GetBurrow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *burrowServiceClient) RentBurrow(ctx context.Context, in *RentBurrowRequest, opts ...grpc.CallOption) (*RentBurrowResponse, error) {
+	out := new(RentBurrowResponse)
+	err := c.cc.Invoke(ctx, BurrowService_RentBurrow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *burrowServiceClient) ReleaseBurrow(ctx context.Context, in *ReleaseBurrowRequest, opts ...grpc.CallOption) (*ReleaseBurrowResponse, error) {
+	out := new(ReleaseBurrowResponse)
+	err := c.cc.Invoke(ctx, BurrowService_ReleaseBurrow_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *burrowServiceClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (*GetReportResponse, error) {
+	out := new(GetReportResponse)
+	err := c.cc.Invoke(ctx, BurrowService_GetReport_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *burrowServiceClient) WatchBurrows(ctx context.Context, in *WatchBurrowsRequest, opts ...grpc.CallOption) (BurrowService_WatchBurrowsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BurrowService_ServiceDesc.Streams[0], BurrowService_WatchBurrows_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &burrowServiceWatchBurrowsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BurrowService_WatchBurrowsClient interface {
+	Recv() (*WatchBurrowsResponse, error)
+	grpc.ClientStream
+}
+
+type burrowServiceWatchBurrowsClient struct {
+	grpc.ClientStream
+}
+
+func (x *burrowServiceWatchBurrowsClient) Recv() (*WatchBurrowsResponse, error) {
+	m := new(WatchBurrowsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BurrowServiceServer is the server API for BurrowService service.
+// All implementations must embed UnimplementedBurrowServiceServer
+// for forward compatibility
+type BurrowServiceServer interface {
+	// ListBurrows returns every burrow.
+	ListBurrows(context.Context, *ListBurrowsRequest) (*ListBurrowsResponse, error)
+	// GetBurrow returns a burrow by name.
+	GetBurrow(context.Context, *GetBurrowRequest) (*GetBurrowResponse, error)
+	// RentBurrow rents an available burrow.
+	RentBurrow(context.Context, *RentBurrowRequest) (*RentBurrowResponse, error)
+	// ReleaseBurrow frees a rented burrow.
+	ReleaseBurrow(context.Context, *ReleaseBurrowRequest) (*ReleaseBurrowResponse, error)
+	// GetReport generates the burrows report.
+	GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error)
+	// WatchBurrows streams a snapshot of every burrow, then every change.
+	WatchBurrows(*WatchBurrowsRequest, BurrowService_WatchBurrowsServer) error
+	mustEmbedUnimplementedBurrowServiceServer()
+}
+
+// UnimplementedBurrowServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedBurrowServiceServer struct {
+}
+
+func (UnimplementedBurrowServiceServer) ListBurrows(context.Context, *ListBurrowsRequest) (*ListBurrowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBurrows not implemented")
+}
+func (UnimplementedBurrowServiceServer) GetBurrow(context.Context, *GetBurrowRequest) (*GetBurrowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBurrow not implemented")
+}
+func (UnimplementedBurrowServiceServer) RentBurrow(context.Context, *RentBurrowRequest) (*RentBurrowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RentBurrow not implemented")
+}
+func (UnimplementedBurrowServiceServer) ReleaseBurrow(context.Context, *ReleaseBurrowRequest) (*ReleaseBurrowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseBurrow not implemented")
+}
+func (UnimplementedBurrowServiceServer) GetReport(context.Context, *GetReportRequest) (*GetReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReport not implemented")
+}
+func (UnimplementedBurrowServiceServer) WatchBurrows(*WatchBurrowsRequest, BurrowService_WatchBurrowsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBurrows not implemented")
+}
+func (UnimplementedBurrowServiceServer) mustEmbedUnimplementedBurrowServiceServer() {}
+
+// UnsafeBurrowServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BurrowServiceServer will
+// result in compilation errors.
+type UnsafeBurrowServiceServer interface {
+	mustEmbedUnimplementedBurrowServiceServer()
+}
+
+func RegisterBurrowServiceServer(s grpc.ServiceRegistrar, srv BurrowServiceServer) {
+	s.RegisterService(&BurrowService_ServiceDesc, srv)
+}
+
+func _BurrowService_ListBurrows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBurrowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BurrowServiceServer).ListBurrows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BurrowService_ListBurrows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BurrowServiceServer).ListBurrows(ctx, req.(*ListBurrowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BurrowService_GetBurrow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBurrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BurrowServiceServer).GetBurrow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BurrowService_GetBurrow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BurrowServiceServer).GetBurrow(ctx, req.(*GetBurrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BurrowService_RentBurrow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RentBurrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BurrowServiceServer).RentBurrow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BurrowService_RentBurrow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BurrowServiceServer).RentBurrow(ctx, req.(*RentBurrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BurrowService_ReleaseBurrow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseBurrowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BurrowServiceServer).ReleaseBurrow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BurrowService_ReleaseBurrow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BurrowServiceServer).ReleaseBurrow(ctx, req.(*ReleaseBurrowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BurrowService_GetReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BurrowServiceServer).GetReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BurrowService_GetReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BurrowServiceServer).GetReport(ctx, req.(*GetReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BurrowService_WatchBurrows_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBurrowsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BurrowServiceServer).WatchBurrows(m, &burrowServiceWatchBurrowsServer{stream})
+}
+
+type BurrowService_WatchBurrowsServer interface {
+	Send(*WatchBurrowsResponse) error
+	grpc.ServerStream
+}
+
+type burrowServiceWatchBurrowsServer struct {
+	grpc.ServerStream
+}
+
+func (x *burrowServiceWatchBurrowsServer) Send(m *WatchBurrowsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// BurrowService_ServiceDesc is the grpc.ServiceDesc for BurrowService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BurrowService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gophernet.v1.BurrowService",
+	HandlerType: (*BurrowServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBurrows",
+			Handler:    _BurrowService_ListBurrows_Handler,
+		},
+		{
+			MethodName: "GetBurrow",
+			Handler:    _BurrowService_GetBurrow_Handler,
+		},
+		{
+			MethodName: "RentBurrow",
+			Handler:    _BurrowService_RentBurrow_Handler,
+		},
+		{
+			MethodName: "ReleaseBurrow",
+			Handler:    _BurrowService_ReleaseBurrow_Handler,
+		},
+		{
+			MethodName: "GetReport",
+			Handler:    _BurrowService_GetReport_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBurrows",
+			Handler:       _BurrowService_WatchBurrows_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gophernet/v1/burrows.proto",
+}
@@ -0,0 +1,332 @@
+// Package grpcapi serves the BurrowService gRPC API defined in proto/gophernet/v1/burrows.proto.
+// The code in gophernetv1 is generated with "make proto".
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/grpcapi/gophernetv1"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details attached to the errors, whose reason is the
+// code of the domain error.
+const ErrorDomain = "gophernet"
+
+// maxNameLength bounds the length of the burrow names.
+const maxNameLength = 100
+
+// watchBuffer is the number of burrow changes buffered for a watcher before changes are dropped.
+const watchBuffer = 64
+
+// codeByError maps the codes of the domain errors to their gRPC code.
+var codeByError = map[string]codes.Code{
+	models.ErrBurrowNotFound.Code:      codes.NotFound,
+	models.ErrBurrowUnavailable.Code:   codes.FailedPrecondition,
+	models.ErrBurrowCollapsed.Code:     codes.FailedPrecondition,
+	models.ErrBurrowAlreadyExists.Code: codes.AlreadyExists,
+	models.ErrBurrowNotRented.Code:     codes.FailedPrecondition,
+	models.ErrForbidden.Code:           codes.PermissionDenied,
+	models.ErrInvalidBurrow.Code:       codes.InvalidArgument,
+	models.ErrBurrowHeld.Code:          codes.FailedPrecondition,
+	models.ErrHoldNotFound.Code:        codes.NotFound,
+	models.ErrHoldExpired.Code:         codes.FailedPrecondition,
+	models.ErrBurrowAvailable.Code:     codes.FailedPrecondition,
+	models.ErrAlreadyWaitlisted.Code:   codes.AlreadyExists,
+	models.ErrBurrowAlreadyTaken.Code:  codes.FailedPrecondition,
+	models.ErrNotWaitlisted.Code:       codes.NotFound,
+	models.ErrRentalNotFound.Code:      codes.NotFound,
+	models.ErrInvalidLedgerEntry.Code:  codes.InvalidArgument,
+	models.ErrRenterNotFound.Code:      codes.NotFound,
+	models.ErrRenterAlreadyExists.Code: codes.AlreadyExists,
+	models.ErrInvalidRenter.Code:       codes.InvalidArgument,
+	models.ErrRenterOverQuota.Code:     codes.ResourceExhausted,
+	models.ErrRenterHasRentals.Code:    codes.FailedPrecondition,
+	models.ErrPricingUnavailable.Code:  codes.Unavailable,
+}
+
+// Server serves the BurrowService on top of a GopherService.
+type Server struct {
+	gophernetv1.UnimplementedBurrowServiceServer
+
+	service       services.GopherService
+	bus           *events.Bus
+	authenticator *auth.Authenticator
+	store         *config.Store
+	limiters      *rateLimiters
+	grpc          *grpc.Server
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewServer creates the gRPC server. Burrow changes are read from bus. When tlsConfig is not nil,
+// the server only accepts TLS connections. Calls are authenticated by authenticator, and
+// authorized and rate limited like the REST endpoint of their method in the configuration of
+// store, measured with clk. When authenticator is nil, callers are the anonymous principal.
+func NewServer(service services.GopherService, bus *events.Bus, tlsConfig *tls.Config, authenticator *auth.Authenticator, clk clock.Clock, store *config.Store) *Server {
+	s := &Server{
+		service:       service,
+		bus:           bus,
+		authenticator: authenticator,
+		store:         store,
+		limiters:      newRateLimiters(clk),
+		done:          make(chan struct{}),
+	}
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(recoveryUnaryInterceptor, s.authUnaryInterceptor),
+		grpc.ChainStreamInterceptor(recoveryStreamInterceptor, s.authStreamInterceptor),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s.grpc = grpc.NewServer(options...)
+	gophernetv1.RegisterBurrowServiceServer(s.grpc, s)
+
+	return s
+}
+
+// Serve accepts connections on the listener until the server is shut down.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// Shutdown ends the watch streams and stops the server gracefully, waiting for the pending calls.
+// If ctx is done first, the remaining connections are closed.
+func (s *Server) Shutdown(ctx context.Context) {
+	s.stopOnce.Do(func() { close(s.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+		<-stopped
+	}
+}
+
+func (s *Server) ListBurrows(ctx context.Context, _ *gophernetv1.ListBurrowsRequest) (*gophernetv1.ListBurrowsResponse, error) {
+	burrows := s.service.GetAllBurrows()
+
+	response := &gophernetv1.ListBurrowsResponse{Burrows: make([]*gophernetv1.Burrow, 0, len(burrows))}
+	for _, burrow := range burrows {
+		response.Burrows = append(response.Burrows, toProto(burrow))
+	}
+
+	return response, nil
+}
+
+func (s *Server) GetBurrow(ctx context.Context, req *gophernetv1.GetBurrowRequest) (*gophernetv1.GetBurrowResponse, error) {
+	if err := validateName(req.GetName()); err != nil {
+		return nil, err
+	}
+
+	burrow, err := s.service.GetBurrow(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &gophernetv1.GetBurrowResponse{Burrow: toProto(burrow)}, nil
+}
+
+func (s *Server) RentBurrow(ctx context.Context, req *gophernetv1.RentBurrowRequest) (*gophernetv1.RentBurrowResponse, error) {
+	if err := validateName(req.GetName()); err != nil {
+		return nil, err
+	}
+
+	if err := s.service.RentBurrow(req.GetName(), callerFromContext(ctx).Subject); err != nil {
+		return nil, toStatus(err)
+	}
+
+	burrow, err := s.service.GetBurrow(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &gophernetv1.RentBurrowResponse{Burrow: toProto(burrow)}, nil
+}
+
+// ReleaseBurrow frees a rented burrow. Callers without the manager or admin role can only release
+// the burrows they rent.
+func (s *Server) ReleaseBurrow(ctx context.Context, req *gophernetv1.ReleaseBurrowRequest) (*gophernetv1.ReleaseBurrowResponse, error) {
+	if err := validateName(req.GetName()); err != nil {
+		return nil, err
+	}
+
+	caller := callerFromContext(ctx)
+	renter := caller.Subject
+	if caller.HasAnyRole(auth.RoleManager, auth.RoleAdmin) {
+		renter = ""
+	}
+
+	if err := s.service.ReleaseBurrow(req.GetName(), renter); err != nil {
+		return nil, toStatus(err)
+	}
+
+	burrow, err := s.service.GetBurrow(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &gophernetv1.ReleaseBurrowResponse{Burrow: toProto(burrow)}, nil
+}
+
+func (s *Server) GetReport(ctx context.Context, _ *gophernetv1.GetReportRequest) (*gophernetv1.GetReportResponse, error) {
+	report, err := s.service.GenerateReport()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &gophernetv1.GetReportResponse{Report: report}, nil
+}
+
+// WatchBurrows sends a snapshot of every burrow, then every burrow change. A new snapshot is sent
+// after every periodic update. Changes are dropped for watchers too slow to keep up.
+func (s *Server) WatchBurrows(_ *gophernetv1.WatchBurrowsRequest, stream gophernetv1.BurrowService_WatchBurrowsServer) error {
+	// Subscribe before taking the snapshot, so that no change is missed in between.
+	changes, unsubscribe := s.bus.Subscribe(watchBuffer)
+	defer unsubscribe()
+
+	if err := s.sendSnapshot(stream); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "the server is shutting down")
+		case event, ok := <-changes:
+			if !ok {
+				return nil
+			}
+
+			var err error
+			switch event.Type {
+			case events.BurrowChanged:
+				if burrow, ok := event.Data.(*models.Burrow); ok {
+					err = stream.Send(&gophernetv1.WatchBurrowsResponse{
+						Kind:   gophernetv1.WatchBurrowsResponse_KIND_CHANGED,
+						Burrow: toProto(burrow),
+					})
+				}
+			case events.BurrowsUpdated:
+				err = s.sendSnapshot(stream)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) sendSnapshot(stream gophernetv1.BurrowService_WatchBurrowsServer) error {
+	for _, burrow := range s.service.GetAllBurrows() {
+		err := stream.Send(&gophernetv1.WatchBurrowsResponse{
+			Kind:   gophernetv1.WatchBurrowsResponse_KIND_SNAPSHOT,
+			Burrow: toProto(burrow),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toProto(burrow *models.Burrow) *gophernetv1.Burrow {
+	return &gophernetv1.Burrow{
+		Name:      burrow.Name,
+		Depth:     burrow.Depth,
+		Width:     burrow.Width,
+		Occupied:  burrow.Occupied,
+		Age:       int32(burrow.Age),
+		RentedBy:  burrow.RentedBy,
+		Collapsed: burrow.HasCollapsed(),
+	}
+}
+
+func validateName(name string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if len(name) > maxNameLength {
+		return status.Errorf(codes.InvalidArgument, "name must be at most %d characters", maxNameLength)
+	}
+
+	return nil
+}
+
+// toStatus converts an error into a gRPC status. Domain errors carry their code as the reason of
+// an ErrorInfo detail; other errors are reported as internal errors without their message.
+func toStatus(err error) error {
+	var domainErr *models.Error
+	if !errors.As(err, &domainErr) {
+		logmgr.GetLogger().LogError(context.Background(), "Unexpected error serving a gRPC call", err)
+		return status.Error(codes.Internal, "an unexpected error occurred")
+	}
+
+	code, ok := codeByError[domainErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	st, detailErr := status.New(code, err.Error()).WithDetails(&errdetails.ErrorInfo{Reason: domainErr.Code, Domain: ErrorDomain})
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+
+	return st.Err()
+}
+
+func recoveryUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (response interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recoveredError(ctx, info.FullMethod, recovered)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoveryStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recoveredError(stream.Context(), info.FullMethod, recovered)
+		}
+	}()
+
+	return handler(srv, stream)
+}
+
+func recoveredError(ctx context.Context, method string, recovered interface{}) error {
+	logmgr.GetLogger().LogError(ctx, fmt.Sprintf("panic serving %s: %v\n%s", method, recovered, debug.Stack()))
+
+	return status.Error(codes.Internal, "an unexpected error occurred")
+}
@@ -0,0 +1,256 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/marcodd23/gopernet/internal/auth"
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/grpcapi"
+	"github.com/marcodd23/gopernet/internal/grpcapi/gophernetv1"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// Credentials of the test API keys.
+const (
+	adminKey   = "admin-key"
+	renterKey  = "renter-key"
+	renter2Key = "renter-2-key"
+)
+
+func testConfig() *config.ServiceConfig {
+	everyone := []string{auth.RoleRenter, auth.RoleManager, auth.RoleAdmin}
+	return &config.ServiceConfig{
+		Rest: config.Rest{Endpoints: map[string]config.Endpoint{
+			"get-burrows":    {Roles: everyone},
+			"get-burrow":     {Roles: everyone},
+			"rent-burrow":    {Roles: everyone},
+			"release-burrow": {Roles: everyone},
+			"get-report":     {Roles: []string{auth.RoleManager, auth.RoleAdmin}},
+			"ws":             {Roles: everyone},
+		}},
+		Auth: config.Auth{Enabled: true, APIKeys: []config.APIKey{
+			{Key: adminKey, Subject: "admin", Roles: []string{auth.RoleAdmin}},
+			{Key: renterKey, Subject: "renter-1", Roles: []string{auth.RoleRenter}},
+			{Key: renter2Key, Subject: "renter-2", Roles: []string{auth.RoleRenter}},
+		}},
+	}
+}
+
+// withKey returns a context sending the API key.
+func withKey(ctx context.Context, key string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcapi.APIKeyMetadata, key)
+}
+
+func startServer(t *testing.T) (gophernetv1.BurrowServiceClient, *services.DefaultBurrowService, *grpcapi.Server) {
+	return startServerWith(t, testConfig())
+}
+
+func startServerWith(t *testing.T, cfg *config.ServiceConfig) (gophernetv1.BurrowServiceClient, *services.DefaultBurrowService, *grpcapi.Server) {
+	repo := repository.NewMemoryRepository("", "")
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 3.0, Width: 1.3, Age: 50}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Collapsed", Depth: 1.0, Width: 1.0, Age: 25 * 24 * 60}))
+
+	bus := events.NewBus()
+	service := services.NewGopherNetService(repo)
+	service.SetEventBus(bus)
+
+	authenticator, err := auth.NewAuthenticator(cfg.Auth)
+	require.NoError(t, err)
+
+	server := grpcapi.NewServer(service, bus, nil, authenticator, clock.New(), config.NewStore(cfg))
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return gophernetv1.NewBurrowServiceClient(conn), service, server
+}
+
+// errorReason returns the gRPC code and the domain error code of err.
+func errorReason(t *testing.T, err error) (codes.Code, string) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info.GetReason()
+		}
+	}
+
+	return st.Code(), ""
+}
+
+func TestServer_Burrows(t *testing.T) {
+	client, _, _ := startServer(t)
+	ctx := withKey(context.Background(), renterKey)
+
+	list, err := client.ListBurrows(ctx, &gophernetv1.ListBurrowsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetBurrows(), 2)
+	assert.Equal(t, "The Molehole", list.GetBurrows()[0].GetName())
+	assert.True(t, list.GetBurrows()[1].GetCollapsed())
+
+	rented, err := client.RentBurrow(ctx, &gophernetv1.RentBurrowRequest{Name: "The Molehole"})
+	require.NoError(t, err)
+	assert.True(t, rented.GetBurrow().GetOccupied())
+	assert.Equal(t, "renter-1", rented.GetBurrow().GetRentedBy())
+
+	code, reason := errorReason(t, errorOf(client.RentBurrow(ctx, &gophernetv1.RentBurrowRequest{Name: "The Molehole"})))
+	assert.Equal(t, codes.FailedPrecondition, code)
+	assert.Equal(t, models.ErrBurrowUnavailable.Code, reason)
+
+	released, err := client.ReleaseBurrow(ctx, &gophernetv1.ReleaseBurrowRequest{Name: "The Molehole"})
+	require.NoError(t, err)
+	assert.False(t, released.GetBurrow().GetOccupied())
+
+	code, reason = errorReason(t, errorOf(client.ReleaseBurrow(ctx, &gophernetv1.ReleaseBurrowRequest{Name: "The Molehole"})))
+	assert.Equal(t, codes.FailedPrecondition, code)
+	assert.Equal(t, models.ErrBurrowNotRented.Code, reason)
+
+	code, reason = errorReason(t, errorOf(client.GetBurrow(ctx, &gophernetv1.GetBurrowRequest{Name: "Nowhere"})))
+	assert.Equal(t, codes.NotFound, code)
+	assert.Equal(t, models.ErrBurrowNotFound.Code, reason)
+
+	code, _ = errorReason(t, errorOf(client.GetBurrow(ctx, &gophernetv1.GetBurrowRequest{})))
+	assert.Equal(t, codes.InvalidArgument, code)
+
+	report, err := client.GetReport(withKey(context.Background(), adminKey), &gophernetv1.GetReportRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, report.GetReport(), "GopherNet Burrow Report")
+}
+
+func TestServer_HeldBurrow(t *testing.T) {
+	client, service, _ := startServer(t)
+	_, err := service.HoldBurrow("The Molehole", "renter-2")
+	require.NoError(t, err)
+
+	code, reason := errorReason(t, errorOf(client.RentBurrow(withKey(context.Background(), renterKey), &gophernetv1.RentBurrowRequest{Name: "The Molehole"})))
+	assert.Equal(t, codes.FailedPrecondition, code)
+	assert.Equal(t, models.ErrBurrowHeld.Code, reason)
+
+	rented, err := client.RentBurrow(withKey(context.Background(), renter2Key), &gophernetv1.RentBurrowRequest{Name: "The Molehole"})
+	require.NoError(t, err)
+	assert.Equal(t, "renter-2", rented.GetBurrow().GetRentedBy())
+}
+
+func TestServer_RenterOverQuota(t *testing.T) {
+	client, service, _ := startServer(t)
+	require.NoError(t, service.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2}))
+	_, err := service.AddRenter(&models.Renter{ID: "renter-1", Name: "Renter", Contact: models.Contact{Channel: models.ContactEmail, Address: "renter@example.com"}, Limits: models.RenterLimits{MaxConcurrentBurrows: 1}})
+	require.NoError(t, err)
+	ctx := withKey(context.Background(), renterKey)
+
+	_, err = client.RentBurrow(ctx, &gophernetv1.RentBurrowRequest{Name: "The Molehole"})
+	require.NoError(t, err)
+	code, reason := errorReason(t, errorOf(client.RentBurrow(ctx, &gophernetv1.RentBurrowRequest{Name: "The Deep Den"})))
+	assert.Equal(t, codes.ResourceExhausted, code)
+	assert.Equal(t, models.ErrRenterOverQuota.Code, reason)
+}
+
+func errorOf[T any](_ T, err error) error {
+	return err
+}
+
+func TestServer_Authorization(t *testing.T) {
+	client, service, _ := startServer(t)
+	ctx := context.Background()
+
+	_, err := client.ListBurrows(ctx, &gophernetv1.ListBurrowsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.RentBurrow(withKey(ctx, "guess"), &gophernetv1.RentBurrowRequest{Name: "The Molehole"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.GetReport(withKey(ctx, renterKey), &gophernetv1.GetReportRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stream, err := client.WatchBurrows(ctx, &gophernetv1.WatchBurrowsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// Renters only release the burrows they rent, managers and admins release any.
+	require.NoError(t, service.RentBurrow("The Molehole", "renter-1"))
+	_, err = client.ReleaseBurrow(withKey(ctx, renter2Key), &gophernetv1.ReleaseBurrowRequest{Name: "The Molehole"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.ReleaseBurrow(withKey(ctx, adminKey), &gophernetv1.ReleaseBurrowRequest{Name: "The Molehole"})
+	assert.NoError(t, err)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	cfg := testConfig()
+	endpoint := cfg.Rest.Endpoints["get-burrows"]
+	endpoint.RateLimit = config.RateLimit{Requests: 1, Period: time.Minute}
+	cfg.Rest.Endpoints["get-burrows"] = endpoint
+	client, _, _ := startServerWith(t, cfg)
+
+	ctx := withKey(context.Background(), renterKey)
+	_, err := client.ListBurrows(ctx, &gophernetv1.ListBurrowsRequest{})
+	require.NoError(t, err)
+	_, err = client.ListBurrows(ctx, &gophernetv1.ListBurrowsRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Other callers have their own bucket.
+	_, err = client.ListBurrows(withKey(context.Background(), adminKey), &gophernetv1.ListBurrowsRequest{})
+	assert.NoError(t, err)
+}
+
+func TestServer_WatchBurrows(t *testing.T) {
+	client, service, server := startServer(t)
+	ctx, cancel := context.WithTimeout(withKey(context.Background(), renterKey), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchBurrows(ctx, &gophernetv1.WatchBurrowsRequest{})
+	require.NoError(t, err)
+
+	for _, name := range []string{"The Molehole", "Collapsed"} {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, gophernetv1.WatchBurrowsResponse_KIND_SNAPSHOT, event.GetKind())
+		assert.Equal(t, name, event.GetBurrow().GetName())
+	}
+
+	require.NoError(t, service.RentBurrow("The Molehole", "renter-1"))
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, gophernetv1.WatchBurrowsResponse_KIND_CHANGED, event.GetKind())
+	assert.Equal(t, "renter-1", event.GetBurrow().GetRentedBy())
+
+	service.UpdateBurrows()
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, gophernetv1.WatchBurrowsResponse_KIND_SNAPSHOT, event.GetKind())
+	assert.Equal(t, int32(51), event.GetBurrow().GetAge())
+
+	// Shutting down ends the open streams instead of waiting for them.
+	server.Shutdown(ctx)
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"sync"
+)
+
+// Check reports whether a component is ready, returning nil when it is.
+type Check func(ctx context.Context) error
+
+// CheckOK is the outcome of a passing check in a Report.
+const CheckOK = "ok"
+
+// Report is the outcome of running every registered Check.
+type Report struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Checker aggregates the readiness checks of the application components.
+type Checker struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+func NewChecker() *Checker {
+	return &Checker{checks: make(map[string]Check)}
+}
+
+// Register adds (or replaces) a named check.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+// Check runs all the registered checks.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Ready: true, Checks: make(map[string]string, len(checks))}
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			report.Ready = false
+			report.Checks[name] = err.Error()
+			continue
+		}
+		report.Checks[name] = CheckOK
+	}
+
+	return report
+}
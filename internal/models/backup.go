@@ -0,0 +1,9 @@
+package models
+
+// RestoreSummary counts what a restored backup holds.
+type RestoreSummary struct {
+	Burrows int `json:"burrows"`
+	Holds   int `json:"holds"`
+	Rentals int `json:"rentals"`
+	Renters int `json:"renters"`
+}
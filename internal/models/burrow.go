@@ -1,27 +1,76 @@
 package models
 
+import (
+	"math"
+	"sync/atomic"
+)
+
 type Burrow struct {
 	Name     string  `json:"name"`
 	Depth    float64 `json:"depth"`
 	Width    float64 `json:"width"`
 	Occupied bool    `json:"occupied"`
-	Age      int     `json:"age"` // in minutes
+	Age      int     `json:"age"`                // in minutes
+	RentedBy string  `json:"rentedBy,omitempty"` // subject of the current renter
+	Quote    *Quote  `json:"quote,omitempty"`    // price of the current rental
+	RentalID string  `json:"rentalId,omitempty"` // ID of the current rental
+}
+
+// Lifecycle holds the parameters of the growth and collapse of the burrows.
+type Lifecycle struct {
+	// GrowthRate is the share of its depth an occupied burrow deepens by every minute.
+	GrowthRate float64
+	// MinGrowth is the depth an occupied burrow without depth deepens by every minute.
+	MinGrowth float64
+	// CollapseAge is the age, in minutes, at which a burrow collapses.
+	CollapseAge int
+}
+
+// DefaultLifecycle is the lifecycle of the burrows until SetLifecycle is called.
+var DefaultLifecycle = Lifecycle{
+	GrowthRate:  0.009,
+	MinGrowth:   0.01,
+	CollapseAge: 25 * 24 * 60, // 25 days in minutes (25 * 24 * 60).
+}
+
+var lifecycle atomic.Pointer[Lifecycle]
+
+// SetLifecycle changes the lifecycle of every burrow.
+func SetLifecycle(l Lifecycle) {
+	lifecycle.Store(&l)
+}
+
+// CurrentLifecycle returns the lifecycle of the burrows.
+func CurrentLifecycle() Lifecycle {
+	if l := lifecycle.Load(); l != nil {
+		return *l
+	}
+
+	return DefaultLifecycle
 }
 
 // UpdateDepth increments the depth of the burrow if it's occupied.
 func (b *Burrow) UpdateDepth() {
+	l := CurrentLifecycle()
 	if b.Occupied {
 		if b.Depth == 0 {
-			b.Depth += 0.01 // Minimum increment for burrows with zero depth
+			b.Depth += l.MinGrowth // Minimum increment for burrows with zero depth
 		} else {
-			b.Depth += b.Depth * 0.009 // Percentage-based increase for non-zero depths
+			b.Depth += b.Depth * l.GrowthRate // Percentage-based increase for non-zero depths
 		}
 	}
 
 	b.Age += 1 // Age increases by 1 minute.
 }
 
+// Volume returns the volume of the burrow in cubic meters (cylindrical volume formula: V = pi * r^2 * h).
+func (b *Burrow) Volume() float64 {
+	radius := b.Width / 2
+
+	return math.Pi * radius * radius * b.Depth
+}
+
 // HasCollapsed checks if the burrow has collapsed based on its age.
 func (b *Burrow) HasCollapsed() bool {
-	return b.Age >= 25*24*60 // 25 days in minutes (25 * 24 * 60).
+	return b.Age >= CurrentLifecycle().CollapseAge
 }
@@ -0,0 +1,61 @@
+package models
+
+// Error is a domain error identified by a stable, machine readable code.
+// Callers can wrap it with more context and match it with errors.Is.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	// ErrBurrowNotFound is returned when no burrow has the requested name.
+	ErrBurrowNotFound = &Error{Code: "burrow_not_found", Message: "burrow not found"}
+	// ErrBurrowUnavailable is returned when renting a burrow that is already occupied.
+	ErrBurrowUnavailable = &Error{Code: "burrow_unavailable", Message: "burrow not available"}
+	// ErrBurrowCollapsed is returned when renting or quoting a burrow that has collapsed.
+	ErrBurrowCollapsed = &Error{Code: "burrow_collapsed", Message: "burrow has collapsed"}
+	// ErrBurrowAlreadyExists is returned when adding a burrow whose name is taken.
+	ErrBurrowAlreadyExists = &Error{Code: "burrow_already_exists", Message: "burrow already exists"}
+	// ErrBurrowNotRented is returned when releasing a burrow that is not occupied.
+	ErrBurrowNotRented = &Error{Code: "burrow_not_rented", Message: "burrow is not rented"}
+	// ErrForbidden is returned when releasing a burrow rented by another renter.
+	ErrForbidden = &Error{Code: "forbidden", Message: "only the renter of the burrow can release it"}
+	// ErrInvalidBurrow is returned when a burrow has invalid attributes.
+	ErrInvalidBurrow = &Error{Code: "invalid_burrow", Message: "invalid burrow"}
+	// ErrBurrowHeld is returned when renting or holding a burrow held by another renter.
+	ErrBurrowHeld = &Error{Code: "burrow_held", Message: "burrow is held by another renter"}
+	// ErrHoldNotFound is returned when no hold has the requested ID.
+	ErrHoldNotFound = &Error{Code: "hold_not_found", Message: "hold not found"}
+	// ErrHoldExpired is returned when confirming a hold that has expired.
+	ErrHoldExpired = &Error{Code: "hold_expired", Message: "hold has expired"}
+	// ErrBurrowAvailable is returned when joining the waitlist of a burrow that can be rented.
+	ErrBurrowAvailable = &Error{Code: "burrow_available", Message: "burrow is available, rent it instead"}
+	// ErrAlreadyWaitlisted is returned when joining a waitlist twice.
+	ErrAlreadyWaitlisted = &Error{Code: "already_waitlisted", Message: "already in the waitlist"}
+	// ErrBurrowAlreadyTaken is returned when joining the waitlist of a burrow the renter rents or holds.
+	ErrBurrowAlreadyTaken = &Error{Code: "burrow_already_taken", Message: "burrow is already rented or held by the renter"}
+	// ErrNotWaitlisted is returned when the renter is not in the waitlist of the burrow.
+	ErrNotWaitlisted = &Error{Code: "not_waitlisted", Message: "not in the waitlist"}
+	// ErrRentalNotFound is returned when no rental of the renter has the requested ID.
+	ErrRentalNotFound = &Error{Code: "rental_not_found", Message: "rental not found"}
+	// ErrInvalidLedgerEntry is returned when a ledger entry has an invalid kind or amount.
+	ErrInvalidLedgerEntry = &Error{Code: "invalid_ledger_entry", Message: "invalid ledger entry"}
+	// ErrRenterNotFound is returned when no renter has the requested ID.
+	ErrRenterNotFound = &Error{Code: "renter_not_found", Message: "renter not found"}
+	// ErrRenterAlreadyExists is returned when adding a renter whose ID is taken.
+	ErrRenterAlreadyExists = &Error{Code: "renter_already_exists", Message: "renter already exists"}
+	// ErrInvalidRenter is returned when a renter has invalid attributes.
+	ErrInvalidRenter = &Error{Code: "invalid_renter", Message: "invalid renter"}
+	// ErrRenterOverQuota is returned when renting more burrows than the limit of the renter.
+	ErrRenterOverQuota = &Error{Code: "renter_over_quota", Message: "renter has reached their maximum of concurrent burrows"}
+	// ErrRenterHasRentals is returned when deleting a renter who still rents burrows.
+	ErrRenterHasRentals = &Error{Code: "renter_has_rentals", Message: "renter still rents burrows"}
+	// ErrInvalidBackup is returned when restoring a backup that is not a valid state document.
+	ErrInvalidBackup = &Error{Code: "invalid_backup", Message: "invalid backup"}
+	// ErrPricingUnavailable is returned when quoting a burrow while no pricing is configured.
+	ErrPricingUnavailable = &Error{Code: "pricing_unavailable", Message: "pricing is not configured"}
+)
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Hold reserves a burrow for a renter while they complete their checkout. Other renters cannot
+// rent a held burrow until the hold expires, or is confirmed into a rental.
+type Hold struct {
+	ID        string    `json:"id"`
+	Burrow    string    `json:"burrow"`
+	Renter    string    `json:"renter,omitempty"` // subject of the renter holding the burrow
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// DefaultHoldTTL is the duration of the holds when none is configured.
+const DefaultHoldTTL = 15 * time.Minute
+
+// Expired reports whether the hold has expired at now.
+func (h *Hold) Expired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// EntryKind is the kind of a ledger entry.
+type EntryKind string
+
+const (
+	// EntryCharge bills the usage of a rental.
+	EntryCharge EntryKind = "charge"
+	// EntryRefund gives money back to a renter.
+	EntryRefund EntryKind = "refund"
+	// EntryAdjustment corrects the balance of a renter, either way.
+	EntryAdjustment EntryKind = "adjustment"
+)
+
+// LedgerEntry is an entry of the append-only ledger of a renter. Amount is in minor units of
+// Currency, positive for charges, negative for refunds and either for adjustments.
+type LedgerEntry struct {
+	ID          string    `json:"id"`
+	Renter      string    `json:"renter"`
+	Kind        EntryKind `json:"kind"`
+	Amount      int64     `json:"amount"`
+	Currency    string    `json:"currency,omitempty"`
+	RentalID    string    `json:"rentalId,omitempty"`
+	Burrow      string    `json:"burrow,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
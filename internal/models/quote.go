@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Quote is the daily price of renting a burrow, in minor units of its currency (cents for EUR).
+// Price is BasePrice after the discounts and surge multipliers.
+type Quote struct {
+	Burrow         string    `json:"burrow"`
+	Currency       string    `json:"currency,omitempty"`
+	Price          int64     `json:"price"`
+	BasePrice      int64     `json:"basePrice"` // from the volume and depth of the burrow
+	AgeDiscount    float64   `json:"ageDiscount"`
+	LifetimeFactor float64   `json:"lifetimeFactor"`
+	Surge          float64   `json:"surge"`            // product of the season and occupancy multipliers
+	Season         string    `json:"season,omitempty"` // name of the season surge applied
+	Occupancy      float64   `json:"occupancy"`        // share of the standing burrows rented
+	QuotedAt       time.Time `json:"quotedAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Rental is the occupation of a burrow by a renter, from StartedAt until EndedAt, which is nil
+// while the rental is current.
+type Rental struct {
+	ID        string     `json:"id"`
+	Burrow    string     `json:"burrow"`
+	Renter    string     `json:"renter,omitempty"`
+	Quote     *Quote     `json:"quote,omitempty"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	Charged   int64      `json:"charged"` // usage charged to the ledger so far, in minor units
+}
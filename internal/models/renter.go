@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// ContactChannel is the way a renter is contacted.
+type ContactChannel string
+
+const (
+	ContactEmail   ContactChannel = "email"
+	ContactSMS     ContactChannel = "sms"
+	ContactWebhook ContactChannel = "webhook"
+)
+
+// ContactChannels lists the supported contact channels.
+var ContactChannels = []ContactChannel{ContactEmail, ContactSMS, ContactWebhook}
+
+// Renter is the account of a renter, identified by the subject they authenticate as.
+type Renter struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Contact   Contact      `json:"contact"`
+	Limits    RenterLimits `json:"limits"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// Contact is where a renter is reached: an email address, a phone number or a webhook URL.
+type Contact struct {
+	Channel ContactChannel `json:"channel"`
+	Address string         `json:"address"`
+}
+
+// RenterLimits bound what a renter can do. Zero values are unlimited.
+type RenterLimits struct {
+	MaxConcurrentBurrows int `json:"maxConcurrentBurrows,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of the reports.
+const (
+	ReportFormatText = "text"
+	ReportFormatJSON = "json"
+)
+
+// ReportFormats are the formats the reports can be written in.
+var ReportFormats = []string{ReportFormatText, ReportFormatJSON}
+
+// Report sums up the state of the burrows. The burrows without width or depth are left out.
+type Report struct {
+	TotalDepth       float64       `json:"totalDepth"` // in meters
+	AvailableBurrows int           `json:"availableBurrows"`
+	Largest          *ReportBurrow `json:"largest"`
+	Smallest         *ReportBurrow `json:"smallest"`
+}
+
+// ReportBurrow is a burrow of a report and its volume in cubic meters.
+type ReportBurrow struct {
+	Name   string  `json:"name"`
+	Volume float64 `json:"volume"`
+}
+
+// Format writes the report in the format, one of ReportFormats.
+func (r *Report) Format(format string) (string, error) {
+	switch format {
+	case ReportFormatText:
+		return r.text(), nil
+	case ReportFormatJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encode the report")
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", errors.Errorf("unknown report format %q", format)
+	}
+}
+
+func (r *Report) text() string {
+	report := "GopherNet Burrow Report\n\n"
+	report += fmt.Sprintf("Total Depth of all Burrows: %.2f meters\n", r.TotalDepth)
+	report += fmt.Sprintf("Number of Available Burrows: %d\n", r.AvailableBurrows)
+
+	if r.Largest != nil {
+		report += fmt.Sprintf("Largest Burrow by Volume: %s (%.2f cubic meters)\n", r.Largest.Name, r.Largest.Volume)
+	} else {
+		report += "Largest Burrow by Volume: N/A\n"
+	}
+
+	if r.Smallest != nil {
+		report += fmt.Sprintf("Smallest Burrow by Volume: %s (%.2f cubic meters)\n", r.Smallest.Name, r.Smallest.Volume)
+	} else {
+		report += "Smallest Burrow by Volume: N/A\n"
+	}
+
+	return report
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// WaitlistEntry is a renter queuing for a burrow. When the burrow is released, the first renter
+// of its waitlist gets a hold on it.
+type WaitlistEntry struct {
+	Renter   string    `json:"renter"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// WaitlistPosition is the position of a renter in the waitlist of a burrow, 1 being served next.
+type WaitlistPosition struct {
+	Burrow   string `json:"burrow"`
+	Position int    `json:"position"`
+	Length   int    `json:"length"`
+}
@@ -0,0 +1,147 @@
+package pricing
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+const minutesPerDay = 24 * 60
+
+// Engine quotes the rentals of the burrows with the pricing rules of the configuration.
+type Engine struct {
+	rules atomic.Pointer[rules]
+	clock clock.Clock
+}
+
+// rules are the parsed pricing configuration.
+type rules struct {
+	config.Pricing
+	seasons []season
+}
+
+// season is a season surge with its days as month*100 + day.
+type season struct {
+	config.SeasonSurge
+	from, to int
+}
+
+// NewEngine creates an Engine quoting with the rules of cfg.
+func NewEngine(cfg config.Pricing, clk clock.Clock) (*Engine, error) {
+	e := &Engine{clock: clk}
+	if err := e.SetRules(cfg); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// SetRules changes the rules of the next quotes, keeping the current ones when cfg is invalid.
+func (e *Engine) SetRules(cfg config.Pricing) error {
+	r := &rules{Pricing: cfg}
+	for _, s := range cfg.Seasons {
+		from, err := monthDay(s.From)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid start of the season %q", s.Name)
+		}
+		to, err := monthDay(s.To)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid end of the season %q", s.Name)
+		}
+		r.seasons = append(r.seasons, season{SeasonSurge: s, from: from, to: to})
+	}
+
+	// The highest occupancy thresholds are checked first.
+	r.Occupancy = append([]config.OccupancySurge{}, cfg.Occupancy...)
+	sort.SliceStable(r.Occupancy, func(i, j int) bool { return r.Occupancy[i].Above > r.Occupancy[j].Above })
+
+	e.rules.Store(r)
+
+	return nil
+}
+
+// Currency returns the currency of the prices.
+func (e *Engine) Currency() string {
+	return e.rules.Load().Currency
+}
+
+// Quote returns the daily price of renting the burrow while occupancy of the standing burrows are rented.
+func (e *Engine) Quote(burrow *models.Burrow, occupancy float64) *models.Quote {
+	r := e.rules.Load()
+	now := e.clock.Now()
+
+	quote := &models.Quote{
+		Burrow:    burrow.Name,
+		Currency:  r.Currency,
+		BasePrice: int64(math.Round(float64(r.BasePrice) + float64(r.PerCubicMeter)*burrow.Volume() + float64(r.PerMeterDepth)*burrow.Depth)),
+		Occupancy: occupancy,
+		QuotedAt:  now,
+	}
+
+	quote.AgeDiscount = math.Min(r.AgeDiscount.PerDay*float64(burrow.Age)/minutesPerDay, r.AgeDiscount.Max)
+	quote.LifetimeFactor = r.MinLifetimeFactor + (1-r.MinLifetimeFactor)*remainingLifetime(burrow)
+
+	quote.Surge = 1
+	if s := r.season(now); s != nil {
+		quote.Surge *= s.Multiplier
+		quote.Season = s.Name
+	}
+	for _, surge := range r.Occupancy {
+		if occupancy > surge.Above {
+			quote.Surge *= surge.Multiplier
+			break
+		}
+	}
+
+	price := float64(quote.BasePrice) * (1 - quote.AgeDiscount) * quote.LifetimeFactor * quote.Surge
+	quote.Price = int64(math.Round(price))
+
+	return quote
+}
+
+// season returns the season surge with the highest multiplier applying at now, or nil.
+func (r *rules) season(now time.Time) *season {
+	day := int(now.Month())*100 + now.Day()
+
+	var applied *season
+	for i, s := range r.seasons {
+		inSeason := s.from <= day && day <= s.to
+		if s.from > s.to { // The season spans the new year.
+			inSeason = day >= s.from || day <= s.to
+		}
+		if inSeason && (applied == nil || s.Multiplier > applied.Multiplier) {
+			applied = &r.seasons[i]
+		}
+	}
+
+	return applied
+}
+
+// remainingLifetime returns the share of its lifetime the burrow has left before collapsing.
+func remainingLifetime(burrow *models.Burrow) float64 {
+	collapseAge := models.CurrentLifecycle().CollapseAge
+	if collapseAge <= 0 {
+		return 0
+	}
+
+	remaining := float64(collapseAge-burrow.Age) / float64(collapseAge)
+
+	return math.Max(0, math.Min(1, remaining))
+}
+
+// monthDay parses a MM-DD day into month*100 + day.
+func monthDay(value string) (int, error) {
+	t, err := time.Parse(config.MonthDayLayout, value)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return int(t.Month())*100 + t.Day(), nil
+}
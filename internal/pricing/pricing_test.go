@@ -0,0 +1,98 @@
+package pricing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
+)
+
+var rules = config.Pricing{
+	Currency:          "EUR",
+	BasePrice:         500,
+	PerCubicMeter:     200,
+	PerMeterDepth:     50,
+	AgeDiscount:       config.AgeDiscount{PerDay: 0.1, Max: 0.3},
+	MinLifetimeFactor: 0.5,
+	Seasons:           []config.SeasonSurge{{Name: "winter", From: "12-01", To: "02-29", Multiplier: 1.2}},
+	Occupancy: []config.OccupancySurge{
+		{Above: 0.5, Multiplier: 1.2},
+		{Above: 0.8, Multiplier: 1.5},
+	},
+}
+
+func TestEngine_Quote(t *testing.T) {
+	// Outside of the winter season.
+	clk := clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	engine, err := pricing.NewEngine(rules, clk)
+	require.NoError(t, err)
+
+	// 500 + 200 * pi (volume) + 50 * 1 (depth).
+	burrow := &models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}
+	quote := engine.Quote(burrow, 0)
+	assert.Equal(t, "The Deep Den", quote.Burrow)
+	assert.Equal(t, "EUR", quote.Currency)
+	assert.Equal(t, int64(1178), quote.BasePrice)
+	assert.Equal(t, int64(1178), quote.Price)
+	assert.Equal(t, clk.Now(), quote.QuotedAt)
+
+	// Two days old: 20% off, and 92% of its lifetime left.
+	burrow.Age = 2 * 24 * 60
+	quote = engine.Quote(burrow, 0)
+	assert.InDelta(t, 0.2, quote.AgeDiscount, 1e-9)
+	assert.InDelta(t, 0.96, quote.LifetimeFactor, 1e-9)
+	assert.Equal(t, int64(905), quote.Price)
+
+	// The age discount is capped, and the lifetime factor bottoms out before the collapse.
+	burrow.Age = models.CurrentLifecycle().CollapseAge
+	quote = engine.Quote(burrow, 0)
+	assert.InDelta(t, 0.3, quote.AgeDiscount, 1e-9)
+	assert.InDelta(t, 0.5, quote.LifetimeFactor, 1e-9)
+}
+
+func TestEngine_Surges(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	engine, err := pricing.NewEngine(rules, clk)
+	require.NoError(t, err)
+	burrow := &models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}
+
+	// The winter season spans the new year, and only the highest occupancy surge applies.
+	quote := engine.Quote(burrow, 0.9)
+	assert.Equal(t, "winter", quote.Season)
+	assert.InDelta(t, 1.8, quote.Surge, 1e-9)
+	assert.Equal(t, int64(2120), quote.Price)
+
+	quote = engine.Quote(burrow, 0.5)
+	assert.InDelta(t, 1.2, quote.Surge, 1e-9)
+
+	// The leap day is still in the winter season.
+	clk.Advance(45 * 24 * time.Hour)
+	quote = engine.Quote(burrow, 0.5)
+	assert.Equal(t, "winter", quote.Season)
+
+	clk.Advance(24 * time.Hour)
+	quote = engine.Quote(burrow, 0.6)
+	assert.Empty(t, quote.Season)
+	assert.InDelta(t, 1.2, quote.Surge, 1e-9)
+}
+
+func TestEngine_SetRules(t *testing.T) {
+	engine, err := pricing.NewEngine(rules, clock.NewFake(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	burrow := &models.Burrow{Name: "The Deep Den", Depth: 1, Width: 2}
+
+	// Invalid rules are refused and the current ones kept.
+	invalid := rules
+	invalid.Seasons = []config.SeasonSurge{{Name: "summer", From: "06-31", To: "08-31", Multiplier: 2}}
+	assert.Error(t, engine.SetRules(invalid))
+	assert.Equal(t, int64(1178), engine.Quote(burrow, 0).Price)
+
+	require.NoError(t, engine.SetRules(config.Pricing{}))
+	assert.Equal(t, int64(0), engine.Quote(burrow, 0).Price)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// HoldBurrow holds an available burrow for the renter until ttl elapses. A hold of the renter on
+// the burrow is replaced by the new one.
+func (s *MemoryRepository) HoldBurrow(name, renter string, ttl time.Duration) (*models.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkRentable(name, renter); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkQuota(renter, name); err != nil {
+		return nil, err
+	}
+
+	if existing := s.activeHold(name); existing != nil {
+		delete(s.holds, existing.ID)
+	}
+	s.removeWaiter(name, renter)
+
+	return s.hold(name, renter, ttl), nil
+}
+
+// hold holds the named burrow for the renter until ttl elapses and returns a copy of the hold.
+// s.mu must be held.
+func (s *MemoryRepository) hold(name, renter string, ttl time.Duration) *models.Hold {
+	now := s.clock.Now()
+	hold := &models.Hold{ID: newID(), Burrow: name, Renter: renter, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	s.holds[hold.ID] = hold
+
+	copiedHold := *hold
+	return &copiedHold
+}
+
+// GetHold returns a copy of the hold having the ID, expired or not.
+func (s *MemoryRepository) GetHold(id string) (*models.Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hold, exists := s.holds[id]
+	if !exists {
+		return nil, errors.WithMessage(models.ErrHoldNotFound, id)
+	}
+
+	copiedHold := *hold
+	return &copiedHold, nil
+}
+
+// ConfirmHold rents the held burrow to the renter of the hold, ending the hold. An expired hold
+// is removed and cannot be confirmed.
+func (s *MemoryRepository) ConfirmHold(id string) (*models.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, exists := s.holds[id]
+	if !exists {
+		return nil, errors.WithMessage(models.ErrHoldNotFound, id)
+	}
+
+	if hold.Expired(s.clock.Now()) {
+		delete(s.holds, id)
+		return nil, errors.WithMessage(models.ErrHoldExpired, id)
+	}
+
+	if err := s.checkRentable(hold.Burrow, hold.Renter); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkQuota(hold.Renter, hold.Burrow); err != nil {
+		return nil, err
+	}
+
+	s.rent(hold.Burrow, hold.Renter, s.occupancy(hold.Burrow))
+
+	return hold, nil
+}
+
+// ReleaseExpiredHolds removes the expired holds and returns them.
+func (s *MemoryRepository) ReleaseExpiredHolds() []*models.Hold {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var expired []*models.Hold
+	for _, hold := range s.sortedHolds() {
+		if hold.Expired(now) {
+			delete(s.holds, hold.ID)
+			expired = append(expired, hold)
+		}
+	}
+
+	return expired
+}
+
+// activeHold returns the hold of the named burrow that has not expired, or nil. s.mu must be held.
+func (s *MemoryRepository) activeHold(name string) *models.Hold {
+	now := s.clock.Now()
+	for _, hold := range s.holds {
+		if hold.Burrow == name && !hold.Expired(now) {
+			return hold
+		}
+	}
+
+	return nil
+}
+
+// sortedHolds returns the holds by creation time. s.mu must be held.
+func (s *MemoryRepository) sortedHolds() []*models.Hold {
+	holds := make([]*models.Hold, 0, len(s.holds))
+	for _, hold := range s.holds {
+		holds = append(holds, hold)
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		if !holds[i].CreatedAt.Equal(holds[j].CreatedAt) {
+			return holds[i].CreatedAt.Before(holds[j].CreatedAt)
+		}
+		return holds[i].ID < holds[j].ID
+	})
+
+	return holds
+}
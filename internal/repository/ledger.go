@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+const secondsPerDay = int64(24 * time.Hour / time.Second)
+
+// AccrueUsage charges the current rentals for their usage since their last charge, and returns the
+// new charges. The rentals of the collapsed burrows end when their burrow collapsed, freeing it.
+func (s *MemoryRepository) AccrueUsage() []*models.LedgerEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var charges []*models.LedgerEntry
+	for _, burrow := range s.burrowsList {
+		rental, exists := s.rentals[burrow.RentalID]
+		if !exists {
+			continue
+		}
+
+		var charge *models.LedgerEntry
+		if burrow.HasCollapsed() {
+			charge = s.release(burrow.Name)
+		} else {
+			charge = s.accrue(rental, now)
+		}
+		if charge != nil {
+			copiedCharge := *charge
+			charges = append(charges, &copiedCharge)
+		}
+	}
+
+	return charges
+}
+
+// endRental charges the last usage of the rental of the burrow and ends it now, or when the burrow
+// collapsed, and returns the charge, or nil when nothing is due. s.mu must be held.
+func (s *MemoryRepository) endRental(burrow *models.Burrow, rental *models.Rental, now time.Time) *models.LedgerEntry {
+	if rental.EndedAt != nil {
+		return nil
+	}
+
+	end := now
+	if burrow.HasCollapsed() {
+		// The age of a burrow grows by a minute every minute.
+		end = now.Add(-time.Duration(burrow.Age-models.CurrentLifecycle().CollapseAge) * time.Minute)
+		if end.Before(rental.StartedAt) {
+			end = rental.StartedAt
+		}
+	}
+
+	charge := s.accrue(rental, end)
+	rental.EndedAt = &end
+
+	return charge
+}
+
+// accrue charges the rental for its usage until now, at the daily price of its quote, and returns
+// the charge, or nil when nothing is due. The charges of a rental always add up to its whole
+// usage, however often it is accrued. A rental without a quote is priced at zero, on the rental
+// and its burrow, recorded by a zero charge. s.mu must be held.
+func (s *MemoryRepository) accrue(rental *models.Rental, now time.Time) *models.LedgerEntry {
+	if rental.EndedAt != nil {
+		return nil
+	}
+
+	if rental.Quote == nil {
+		rental.Quote = &models.Quote{Burrow: rental.Burrow, QuotedAt: now}
+		if burrow, exists := s.burrows[rental.Burrow]; exists && burrow.RentalID == rental.ID {
+			burrowQuote := *rental.Quote
+			burrow.Quote = &burrowQuote
+		}
+		return s.appendCharge(rental, 0, "Usage of "+rental.Burrow+" not charged, the rental has no quote", now)
+	}
+
+	elapsed := int64(now.Sub(rental.StartedAt) / time.Second)
+	due := rental.Quote.Price * elapsed / secondsPerDay
+	if due <= rental.Charged {
+		return nil
+	}
+
+	charge := s.appendCharge(rental, due-rental.Charged, "Usage of "+rental.Burrow, now)
+	rental.Charged = due
+
+	return charge
+}
+
+// appendCharge appends a charge of the amount to the rental to the ledger, and returns it. s.mu must be held.
+func (s *MemoryRepository) appendCharge(rental *models.Rental, amount int64, description string, now time.Time) *models.LedgerEntry {
+	charge := &models.LedgerEntry{
+		ID:          newID(),
+		Renter:      rental.Renter,
+		Kind:        models.EntryCharge,
+		Amount:      amount,
+		Currency:    rental.Quote.Currency,
+		RentalID:    rental.ID,
+		Burrow:      rental.Burrow,
+		Description: description,
+		CreatedAt:   now,
+	}
+	s.ledger = append(s.ledger, charge)
+
+	return charge
+}
+
+// AppendLedgerEntry appends a copy of the entry to the ledger, with a new ID and the current time,
+// and returns it. An entry linked to a rental must have the renter of the rental.
+func (s *MemoryRepository) AppendLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	appended := *entry
+	if appended.RentalID != "" {
+		rental, exists := s.rentals[appended.RentalID]
+		if !exists || rental.Renter != appended.Renter {
+			return nil, errors.WithMessage(models.ErrRentalNotFound, appended.RentalID)
+		}
+		appended.Burrow = rental.Burrow
+	}
+
+	appended.ID = newID()
+	appended.CreatedAt = s.clock.Now()
+	s.ledger = append(s.ledger, &appended)
+
+	copiedEntry := appended
+	return &copiedEntry, nil
+}
+
+// LedgerEntries returns copies of the ledger entries of the renter, oldest first.
+func (s *MemoryRepository) LedgerEntries(renter string) []*models.LedgerEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*models.LedgerEntry
+	for _, entry := range s.ledger {
+		if entry.Renter == renter {
+			copiedEntry := *entry
+			entries = append(entries, &copiedEntry)
+		}
+	}
+
+	return entries
+}
+
+// sortedRentals returns the rentals by start time. s.mu must be held.
+func (s *MemoryRepository) sortedRentals() []*models.Rental {
+	rentals := make([]*models.Rental, 0, len(s.rentals))
+	for _, rental := range s.rentals {
+		rentals = append(rentals, rental)
+	}
+	sort.Slice(rentals, func(i, j int) bool {
+		if !rentals[i].StartedAt.Equal(rentals[j].StartedAt) {
+			return rentals[i].StartedAt.Before(rentals[j].StartedAt)
+		}
+		return rentals[i].ID < rentals[j].ID
+	})
+
+	return rentals
+}
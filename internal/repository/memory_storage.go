@@ -6,12 +6,21 @@ import (
 	"os"
 	"sync"
 
+	"github.com/marcodd23/gopernet/internal/clock"
 	"github.com/marcodd23/gopernet/internal/models"
 )
 
 type MemoryRepository struct {
 	burrows     map[string]*models.Burrow
 	burrowsList []*models.Burrow // For preserving order
+	holds       map[string]*models.Hold
+	waitlists   map[string][]*models.WaitlistEntry
+	rentals     map[string]*models.Rental
+	ledger      []*models.LedgerEntry
+	renters     map[string]*models.Renter
+	limits      models.RenterLimits // Of the renters without an account
+	clock       clock.Clock
+	quote       QuoteFunc
 	mu          sync.RWMutex
 	stateFile   string
 	reportFile  string
@@ -21,11 +30,69 @@ func NewMemoryRepository(stateFile, reportFile string) *MemoryRepository {
 	return &MemoryRepository{
 		burrows:     make(map[string]*models.Burrow),
 		burrowsList: make([]*models.Burrow, 0),
+		holds:       make(map[string]*models.Hold),
+		waitlists:   make(map[string][]*models.WaitlistEntry),
+		rentals:     make(map[string]*models.Rental),
+		renters:     make(map[string]*models.Renter),
+		clock:       clock.New(),
 		stateFile:   stateFile,
 		reportFile:  reportFile,
 	}
 }
 
+// SetClock sets the clock measuring the expiry of the holds.
+func (s *MemoryRepository) SetClock(clk clock.Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clock = clk
+}
+
+// SetDefaultLimits sets the limits of the renters without an account, unlimited by default.
+func (s *MemoryRepository) SetDefaultLimits(limits models.RenterLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limits = limits
+}
+
+// SetQuoter makes the repository quote every new rental with quote, while renting it.
+func (s *MemoryRepository) SetQuoter(quote QuoteFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quote = quote
+}
+
+// Occupancy returns the share of the standing burrows, other than the named one, that are rented.
+func (s *MemoryRepository) Occupancy(name string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.occupancy(name)
+}
+
+// occupancy returns the share of the standing burrows, other than the named one, that are rented.
+// s.mu must be held.
+func (s *MemoryRepository) occupancy(name string) float64 {
+	standing, rented := 0, 0
+	for _, burrow := range s.burrowsList {
+		if burrow.HasCollapsed() {
+			continue
+		}
+		standing++
+		if burrow.Occupied && burrow.Name != name {
+			rented++
+		}
+	}
+
+	if standing == 0 {
+		return 0
+	}
+
+	return float64(rented) / float64(standing)
+}
+
 func (s *MemoryRepository) GetAllBurrows() []*models.Burrow {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -35,38 +102,229 @@ func (s *MemoryRepository) GetAllBurrows() []*models.Burrow {
 		// Create a deep copy of the burrow before returning
 		// to avoid that the user of the repository could modify the
 		// data in the storage
-		copiedBurrow := &models.Burrow{
-			Name:     burrow.Name,
-			Depth:    burrow.Depth,
-			Width:    burrow.Width,
-			Occupied: burrow.Occupied,
-			Age:      burrow.Age,
+		burrowsListCopy = append(burrowsListCopy, copyBurrow(burrow))
+	}
+
+	return burrowsListCopy
+}
+
+// GetBurrow returns a copy of the named burrow.
+func (s *MemoryRepository) GetBurrow(name string) (*models.Burrow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	burrow, exists := s.burrows[name]
+	if !exists {
+		return nil, errors.WithMessage(models.ErrBurrowNotFound, name)
+	}
+
+	return copyBurrow(burrow), nil
+}
+
+// copyBurrow returns a deep copy of the burrow.
+func copyBurrow(burrow *models.Burrow) *models.Burrow {
+	copiedBurrow := *burrow
+	if burrow.Quote != nil {
+		quote := *burrow.Quote
+		copiedBurrow.Quote = &quote
+	}
+
+	return &copiedBurrow
+}
+
+func (s *MemoryRepository) RentBurrow(name, renter string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkRentable(name, renter); err != nil {
+		return err
+	}
+
+	if err := s.checkQuota(renter, name); err != nil {
+		return err
+	}
+
+	s.rent(name, renter, s.occupancy(name))
+
+	return nil
+}
+
+// RentBurrows rents every named burrow to the renter, or none of them when one cannot be rented.
+// The errors are indexed like names and are all nil when the burrows were rented; a name
+// repeated in names is unavailable.
+func (s *MemoryRepository) RentBurrows(names []string, renter string) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rented []string
+	check := func(name string) error {
+		if err := s.checkRentable(name, renter); err != nil {
+			return err
 		}
+		rented = append(rented, name)
+		return s.checkQuota(renter, rented...)
+	}
+	errs, failed := checkBatch(names, check, models.ErrBurrowUnavailable)
+	if failed {
+		return errs
+	}
 
-		burrowsListCopy = append(burrowsListCopy, copiedBurrow)
+	// The burrows of the batch are quoted at the occupancy before the batch.
+	occupancy := s.occupancy("")
+	for _, name := range names {
+		s.rent(name, renter, occupancy)
 	}
 
-	return burrowsListCopy
+	return errs
 }
 
-func (s *MemoryRepository) RentBurrow(name string) error {
+// rent rents the named burrow to the renter, starting a rental quoted at occupancy, ending its hold
+// and removing the renter from its waitlist. s.mu must be held.
+func (s *MemoryRepository) rent(name, renter string, occupancy float64) {
+	rental := &models.Rental{ID: newID(), Burrow: name, Renter: renter, StartedAt: s.clock.Now()}
+	s.rentals[rental.ID] = rental
+
+	burrow := s.burrows[name]
+	burrow.Quote = nil
+	if s.quote != nil {
+		if quote := s.quote(copyBurrow(burrow), occupancy); quote != nil {
+			burrowQuote, rentalQuote := *quote, *quote
+			burrow.Quote, rental.Quote = &burrowQuote, &rentalQuote
+		}
+	}
+
+	burrow.Occupied = true
+	burrow.RentedBy = renter
+	burrow.RentalID = rental.ID
+
+	for id, hold := range s.holds {
+		if hold.Burrow == name {
+			delete(s.holds, id)
+		}
+	}
+	s.removeWaiter(name, renter)
+}
+
+// checkRentable returns why the named burrow cannot be rented by the renter, or nil. s.mu must be held.
+func (s *MemoryRepository) checkRentable(name, renter string) error {
+	burrow, exists := s.burrows[name]
+	if !exists {
+		return errors.WithMessage(models.ErrBurrowNotFound, name)
+	}
+
+	if burrow.HasCollapsed() {
+		return errors.WithMessage(models.ErrBurrowCollapsed, name)
+	}
+
+	if burrow.Occupied {
+		return errors.WithMessage(models.ErrBurrowUnavailable, name)
+	}
+
+	hold := s.activeHold(name)
+	if hold != nil && hold.Renter != renter {
+		return errors.WithMessage(models.ErrBurrowHeld, name)
+	}
+
+	// A burrow released while renters wait for it is theirs, first come first served, skipping
+	// the renters over quota.
+	if next := s.nextWaiter(name); hold == nil && next != nil && next.Renter != renter {
+		return errors.WithMessage(models.ErrBurrowUnavailable, name+" is reserved for its waitlist")
+	}
+
+	return nil
+}
+
+// ReleaseBurrow frees an occupied burrow. When renter is not empty, the burrow must be rented by
+// renter.
+func (s *MemoryRepository) ReleaseBurrow(name, renter string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.checkReleasable(name, renter); err != nil {
+		return err
+	}
+
+	s.release(name)
+
+	return nil
+}
+
+// ReleaseBurrows frees every named burrow, or none of them when one is not rented, or not by
+// renter when it is not empty. The errors are indexed like names and are all nil when the burrows
+// were released; a name repeated in names is not rented.
+func (s *MemoryRepository) ReleaseBurrows(names []string, renter string) []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs, failed := checkBatch(names, func(name string) error {
+		return s.checkReleasable(name, renter)
+	}, models.ErrBurrowNotRented)
+	if failed {
+		return errs
+	}
+
+	for _, name := range names {
+		s.release(name)
+	}
+
+	return errs
+}
+
+// release frees the named burrow, charging the last usage of its rental and ending it, and returns
+// the charge, or nil when nothing is due. s.mu must be held.
+func (s *MemoryRepository) release(name string) *models.LedgerEntry {
+	burrow := s.burrows[name]
+	var charge *models.LedgerEntry
+	if rental, exists := s.rentals[burrow.RentalID]; exists {
+		charge = s.endRental(burrow, rental, s.clock.Now())
+	}
+
+	burrow.Occupied = false
+	burrow.RentedBy = ""
+	burrow.Quote = nil
+	burrow.RentalID = ""
+
+	return charge
+}
+
+// checkReleasable returns why the named burrow cannot be released, or nil. s.mu must be held.
+func (s *MemoryRepository) checkReleasable(name, renter string) error {
 	burrow, exists := s.burrows[name]
 	if !exists {
-		return errors.New("burrow not found")
+		return errors.WithMessage(models.ErrBurrowNotFound, name)
 	}
 
-	if burrow.Occupied || burrow.HasCollapsed() {
-		return errors.New("burrow not available")
+	if !burrow.Occupied {
+		return errors.WithMessage(models.ErrBurrowNotRented, name)
 	}
 
-	burrow.Occupied = true
+	if renter != "" && burrow.RentedBy != renter {
+		return errors.WithMessage(models.ErrForbidden, name)
+	}
 
 	return nil
 }
 
+// checkBatch checks every name, the repeated ones failing with repeated, and returns the errors
+// indexed like names and whether one failed.
+func checkBatch(names []string, check func(name string) error, repeated error) ([]error, bool) {
+	errs := make([]error, len(names))
+	seen := make(map[string]bool, len(names))
+	failed := false
+
+	for i, name := range names {
+		if seen[name] {
+			errs[i] = errors.WithMessage(repeated, name)
+		} else {
+			errs[i] = check(name)
+		}
+		seen[name] = true
+		failed = failed || errs[i] != nil
+	}
+
+	return errs, failed
+}
+
 func (s *MemoryRepository) UpdateAllBurrows() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -85,30 +343,90 @@ func (s *MemoryRepository) LoadState() error {
 		return errors.WithMessage(err, "failed to read state file")
 	}
 
-	var burrows []*models.Burrow
-	if err := json.Unmarshal(data, &burrows); err != nil {
+	state, err := decodeState(data)
+	if err != nil {
 		return errors.WithMessage(err, "failed to unmarshal state data")
 	}
 
-	// Clear existing data
+	s.setState(state)
+
+	return nil
+}
+
+// RestoreState replaces the whole state by the backup, a state document in the format of the
+// state file. A backup that cannot be decoded or holds invalid burrows is refused with
+// models.ErrInvalidBackup, leaving the state unchanged.
+func (s *MemoryRepository) RestoreState(backup []byte) (*models.RestoreSummary, error) {
+	state, err := decodeState(backup)
+	if err != nil {
+		return nil, errors.WithMessage(models.ErrInvalidBackup, err.Error())
+	}
+
+	names := make(map[string]bool, len(state.Burrows))
+	for _, burrow := range state.Burrows {
+		if burrow == nil || burrow.Name == "" {
+			return nil, errors.WithMessage(models.ErrInvalidBackup, "every burrow must have a name")
+		}
+		if names[burrow.Name] {
+			return nil, errors.WithMessagef(models.ErrInvalidBackup, "burrow %s is repeated", burrow.Name)
+		}
+		names[burrow.Name] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setState(state)
+
+	return &models.RestoreSummary{
+		Burrows: len(s.burrowsList),
+		Holds:   len(s.holds),
+		Rentals: len(s.rentals),
+		Renters: len(s.renters),
+	}, nil
+}
+
+// setState replaces the whole state. s.mu must be held.
+func (s *MemoryRepository) setState(state state) {
 	s.burrows = make(map[string]*models.Burrow)
 	s.burrowsList = make([]*models.Burrow, 0)
+	s.holds = make(map[string]*models.Hold)
 
-	for _, burrow := range burrows {
+	for _, burrow := range state.Burrows {
 		s.burrowsList = append(s.burrowsList, burrow)
 		s.burrows[burrow.Name] = burrow
 	}
 
-	return nil
+	for _, hold := range state.Holds {
+		s.holds[hold.ID] = hold
+	}
+
+	s.waitlists = make(map[string][]*models.WaitlistEntry)
+	for name, waiters := range state.Waitlists {
+		if len(waiters) > 0 {
+			s.waitlists[name] = waiters
+		}
+	}
+
+	s.rentals = make(map[string]*models.Rental)
+	for _, rental := range state.Rentals {
+		s.rentals[rental.ID] = rental
+	}
+	s.ledger = state.Ledger
+
+	s.renters = make(map[string]*models.Renter)
+	for _, renter := range state.Renters {
+		s.renters[renter.ID] = renter
+	}
 }
 
 func (s *MemoryRepository) SaveState() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	data, err := json.MarshalIndent(s.burrowsList, "", "  ")
+	data, err := s.marshalState()
 	if err != nil {
-		return errors.WithMessage(err, "failed to marshal state")
+		return err
 	}
 
 	if err := os.WriteFile(s.stateFile, data, 0644); err != nil {
@@ -118,6 +436,31 @@ func (s *MemoryRepository) SaveState() error {
 	return nil
 }
 
+// Backup returns the whole state as a state document, in the format of the state file.
+func (s *MemoryRepository) Backup() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.marshalState()
+}
+
+// marshalState encodes the whole state. s.mu must be held.
+func (s *MemoryRepository) marshalState() ([]byte, error) {
+	data, err := json.MarshalIndent(state{
+		Burrows:   s.burrowsList,
+		Holds:     s.sortedHolds(),
+		Waitlists: s.waitlists,
+		Rentals:   s.sortedRentals(),
+		Ledger:    s.ledger,
+		Renters:   s.sortedRenters(),
+	}, "", "  ")
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to marshal state")
+	}
+
+	return data, nil
+}
+
 func (s *MemoryRepository) SaveReport(report string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -137,9 +480,34 @@ func (s *MemoryRepository) GetReportFile() string {
 	return s.reportFile
 }
 
-func (s *MemoryRepository) AddBurrow(burrow *models.Burrow) {
+func (s *MemoryRepository) AddBurrow(burrow *models.Burrow) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+
+	if _, exists := s.burrows[burrow.Name]; exists {
+		return errors.WithMessage(models.ErrBurrowAlreadyExists, burrow.Name)
+	}
+
 	s.burrows[burrow.Name] = burrow
 	s.burrowsList = append(s.burrowsList, burrow)
+
+	return nil
+}
+
+// UpdateBurrow replaces the depth, width and age of the existing burrow having the name of burrow.
+// Its rental is kept: rentals only change through RentBurrow and ReleaseBurrow.
+func (s *MemoryRepository) UpdateBurrow(burrow *models.Burrow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.burrows[burrow.Name]
+	if !exists {
+		return errors.WithMessage(models.ErrBurrowNotFound, burrow.Name)
+	}
+
+	existing.Depth = burrow.Depth
+	existing.Width = burrow.Width
+	existing.Age = burrow.Age
+
+	return nil
 }
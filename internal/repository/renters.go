@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// AddRenter adds a copy of the renter, created now, and returns it.
+func (s *MemoryRepository) AddRenter(renter *models.Renter) (*models.Renter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.renters[renter.ID]; exists {
+		return nil, errors.WithMessage(models.ErrRenterAlreadyExists, renter.ID)
+	}
+
+	added := *renter
+	added.CreatedAt = s.clock.Now()
+	added.UpdatedAt = added.CreatedAt
+	s.renters[added.ID] = &added
+
+	copiedRenter := added
+	return &copiedRenter, nil
+}
+
+// GetRenter returns a copy of the renter having the ID.
+func (s *MemoryRepository) GetRenter(id string) (*models.Renter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	renter, exists := s.renters[id]
+	if !exists {
+		return nil, errors.WithMessage(models.ErrRenterNotFound, id)
+	}
+
+	copiedRenter := *renter
+	return &copiedRenter, nil
+}
+
+// GetAllRenters returns copies of the renters, by ID.
+func (s *MemoryRepository) GetAllRenters() []*models.Renter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	renters := make([]*models.Renter, 0, len(s.renters))
+	for _, renter := range s.sortedRenters() {
+		copiedRenter := *renter
+		renters = append(renters, &copiedRenter)
+	}
+
+	return renters
+}
+
+// UpdateRenter replaces the name, contact and limits of the existing renter having the ID of
+// renter, and returns it. Lowering its limits does not end its current rentals.
+func (s *MemoryRepository) UpdateRenter(renter *models.Renter) (*models.Renter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.renters[renter.ID]
+	if !exists {
+		return nil, errors.WithMessage(models.ErrRenterNotFound, renter.ID)
+	}
+
+	existing.Name = renter.Name
+	existing.Contact = renter.Contact
+	existing.Limits = renter.Limits
+	existing.UpdatedAt = s.clock.Now()
+
+	copiedRenter := *existing
+	return &copiedRenter, nil
+}
+
+// DeleteRenter deletes the renter having the ID, who must not rent any burrow. Their rentals
+// and ledger are kept.
+func (s *MemoryRepository) DeleteRenter(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.renters[id]; !exists {
+		return errors.WithMessage(models.ErrRenterNotFound, id)
+	}
+
+	if s.rentedBurrows(id) > 0 {
+		return errors.WithMessage(models.ErrRenterHasRentals, id)
+	}
+
+	delete(s.renters, id)
+
+	return nil
+}
+
+// GetRentals returns copies of the current and past rentals of the renter, oldest first. The
+// renter must have an account or rentals.
+func (s *MemoryRepository) GetRentals(renter string) ([]*models.Rental, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rentals := s.copyRentals(func(rental *models.Rental) bool { return rental.Renter == renter })
+	if _, exists := s.renters[renter]; !exists && len(rentals) == 0 {
+		return nil, errors.WithMessage(models.ErrRenterNotFound, renter)
+	}
+
+	return rentals, nil
+}
+
+// GetBurrowRentals returns copies of the current and past rentals of the named burrow, oldest first.
+func (s *MemoryRepository) GetBurrowRentals(name string) ([]*models.Rental, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.burrows[name]; !exists {
+		return nil, errors.WithMessage(models.ErrBurrowNotFound, name)
+	}
+
+	return s.copyRentals(func(rental *models.Rental) bool { return rental.Burrow == name }), nil
+}
+
+// copyRentals returns copies of the rentals matching match, oldest first. The caller holds the lock.
+func (s *MemoryRepository) copyRentals(match func(*models.Rental) bool) []*models.Rental {
+	rentals := make([]*models.Rental, 0)
+	for _, rental := range s.sortedRentals() {
+		if !match(rental) {
+			continue
+		}
+
+		copiedRental := *rental
+		if rental.Quote != nil {
+			quote := *rental.Quote
+			copiedRental.Quote = &quote
+		}
+		if rental.EndedAt != nil {
+			endedAt := *rental.EndedAt
+			copiedRental.EndedAt = &endedAt
+		}
+		rentals = append(rentals, &copiedRental)
+	}
+
+	return rentals
+}
+
+// checkQuota returns ErrRenterOverQuota when taking the named burrows, on top of the burrows the
+// renter rents and holds, would exceed the limit of the renter, or nil. Renters without an account
+// have the default limits, and the rentals without a renter are not limited. s.mu must be held.
+func (s *MemoryRepository) checkQuota(renter string, names ...string) error {
+	if renter == "" {
+		return nil
+	}
+
+	limits := s.limits
+	if account, exists := s.renters[renter]; exists {
+		limits = account.Limits
+	}
+	if limits.MaxConcurrentBurrows <= 0 {
+		return nil
+	}
+
+	taken := s.takenBurrows(renter)
+	for _, name := range names {
+		taken[name] = true
+	}
+
+	if len(taken) > limits.MaxConcurrentBurrows {
+		return errors.WithMessagef(models.ErrRenterOverQuota, "%s rents at most %d burrow(s)", renter, limits.MaxConcurrentBurrows)
+	}
+
+	return nil
+}
+
+// takenBurrows returns the names of the burrows the renter rents or holds. s.mu must be held.
+func (s *MemoryRepository) takenBurrows(renter string) map[string]bool {
+	taken := make(map[string]bool)
+	for _, burrow := range s.burrowsList {
+		if burrow.Occupied && burrow.RentedBy == renter {
+			taken[burrow.Name] = true
+		}
+	}
+
+	now := s.clock.Now()
+	for _, hold := range s.holds {
+		if hold.Renter == renter && !hold.Expired(now) {
+			taken[hold.Burrow] = true
+		}
+	}
+
+	return taken
+}
+
+// rentedBurrows returns the number of burrows the renter rents. s.mu must be held.
+func (s *MemoryRepository) rentedBurrows(renter string) int {
+	count := 0
+	for _, burrow := range s.burrowsList {
+		if burrow.Occupied && burrow.RentedBy == renter {
+			count++
+		}
+	}
+
+	return count
+}
+
+// sortedRenters returns the renters by ID. s.mu must be held.
+func (s *MemoryRepository) sortedRenters() []*models.Renter {
+	renters := make([]*models.Renter, 0, len(s.renters))
+	for _, renter := range s.renters {
+		renters = append(renters, renter)
+	}
+	sort.Slice(renters, func(i, j int) bool { return renters[i].ID < renters[j].ID })
+
+	return renters
+}
@@ -1,18 +1,53 @@
 package repository
 
-import "github.com/marcodd23/gopernet/internal/models"
+import (
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// QuoteFunc returns the daily price of renting the burrow while occupancy of the standing burrows
+// are rented, or nil when the burrow has no price.
+type QuoteFunc func(burrow *models.Burrow, occupancy float64) *models.Quote
 
 type Repository interface {
 	GetAllBurrows() []*models.Burrow
-	RentBurrow(name string) error
+	GetBurrow(name string) (*models.Burrow, error)
+	RentBurrow(name, renter string) error
+	ReleaseBurrow(name, renter string) error
+	RentBurrows(names []string, renter string) []error
+	ReleaseBurrows(names []string, renter string) []error
+	SetQuoter(quote QuoteFunc)
+	Occupancy(name string) float64
 	UpdateAllBurrows()
-	AddBurrow(burrow *models.Burrow)
+	AddBurrow(burrow *models.Burrow) error
+	UpdateBurrow(burrow *models.Burrow) error
+	HoldBurrow(name, renter string, ttl time.Duration) (*models.Hold, error)
+	GetHold(id string) (*models.Hold, error)
+	ConfirmHold(id string) (*models.Hold, error)
+	ReleaseExpiredHolds() []*models.Hold
+	JoinWaitlist(name, renter string) (*models.WaitlistPosition, error)
+	GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error)
+	LeaveWaitlist(name, renter string) error
+	HoldNextWaiter(name string, ttl time.Duration) (*models.Hold, error)
+	AccrueUsage() []*models.LedgerEntry
+	AppendLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error)
+	LedgerEntries(renter string) []*models.LedgerEntry
+	AddRenter(renter *models.Renter) (*models.Renter, error)
+	GetRenter(id string) (*models.Renter, error)
+	GetAllRenters() []*models.Renter
+	UpdateRenter(renter *models.Renter) (*models.Renter, error)
+	DeleteRenter(id string) error
+	GetRentals(renter string) ([]*models.Rental, error)
+	GetBurrowRentals(name string) ([]*models.Rental, error)
 }
 
 type StatefulRepository interface {
 	Repository
 	LoadState() error
 	SaveState() error
+	Backup() ([]byte, error)
+	RestoreState(backup []byte) (*models.RestoreSummary, error)
 	SaveReport(report string) error
 	GetStateFile() string
 	GetReportFile() string
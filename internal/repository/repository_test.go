@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/marcodd23/gopernet/internal/clock"
 	"github.com/marcodd23/gopernet/internal/models"
 	"github.com/marcodd23/gopernet/internal/repository"
 	"github.com/stretchr/testify/assert"
@@ -75,12 +77,43 @@ func TestMemoryRepository_SaveState(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Unmarshal the data and check the burrows
-	var loadedBurrows []*models.Burrow
-	err = json.Unmarshal(data, &loadedBurrows)
+	var loaded struct {
+		Burrows []*models.Burrow `json:"burrows"`
+		Holds   []*models.Hold   `json:"holds"`
+	}
+	err = json.Unmarshal(data, &loaded)
 	assert.NoError(t, err)
-	assert.Equal(t, 2, len(loadedBurrows))
-	assert.Equal(t, "Burrow1", loadedBurrows[0].Name)
-	assert.Equal(t, "Burrow2", loadedBurrows[1].Name)
+	assert.Equal(t, 2, len(loaded.Burrows))
+	assert.Equal(t, "Burrow1", loaded.Burrows[0].Name)
+	assert.Equal(t, "Burrow2", loaded.Burrows[1].Name)
+	assert.Empty(t, loaded.Holds)
+}
+
+func TestMemoryRepository_BackupAndRestore(t *testing.T) {
+	repo := repository.NewMemoryRepository("", "")
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1, Width: 1}))
+	assert.NoError(t, repo.RentBurrow("Burrow1", "alice"))
+
+	backup, err := repo.Backup()
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1, Width: 1}))
+
+	summary, err := repo.RestoreState(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.RestoreSummary{Burrows: 1, Rentals: 1}, summary)
+
+	burrows := repo.GetAllBurrows()
+	assert.Len(t, burrows, 1)
+	assert.Equal(t, "alice", burrows[0].RentedBy)
+
+	// An invalid backup leaves the state unchanged.
+	_, err = repo.RestoreState([]byte(`{"burrows":[{"name":"Burrow3"},{"name":"Burrow3"}]}`))
+	assert.ErrorIs(t, err, models.ErrInvalidBackup)
+	_, err = repo.RestoreState([]byte(`not json`))
+	assert.ErrorIs(t, err, models.ErrInvalidBackup)
+	assert.Len(t, repo.GetAllBurrows(), 1)
 }
 
 func TestMemoryRepository_RentBurrow(t *testing.T) {
@@ -92,15 +125,16 @@ func TestMemoryRepository_RentBurrow(t *testing.T) {
 	repo.AddBurrow(burrow)
 
 	// Rent the burrow
-	err := repo.RentBurrow("Burrow1")
+	err := repo.RentBurrow("Burrow1", "renter-1")
 	assert.NoError(t, err)
 
-	// Check if the burrow is now occupied using public API
+	// Check if the burrow is now occupied by the renter using public API
 	loadedBurrows := repo.GetAllBurrows()
 	assert.True(t, loadedBurrows[0].Occupied)
+	assert.Equal(t, "renter-1", loadedBurrows[0].RentedBy)
 
 	// Try renting an already occupied burrow
-	err = repo.RentBurrow("Burrow1")
+	err = repo.RentBurrow("Burrow1", "renter-2")
 	assert.Error(t, err)
 }
 
@@ -142,3 +176,486 @@ func TestMemoryRepository_SaveReport(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, report, string(data))
 }
+
+func TestMemoryRepository_AddBurrow(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	err := repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.5, Width: 1.0})
+	assert.NoError(t, err)
+
+	// Adding a burrow with the same name fails
+	err = repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 2.0, Width: 1.0})
+	assert.Error(t, err)
+
+	loadedBurrows := repo.GetAllBurrows()
+	assert.Equal(t, 1, len(loadedBurrows))
+	assert.Equal(t, 1.5, loadedBurrows[0].Depth)
+}
+
+func TestMemoryRepository_RentBurrowErrors(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Occupied", Depth: 1.0, Width: 1.0, Occupied: true}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Collapsed", Depth: 1.0, Width: 1.0, Age: 25 * 24 * 60}))
+
+	assert.ErrorIs(t, repo.RentBurrow("Nowhere", "renter-1"), models.ErrBurrowNotFound)
+	assert.ErrorIs(t, repo.RentBurrow("Occupied", "renter-1"), models.ErrBurrowUnavailable)
+	assert.ErrorIs(t, repo.RentBurrow("Collapsed", "renter-1"), models.ErrBurrowCollapsed)
+}
+
+func TestMemoryRepository_ReleaseBurrow(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.RentBurrow("Burrow1", "renter-1"))
+
+	// Only the renter of the burrow releases it, unless no renter is given.
+	assert.ErrorIs(t, repo.ReleaseBurrow("Burrow1", "renter-2"), models.ErrForbidden)
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", "renter-1"))
+	assert.NoError(t, repo.RentBurrow("Burrow1", "renter-1"))
+
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	burrow, err := repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.False(t, burrow.Occupied)
+	assert.Empty(t, burrow.RentedBy)
+
+	assert.ErrorIs(t, repo.ReleaseBurrow("Burrow1", ""), models.ErrBurrowNotRented)
+	assert.ErrorIs(t, repo.ReleaseBurrow("Nowhere", ""), models.ErrBurrowNotFound)
+}
+
+func TestMemoryRepository_QuotesRentals(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for _, name := range []string{"Burrow1", "Burrow2", "Burrow3"} {
+		assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: name, Depth: 1.0, Width: 1.0}))
+	}
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Occupied", Depth: 1.0, Width: 1.0, Occupied: true}))
+
+	occupancies := make(map[string]float64)
+	repo.SetQuoter(func(burrow *models.Burrow, occupancy float64) *models.Quote {
+		occupancies[burrow.Name] = occupancy
+		return &models.Quote{Burrow: burrow.Name, Currency: "EUR", Price: 1200}
+	})
+
+	assert.NoError(t, repo.RentBurrow("Burrow1", "renter-1"))
+	assert.Equal(t, 0.25, occupancies["Burrow1"])
+
+	// The burrows of a batch are quoted at the occupancy before the batch.
+	assert.Equal(t, []error{nil, nil}, repo.RentBurrows([]string{"Burrow2", "Burrow3"}, "renter-2"))
+	assert.Equal(t, 0.5, occupancies["Burrow2"])
+	assert.Equal(t, 0.5, occupancies["Burrow3"])
+	assert.Equal(t, 0.75, repo.Occupancy("Burrow3"))
+
+	// The quote is copied out of the repository, and persisted with the rental.
+	burrow, err := repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1200), burrow.Quote.Price)
+	burrow.Quote.Price = 0
+	assert.Equal(t, int64(1200), repo.GetAllBurrows()[0].Quote.Price)
+
+	assert.NoError(t, repo.SaveState())
+	assert.NoError(t, repo.LoadState())
+	assert.Equal(t, int64(1200), repo.GetAllBurrows()[0].Quote.Price)
+	rentals, err := repo.GetRentals("renter-1")
+	assert.NoError(t, err)
+	assert.Len(t, rentals, 1)
+	assert.Equal(t, int64(1200), rentals[0].Quote.Price)
+
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	assert.Nil(t, repo.GetAllBurrows()[0].Quote)
+}
+
+func TestMemoryRepository_RentBurrows(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Occupied", Depth: 1.0, Width: 1.0, Occupied: true}))
+
+	// One unavailable burrow fails the batch, renting none of them.
+	errs := repo.RentBurrows([]string{"Burrow1", "Occupied", "Nowhere", "Burrow1"}, "renter-1")
+	assert.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], models.ErrBurrowUnavailable)
+	assert.ErrorIs(t, errs[2], models.ErrBurrowNotFound)
+	assert.ErrorIs(t, errs[3], models.ErrBurrowUnavailable)
+	burrow, err := repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.False(t, burrow.Occupied)
+
+	assert.Equal(t, []error{nil, nil}, repo.RentBurrows([]string{"Burrow1", "Burrow2"}, "renter-1"))
+	for _, name := range []string{"Burrow1", "Burrow2"} {
+		burrow, err := repo.GetBurrow(name)
+		assert.NoError(t, err)
+		assert.True(t, burrow.Occupied)
+		assert.Equal(t, "renter-1", burrow.RentedBy)
+	}
+}
+
+func TestMemoryRepository_ReleaseBurrows(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0, Occupied: true, RentedBy: "renter-1"}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+
+	errs := repo.ReleaseBurrows([]string{"Burrow1"}, "renter-2")
+	assert.ErrorIs(t, errs[0], models.ErrForbidden)
+
+	errs = repo.ReleaseBurrows([]string{"Burrow1", "Burrow2"}, "")
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], models.ErrBurrowNotRented)
+	burrow, err := repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.True(t, burrow.Occupied)
+
+	assert.Equal(t, []error{nil}, repo.ReleaseBurrows([]string{"Burrow1"}, ""))
+	burrow, err = repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.False(t, burrow.Occupied)
+}
+
+func TestMemoryRepository_HoldBurrow(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo.SetClock(clk)
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	hold, err := repo.HoldBurrow("Burrow1", "alice", 10*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, clk.Now().Add(10*time.Minute), hold.ExpiresAt)
+
+	// The hold blocks the other renters, not its renter.
+	_, err = repo.HoldBurrow("Burrow1", "bob", 10*time.Minute)
+	assert.ErrorIs(t, err, models.ErrBurrowHeld)
+	assert.ErrorIs(t, repo.RentBurrow("Burrow1", "bob"), models.ErrBurrowHeld)
+	assert.ErrorIs(t, repo.RentBurrows([]string{"Burrow1"}, "bob")[0], models.ErrBurrowHeld)
+
+	confirmed, err := repo.ConfirmHold(hold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Burrow1", confirmed.Burrow)
+	burrow, err := repo.GetBurrow("Burrow1")
+	assert.NoError(t, err)
+	assert.True(t, burrow.Occupied)
+	assert.Equal(t, "alice", burrow.RentedBy)
+
+	_, err = repo.ConfirmHold(hold.ID)
+	assert.ErrorIs(t, err, models.ErrHoldNotFound)
+}
+
+func TestMemoryRepository_HoldExpiry(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo.SetClock(clk)
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+	expiring, err := repo.HoldBurrow("Burrow1", "alice", time.Minute)
+	assert.NoError(t, err)
+	_, err = repo.HoldBurrow("Burrow2", "alice", time.Hour)
+	assert.NoError(t, err)
+
+	clk.Advance(time.Minute)
+	expired := repo.ReleaseExpiredHolds()
+	assert.Len(t, expired, 1)
+	assert.Equal(t, expiring.ID, expired[0].ID)
+
+	// Holds survive a restart.
+	assert.NoError(t, repo.SaveState())
+	restarted := repository.NewMemoryRepository(repo.GetStateFile(), "")
+	restarted.SetClock(clk)
+	assert.NoError(t, restarted.LoadState())
+	assert.ErrorIs(t, restarted.RentBurrow("Burrow2", "bob"), models.ErrBurrowHeld)
+	assert.NoError(t, restarted.RentBurrow("Burrow1", "bob"))
+
+	_, err = restarted.GetHold(expiring.ID)
+	assert.ErrorIs(t, err, models.ErrHoldNotFound)
+
+	// Expired holds cannot be confirmed, even before they are released.
+	clk.Advance(time.Hour)
+	hold, err := restarted.HoldBurrow("Burrow2", "bob", time.Minute)
+	assert.NoError(t, err)
+	clk.Advance(time.Minute)
+	_, err = restarted.ConfirmHold(hold.ID)
+	assert.ErrorIs(t, err, models.ErrHoldExpired)
+}
+
+func TestMemoryRepository_Waitlist(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo.SetClock(clk)
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0, Occupied: true, RentedBy: "alice"}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+
+	_, err := repo.JoinWaitlist("Burrow2", "bob")
+	assert.ErrorIs(t, err, models.ErrBurrowAvailable)
+	_, err = repo.JoinWaitlist("Nowhere", "bob")
+	assert.ErrorIs(t, err, models.ErrBurrowNotFound)
+
+	for i, renter := range []string{"bob", "carol", "dave"} {
+		position, err := repo.JoinWaitlist("Burrow1", renter)
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, position.Position)
+	}
+	_, err = repo.JoinWaitlist("Burrow1", "bob")
+	assert.ErrorIs(t, err, models.ErrAlreadyWaitlisted)
+
+	assert.NoError(t, repo.LeaveWaitlist("Burrow1", "carol"))
+	assert.ErrorIs(t, repo.LeaveWaitlist("Burrow1", "carol"), models.ErrNotWaitlisted)
+	position, err := repo.GetWaitlistPosition("Burrow1", "dave")
+	assert.NoError(t, err)
+	assert.Equal(t, &models.WaitlistPosition{Burrow: "Burrow1", Position: 2, Length: 2}, position)
+
+	// Nobody is served while the burrow is occupied.
+	hold, err := repo.HoldNextWaiter("Burrow1", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, hold)
+
+	// Once released, the burrow is reserved for the first renter of its waitlist.
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	assert.ErrorIs(t, repo.RentBurrow("Burrow1", "erin"), models.ErrBurrowUnavailable)
+
+	// The waitlists survive a restart.
+	assert.NoError(t, repo.SaveState())
+	restarted := repository.NewMemoryRepository(repo.GetStateFile(), "")
+	restarted.SetClock(clk)
+	assert.NoError(t, restarted.LoadState())
+
+	hold, err = restarted.HoldNextWaiter("Burrow1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", hold.Renter)
+	position, err = restarted.GetWaitlistPosition("Burrow1", "dave")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, position.Position)
+	assert.ErrorIs(t, restarted.RentBurrow("Burrow1", "dave"), models.ErrBurrowHeld)
+
+	// When the hold of bob expires, dave is next.
+	clk.Advance(time.Minute)
+	hold, err = restarted.HoldNextWaiter("Burrow1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "dave", hold.Renter)
+	_, err = restarted.GetWaitlistPosition("Burrow1", "dave")
+	assert.ErrorIs(t, err, models.ErrNotWaitlisted)
+}
+
+func TestMemoryRepository_Ledger(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo.SetClock(clk)
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+	collapseAge := models.CurrentLifecycle().CollapseAge
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow3", Depth: 1.0, Width: 1.0, Age: collapseAge - 1}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow4", Depth: 1.0, Width: 1.0}))
+	_, err := repo.AddRenter(&models.Renter{ID: "carol", Name: "Carol", Limits: models.RenterLimits{MaxConcurrentBurrows: 1}})
+	assert.NoError(t, err)
+	repo.SetQuoter(func(burrow *models.Burrow, occupancy float64) *models.Quote {
+		if burrow.Name == "Burrow2" {
+			return nil
+		}
+		return &models.Quote{Burrow: burrow.Name, Currency: "EUR", Price: 2400}
+	})
+	assert.NoError(t, repo.RentBurrow("Burrow1", "alice"))
+	assert.NoError(t, repo.RentBurrow("Burrow2", "bob"))
+	assert.NoError(t, repo.RentBurrow("Burrow3", "carol"))
+
+	// 2400 a day is 100 an hour, charged as it accrues. A rental without a quote is charged zero, once.
+	clk.Advance(time.Hour)
+	charges := repo.AccrueUsage()
+	assert.Len(t, charges, 3)
+	assert.Equal(t, int64(100), charges[0].Amount)
+	assert.Equal(t, models.EntryCharge, charges[0].Kind)
+	assert.Equal(t, "Burrow1", charges[0].Burrow)
+	assert.Equal(t, "bob", charges[1].Renter)
+	assert.Equal(t, int64(0), charges[1].Amount)
+	assert.Contains(t, charges[1].Description, "no quote")
+	assert.Empty(t, repo.AccrueUsage())
+	burrow, err := repo.GetBurrow("Burrow2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), burrow.Quote.Price)
+	assert.Equal(t, int64(0), repo.GetAllBurrows()[1].Quote.Price)
+	assert.ErrorIs(t, repo.RentBurrow("Burrow4", "carol"), models.ErrRenterOverQuota)
+
+	// A rental ends when its burrow collapses, charged until then.
+	clk.Advance(time.Hour)
+	for i := 0; i < 31; i++ {
+		repo.UpdateAllBurrows()
+	}
+	charges = repo.AccrueUsage()
+	assert.Len(t, charges, 2)
+	assert.Equal(t, "Burrow3", charges[1].Burrow)
+	assert.Equal(t, int64(50), charges[1].Amount)
+	assert.Equal(t, []int64{100, 50}, func() []int64 {
+		var amounts []int64
+		for _, entry := range repo.LedgerEntries("carol") {
+			amounts = append(amounts, entry.Amount)
+		}
+		return amounts
+	}())
+	assert.Empty(t, repo.AccrueUsage())
+
+	// The collapsed burrow is freed, and so is the quota of its renter.
+	burrow, err = repo.GetBurrow("Burrow3")
+	assert.NoError(t, err)
+	assert.False(t, burrow.Occupied)
+	assert.Empty(t, burrow.RentalID)
+	assert.NoError(t, repo.RentBurrow("Burrow4", "carol"))
+	assert.NoError(t, repo.ReleaseBurrow("Burrow4", ""))
+	assert.NoError(t, repo.DeleteRenter("carol"))
+
+	// The release charges the last usage.
+	clk.Advance(15 * time.Minute)
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	clk.Advance(time.Hour)
+	assert.Empty(t, repo.AccrueUsage())
+
+	rentalID := charges[0].RentalID
+	refund, err := repo.AppendLedgerEntry(&models.LedgerEntry{Renter: "alice", Kind: models.EntryRefund, Amount: -25, Currency: "EUR", RentalID: rentalID})
+	assert.NoError(t, err)
+	assert.Equal(t, "Burrow1", refund.Burrow)
+	_, err = repo.AppendLedgerEntry(&models.LedgerEntry{Renter: "bob", Kind: models.EntryRefund, Amount: -25, RentalID: rentalID})
+	assert.ErrorIs(t, err, models.ErrRentalNotFound)
+
+	amounts := func() []int64 {
+		var amounts []int64
+		for _, entry := range repo.LedgerEntries("alice") {
+			amounts = append(amounts, entry.Amount)
+		}
+		return amounts
+	}
+	assert.Equal(t, []int64{100, 100, 25, -25}, amounts())
+	assert.Len(t, repo.LedgerEntries("bob"), 1)
+
+	// The ledger and the rentals are persisted.
+	assert.NoError(t, repo.SaveState())
+	assert.NoError(t, repo.LoadState())
+	assert.Equal(t, []int64{100, 100, 25, -25}, amounts())
+	assert.Empty(t, repo.AccrueUsage())
+}
+
+func TestMemoryRepository_WaitlistQuota(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	repo.SetDefaultLimits(models.RenterLimits{MaxConcurrentBurrows: 1})
+
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow1", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: "Burrow2", Depth: 1.0, Width: 1.0}))
+	assert.NoError(t, repo.RentBurrow("Burrow1", "alice"))
+	assert.NoError(t, repo.RentBurrow("Burrow2", "bob"))
+	_, err := repo.JoinWaitlist("Burrow1", "bob")
+	assert.NoError(t, err)
+	_, err = repo.JoinWaitlist("Burrow1", "carol")
+	assert.NoError(t, err)
+
+	// The burrow is held for the first waiter within their quota, the others keep their place.
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	hold, err := repo.HoldNextWaiter("Burrow1", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, "carol", hold.Renter)
+	position, err := repo.GetWaitlistPosition("Burrow1", "bob")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, position.Position)
+
+	// Nobody is held for when every waiter is over quota.
+	_, err = repo.JoinWaitlist("Burrow2", "carol")
+	assert.NoError(t, err)
+	assert.NoError(t, repo.ReleaseBurrow("Burrow2", ""))
+	hold, err = repo.HoldNextWaiter("Burrow2", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, hold)
+
+	// Nor reserved for them: anybody can rent it.
+	assert.NoError(t, repo.RentBurrow("Burrow2", "dave"))
+
+	// Renters cannot wait for the burrows they rent or hold.
+	_, err = repo.JoinWaitlist("Burrow2", "dave")
+	assert.ErrorIs(t, err, models.ErrBurrowAlreadyTaken)
+	_, err = repo.JoinWaitlist("Burrow1", "carol")
+	assert.ErrorIs(t, err, models.ErrBurrowAlreadyTaken)
+}
+
+func TestMemoryRepository_Renters(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo.SetClock(clk)
+
+	for _, name := range []string{"Burrow1", "Burrow2", "Burrow3"} {
+		assert.NoError(t, repo.AddBurrow(&models.Burrow{Name: name, Depth: 1.0, Width: 1.0}))
+	}
+
+	alice := &models.Renter{ID: "alice", Name: "Alice", Contact: models.Contact{Channel: models.ContactEmail, Address: "alice@example.com"}, Limits: models.RenterLimits{MaxConcurrentBurrows: 1}}
+	added, err := repo.AddRenter(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, clk.Now(), added.CreatedAt)
+	_, err = repo.AddRenter(alice)
+	assert.ErrorIs(t, err, models.ErrRenterAlreadyExists)
+	_, err = repo.GetRenter("bob")
+	assert.ErrorIs(t, err, models.ErrRenterNotFound)
+
+	// Alice rents or holds at most one burrow, alone, in a batch or from a waitlist; renters
+	// without an account have the default limits.
+	assert.NoError(t, repo.RentBurrow("Burrow1", "alice"))
+	assert.ErrorIs(t, repo.RentBurrow("Burrow2", "alice"), models.ErrRenterOverQuota)
+	_, err = repo.HoldBurrow("Burrow2", "alice", time.Minute)
+	assert.ErrorIs(t, err, models.ErrRenterOverQuota)
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+	hold, err := repo.HoldBurrow("Burrow2", "alice", time.Minute)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, repo.RentBurrow("Burrow1", "alice"), models.ErrRenterOverQuota)
+	_, err = repo.ConfirmHold(hold.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.ReleaseBurrow("Burrow2", ""))
+	errs := repo.RentBurrows([]string{"Burrow1", "Burrow2"}, "alice")
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], models.ErrRenterOverQuota)
+	assert.NoError(t, repo.RentBurrows([]string{"Burrow2", "Burrow3"}, "bob")[1])
+
+	// Raising the limit lets her rent more.
+	clk.Advance(time.Hour)
+	alice.Limits.MaxConcurrentBurrows = 2
+	updated, err := repo.UpdateRenter(alice)
+	assert.NoError(t, err)
+	assert.Equal(t, clk.Now(), updated.UpdatedAt)
+	assert.NoError(t, repo.RentBurrow("Burrow1", "alice"))
+	assert.NoError(t, repo.ReleaseBurrow("Burrow1", ""))
+
+	rentals, err := repo.GetRentals("alice")
+	assert.NoError(t, err)
+	assert.Len(t, rentals, 3)
+	assert.NotNil(t, rentals[0].EndedAt)
+	assert.NotNil(t, rentals[1].EndedAt)
+	assert.NotNil(t, rentals[2].EndedAt)
+	rentals, err = repo.GetRentals("bob")
+	assert.NoError(t, err)
+	assert.Len(t, rentals, 2)
+	_, err = repo.GetRentals("carol")
+	assert.ErrorIs(t, err, models.ErrRenterNotFound)
+
+	// Renters are persisted, and only those renting no burrow can be deleted.
+	_, err = repo.AddRenter(&models.Renter{ID: "bob", Name: "Bob", Contact: models.Contact{Channel: models.ContactSMS, Address: "+14155550100"}})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SaveState())
+	assert.NoError(t, repo.LoadState())
+	renters := repo.GetAllRenters()
+	assert.Len(t, renters, 2)
+	assert.Equal(t, 2, renters[0].Limits.MaxConcurrentBurrows)
+	assert.ErrorIs(t, repo.DeleteRenter("bob"), models.ErrRenterHasRentals)
+	assert.NoError(t, repo.DeleteRenter("alice"))
+	assert.ErrorIs(t, repo.DeleteRenter("alice"), models.ErrRenterNotFound)
+	rentals, err = repo.GetRentals("alice")
+	assert.NoError(t, err)
+	assert.Len(t, rentals, 3)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// state is the content of the state file. Older state files hold the burrows only, as an array.
+type state struct {
+	Burrows   []*models.Burrow                   `json:"burrows"`
+	Holds     []*models.Hold                     `json:"holds"`
+	Waitlists map[string][]*models.WaitlistEntry `json:"waitlists"`
+	Rentals   []*models.Rental                   `json:"rentals"`
+	Ledger    []*models.LedgerEntry              `json:"ledger"`
+	Renters   []*models.Renter                   `json:"renters"`
+}
+
+// decodeState decodes a state file, in the current or the older format.
+func decodeState(data []byte) (state, error) {
+	var st state
+	if isLegacyState(data) {
+		err := json.Unmarshal(data, &st.Burrows)
+		return st, err
+	}
+
+	err := json.Unmarshal(data, &st)
+	return st, err
+}
+
+// StateBurrows returns the JSON array of the burrows of a state file, in the current or the older format.
+func StateBurrows(data []byte) ([]byte, error) {
+	if isLegacyState(data) {
+		return data, nil
+	}
+
+	var raw struct {
+		Burrows json.RawMessage `json:"burrows"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Burrows == nil {
+		return nil, errors.New("state file has no burrows")
+	}
+
+	return raw.Burrows, nil
+}
+
+func isLegacyState(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
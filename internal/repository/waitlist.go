@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// JoinWaitlist queues the renter for the named burrow, which must be occupied or held by another
+// renter, and returns the position of the renter.
+func (s *MemoryRepository) JoinWaitlist(name, renter string) (*models.WaitlistPosition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.waiterIndex(name, renter) >= 0 {
+		return nil, errors.WithMessage(models.ErrAlreadyWaitlisted, name)
+	}
+
+	if burrow, exists := s.burrows[name]; exists && burrow.Occupied && burrow.RentedBy == renter {
+		return nil, errors.WithMessage(models.ErrBurrowAlreadyTaken, name)
+	}
+	if hold := s.activeHold(name); hold != nil && hold.Renter == renter {
+		return nil, errors.WithMessage(models.ErrBurrowAlreadyTaken, name)
+	}
+
+	err := s.checkRentable(name, renter)
+	if err == nil {
+		return nil, errors.WithMessage(models.ErrBurrowAvailable, name)
+	}
+	if !errors.Is(err, models.ErrBurrowUnavailable) && !errors.Is(err, models.ErrBurrowHeld) {
+		return nil, err
+	}
+
+	s.waitlists[name] = append(s.waitlists[name], &models.WaitlistEntry{Renter: renter, JoinedAt: s.clock.Now()})
+
+	return s.position(name, renter), nil
+}
+
+// GetWaitlistPosition returns the position of the renter in the waitlist of the named burrow.
+func (s *MemoryRepository) GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.waiterIndex(name, renter) < 0 {
+		return nil, errors.WithMessage(models.ErrNotWaitlisted, name)
+	}
+
+	return s.position(name, renter), nil
+}
+
+// LeaveWaitlist removes the renter from the waitlist of the named burrow.
+func (s *MemoryRepository) LeaveWaitlist(name, renter string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.removeWaiter(name, renter) {
+		return errors.WithMessage(models.ErrNotWaitlisted, name)
+	}
+
+	return nil
+}
+
+// HoldNextWaiter holds the named burrow, when it is free, for the first renter of its waitlist
+// within their quota until ttl elapses, removing them from the waitlist. It returns nil when the
+// burrow is occupied, held or has no waitlist, or when every renter of its waitlist is over quota.
+func (s *MemoryRepository) HoldNextWaiter(name string, ttl time.Duration) (*models.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	waiters := s.waitlists[name]
+	burrow, exists := s.burrows[name]
+	if len(waiters) == 0 || !exists || burrow.Occupied || s.activeHold(name) != nil {
+		return nil, nil
+	}
+
+	if burrow.HasCollapsed() {
+		return nil, errors.WithMessage(models.ErrBurrowCollapsed, name)
+	}
+
+	next := s.nextWaiter(name)
+	if next == nil {
+		return nil, nil
+	}
+
+	s.removeWaiter(name, next.Renter)
+
+	return s.hold(name, next.Renter, ttl), nil
+}
+
+// nextWaiter returns the first renter of the waitlist of the named burrow within their quota, or
+// nil. s.mu must be held.
+func (s *MemoryRepository) nextWaiter(name string) *models.WaitlistEntry {
+	for _, waiter := range s.waitlists[name] {
+		if s.checkQuota(waiter.Renter, name) == nil {
+			return waiter
+		}
+	}
+
+	return nil
+}
+
+// position returns the position of a renter of the waitlist of the named burrow. s.mu must be held.
+func (s *MemoryRepository) position(name, renter string) *models.WaitlistPosition {
+	return &models.WaitlistPosition{
+		Burrow:   name,
+		Position: s.waiterIndex(name, renter) + 1,
+		Length:   len(s.waitlists[name]),
+	}
+}
+
+// waiterIndex returns the index of the renter in the waitlist of the named burrow, or -1.
+// s.mu must be held.
+func (s *MemoryRepository) waiterIndex(name, renter string) int {
+	for i, entry := range s.waitlists[name] {
+		if entry.Renter == renter {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// removeWaiter removes the renter from the waitlist of the named burrow and reports whether they
+// were in it. s.mu must be held.
+func (s *MemoryRepository) removeWaiter(name, renter string) bool {
+	i := s.waiterIndex(name, renter)
+	if i < 0 {
+		return false
+	}
+
+	waiters := append(s.waitlists[name][:i:i], s.waitlists[name][i+1:]...)
+	if len(waiters) == 0 {
+		delete(s.waitlists, name)
+	} else {
+		s.waitlists[name] = waiters
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+)
+
+// ErrCircuitOpen is returned when a call is rejected because the circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State of a CircuitBreaker.
+type State int
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = iota
+	// StateOpen rejects every call until the open timeout has elapsed.
+	StateOpen
+	// StateHalfOpen lets a single trial call through to probe the recovery.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker opens after FailureThreshold consecutive failures, and lets a
+// trial call through once OpenTimeout has elapsed.
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	clock            clock.Clock
+	onStateChange    func(from, to State)
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker. onStateChange, if not nil, is called
+// on every transition, outside the breaker lock.
+func NewCircuitBreaker(clk clock.Clock, failureThreshold int, openTimeout time.Duration, onStateChange func(from, to State)) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		clock:            clk,
+		onStateChange:    onStateChange,
+	}
+}
+
+// State returns the current state of the breaker.
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.currentState()
+}
+
+// Allow reports whether a call can go through. Every allowed call must be followed by
+// a call to Success or Failure.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	from := cb.state
+	to := cb.currentState()
+	cb.state = to
+
+	var err error
+	if to == StateOpen || (to == StateHalfOpen && cb.trial) {
+		err = ErrCircuitOpen
+	} else if to == StateHalfOpen {
+		cb.trial = true
+	}
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+
+	return err
+}
+
+// Success records a successful call and closes the breaker.
+func (cb *CircuitBreaker) Success() {
+	cb.mu.Lock()
+	from := cb.state
+	cb.state = StateClosed
+	cb.failures = 0
+	cb.trial = false
+	cb.mu.Unlock()
+
+	cb.notify(from, StateClosed)
+}
+
+// Failure records a failed call, opening the breaker when the threshold is reached
+// or when the half-open trial fails.
+func (cb *CircuitBreaker) Failure() {
+	cb.mu.Lock()
+	from := cb.state
+	cb.failures++
+	cb.trial = false
+	if from == StateHalfOpen || cb.failures >= cb.failureThreshold {
+		cb.state = StateOpen
+		cb.openedAt = cb.clock.Now()
+	}
+	to := cb.state
+	cb.mu.Unlock()
+
+	cb.notify(from, to)
+}
+
+// currentState must be called with the lock held.
+func (cb *CircuitBreaker) currentState() State {
+	if cb.state == StateOpen && !cb.clock.Now().Before(cb.openedAt.Add(cb.openTimeout)) {
+		return StateHalfOpen
+	}
+
+	return cb.state
+}
+
+func (cb *CircuitBreaker) notify(from, to State) {
+	if from != to && cb.onStateChange != nil {
+		cb.onStateChange(from, to)
+	}
+}
@@ -0,0 +1,130 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+)
+
+// RateLimiter is a token bucket rate limiter keeping one bucket per key.
+// Every bucket holds up to burst tokens and is refilled at requests tokens per period.
+type RateLimiter struct {
+	clock    clock.Clock
+	burst    float64
+	interval time.Duration // time to refill one token
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Decision is the outcome of RateLimiter.Allow.
+type Decision struct {
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// RetryAfter is the time until the next request is allowed, zero when Allowed.
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// NewRateLimiter creates a RateLimiter allowing requests per period, with bursts of up to burst requests.
+// A non positive period defaults to one second and a non positive burst to requests.
+func NewRateLimiter(clk clock.Clock, requests int, period time.Duration, burst int) *RateLimiter {
+	if requests <= 0 {
+		requests = 1
+	}
+	if period <= 0 {
+		period = time.Second
+	}
+	if burst <= 0 {
+		burst = requests
+	}
+
+	return &RateLimiter{
+		clock:     clk,
+		burst:     float64(burst),
+		interval:  period / time.Duration(requests),
+		buckets:   make(map[string]*bucket),
+		lastSweep: clk.Now(),
+	}
+}
+
+// Allow takes a token from the bucket of key, if one is available.
+func (l *RateLimiter) Allow(key string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = minFloat(l.burst, b.tokens+float64(elapsed)/float64(l.interval))
+	}
+	b.last = now
+
+	decision := Decision{Limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.refillTime(1 - b.tokens)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = l.refillTime(l.burst - b.tokens)
+
+	return decision
+}
+
+// refillTime returns the time needed to refill the given number of tokens.
+func (l *RateLimiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens * float64(l.interval))
+}
+
+// sweep drops the buckets that have been refilled completely, which behave like new ones,
+// so that the limiter does not grow with every key ever seen. It runs at most once per
+// full refill time. Must be called with the lock held.
+func (l *RateLimiter) sweep(now time.Time) {
+	fullRefill := l.refillTime(l.burst)
+	if now.Sub(l.lastSweep) < fullRefill {
+		return
+	}
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.refillTime(l.burst-b.tokens) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Len returns the number of buckets currently tracked.
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,150 @@
+package resilience_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/resilience"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	backoff := resilience.Backoff{InitialInterval: time.Second, MaxInterval: 5 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Second, backoff.Delay(1))
+	assert.Equal(t, 2*time.Second, backoff.Delay(2))
+	assert.Equal(t, 4*time.Second, backoff.Delay(3))
+	assert.Equal(t, 5*time.Second, backoff.Delay(4))
+}
+
+func TestRetry(t *testing.T) {
+	policy := resilience.Backoff{InitialInterval: time.Millisecond, Multiplier: 2, MaxAttempts: 3}
+
+	calls := 0
+	err := resilience.Retry(context.Background(), clock.New(), policy, func(ctx context.Context) error {
+		calls++
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = resilience.Retry(context.Background(), clock.New(), policy, func(ctx context.Context) error {
+		calls++
+		return resilience.Permanent(errors.New("fatal"))
+	})
+	assert.EqualError(t, err, "fatal")
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_StopsWhenContextIsDone(t *testing.T) {
+	policy := resilience.Backoff{InitialInterval: time.Hour, Multiplier: 2, MaxAttempts: 10}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := resilience.Retry(ctx, clock.New(), policy, func(ctx context.Context) error {
+		calls++
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "retries stopped after 1 attempt(s): context deadline exceeded: boom")
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_UnlimitedUntilContextIsDone(t *testing.T) {
+	policy := resilience.Backoff{InitialInterval: time.Millisecond, MaxAttempts: -1}
+
+	calls := 0
+	err := resilience.Retry(context.Background(), clock.New(), policy, func(ctx context.Context) error {
+		calls++
+		if calls < 20 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, calls)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var transitions []string
+	breaker := resilience.NewCircuitBreaker(clk, 2, time.Minute, func(from, to resilience.State) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	assert.NoError(t, breaker.Allow())
+	breaker.Failure()
+	assert.Equal(t, resilience.StateClosed, breaker.State())
+	breaker.Failure()
+	assert.Equal(t, resilience.StateOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), resilience.ErrCircuitOpen)
+
+	// After the open timeout a single trial call is let through.
+	clk.Advance(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), resilience.ErrCircuitOpen)
+
+	// A failed trial opens the circuit again, a successful one closes it.
+	breaker.Failure()
+	assert.Equal(t, resilience.StateOpen, breaker.State())
+	clk.Advance(time.Minute)
+	assert.NoError(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, resilience.StateClosed, breaker.State())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func TestRateLimiter(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := resilience.NewRateLimiter(clk, 2, time.Minute, 3)
+
+	// The burst is available right away.
+	for i := 2; i >= 0; i-- {
+		decision := limiter.Allow("client-a")
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, i, decision.Remaining)
+	}
+
+	decision := limiter.Allow("client-a")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 30*time.Second, decision.RetryAfter)
+	assert.Equal(t, 90*time.Second, decision.Reset)
+
+	// Every key has its own bucket.
+	assert.True(t, limiter.Allow("client-b").Allowed)
+
+	// Tokens are refilled at 2 per minute.
+	clk.Advance(29 * time.Second)
+	assert.False(t, limiter.Allow("client-a").Allowed)
+	clk.Advance(time.Second)
+	assert.True(t, limiter.Allow("client-a").Allowed)
+	assert.False(t, limiter.Allow("client-a").Allowed)
+}
+
+func TestRateLimiter_DropsRefilledBuckets(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := resilience.NewRateLimiter(clk, 1, time.Second, 1)
+
+	limiter.Allow("client-a")
+	limiter.Allow("client-b")
+	assert.Equal(t, 2, limiter.Len())
+
+	clk.Advance(time.Second)
+	limiter.Allow("client-c")
+	assert.Equal(t, 1, limiter.Len())
+}
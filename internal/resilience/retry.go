@@ -0,0 +1,108 @@
+package resilience
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+)
+
+// Backoff defines an exponential backoff retry policy.
+type Backoff struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two attempts.
+	MaxInterval time.Duration
+	// Multiplier grows the delay after every failed attempt.
+	Multiplier float64
+	// Jitter is the fraction (0..1) of every delay that is randomized.
+	Jitter float64
+	// MaxAttempts is the total number of attempts, the first one included. A negative value
+	// retries until the context is done.
+	MaxAttempts int
+}
+
+// Delay returns the delay to wait after the given failed attempt (starting from 1), jitter excluded.
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := float64(b.InitialInterval)
+	for i := 1; i < attempt; i++ {
+		delay *= b.Multiplier
+		if b.MaxInterval > 0 && delay >= float64(b.MaxInterval) {
+			return b.MaxInterval
+		}
+	}
+
+	return time.Duration(delay)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so that Retry stops retrying and returns it immediately.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// Retry calls op until it succeeds, returns an error marked with Permanent, the attempts are
+// exhausted or ctx is done. The returned error is the last one returned by op, annotated with
+// the context error when ctx ended the retries.
+func Retry(ctx context.Context, clk clock.Clock, policy Backoff, op func(ctx context.Context) error) error {
+	attempts := policy.MaxAttempts
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(ctx); err == nil {
+			return nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		if attempts > 0 && attempt >= attempts {
+			return err
+		}
+
+		timer := clk.NewTimer(withJitter(policy.Delay(attempt), policy.Jitter))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.WithMessagef(err, "retries stopped after %d attempt(s): %v", attempt, ctx.Err())
+		}
+	}
+}
+
+func withJitter(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	// Randomize the delay in [delay*(1-jitter), delay*(1+jitter)).
+	spread := float64(delay) * jitter
+	//nolint:gosec
+	return time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+}
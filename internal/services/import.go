@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/burrowio"
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// ImportMode decides what happens to the imported burrows whose name already exists.
+type ImportMode string
+
+const (
+	// ImportCreate fails the rows of existing burrows.
+	ImportCreate ImportMode = "create"
+	// ImportUpsert updates the depth, width and age of existing burrows.
+	ImportUpsert ImportMode = "upsert"
+	// ImportSkipExisting skips the rows of existing burrows.
+	ImportSkipExisting ImportMode = "skip-existing"
+)
+
+// ImportModes lists the import modes.
+var ImportModes = []ImportMode{ImportCreate, ImportUpsert, ImportSkipExisting}
+
+// ParseImportMode returns the import mode named s.
+func ParseImportMode(s string) (ImportMode, error) {
+	for _, mode := range ImportModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown import mode %q, expected one of %v", s, ImportModes)
+}
+
+// ImportOutcome is what happened to an imported row.
+type ImportOutcome string
+
+const (
+	ImportCreated ImportOutcome = "created"
+	ImportUpdated ImportOutcome = "updated"
+	ImportSkipped ImportOutcome = "skipped"
+	ImportFailed  ImportOutcome = "failed"
+)
+
+// ImportRow is the outcome of a row of an import. Err is set when the row failed.
+type ImportRow struct {
+	Row     int
+	Name    string
+	Outcome ImportOutcome
+	Err     error
+}
+
+// ImportResult is the outcome of every row of an import.
+type ImportResult struct {
+	Rows []ImportRow
+}
+
+// Count returns the number of rows having the outcome.
+func (r ImportResult) Count(outcome ImportOutcome) int {
+	count := 0
+	for _, row := range r.Rows {
+		if row.Outcome == outcome {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Failures returns the failed rows.
+func (r ImportResult) Failures() []ImportRow {
+	var failures []ImportRow
+	for _, row := range r.Rows {
+		if row.Outcome == ImportFailed {
+			failures = append(failures, row)
+		}
+	}
+
+	return failures
+}
+
+// ImportBurrows adds the burrows of the records through AddBurrow, handling the existing burrows
+// as mode says. Every row succeeds or fails on its own. With dryRun, nothing is changed: the rows
+// are checked against the burrows of GetAllBurrows and the rows before them.
+// The import stops between two rows once ctx is done, returning the rows imported so far and the
+// error of ctx.
+func (s *DefaultBurrowService) ImportBurrows(ctx context.Context, records []burrowio.Record, mode ImportMode, dryRun bool) (ImportResult, error) {
+	var existing map[string]bool
+	if dryRun {
+		existing = make(map[string]bool)
+		for _, burrow := range s.GetAllBurrows() {
+			existing[burrow.Name] = true
+		}
+	}
+
+	result := ImportResult{Rows: make([]ImportRow, 0, len(records))}
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return result, errors.Wrapf(err, "import stopped before row %d", record.Row)
+		}
+
+		row := ImportRow{Row: record.Row}
+		if record.Burrow != nil {
+			row.Name = record.Burrow.Name
+		}
+
+		row.Outcome, row.Err = s.importRecord(record, mode, existing)
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// importRecord imports a record, or checks it against the existing burrow names when not nil.
+func (s *DefaultBurrowService) importRecord(record burrowio.Record, mode ImportMode, existing map[string]bool) (ImportOutcome, error) {
+	if record.Err != nil {
+		return ImportFailed, errors.WithMessage(models.ErrInvalidBurrow, record.Err.Error())
+	}
+
+	burrow := record.Burrow
+	if existing != nil {
+		if err := validateBurrow(burrow); err != nil {
+			return ImportFailed, err
+		}
+		if !existing[burrow.Name] {
+			existing[burrow.Name] = true
+			return ImportCreated, nil
+		}
+		return existingOutcome(mode, burrow.Name, nil)
+	}
+
+	err := s.AddBurrow(burrow)
+	if err == nil {
+		return ImportCreated, nil
+	}
+	if !errors.Is(err, models.ErrBurrowAlreadyExists) {
+		return ImportFailed, err
+	}
+
+	return existingOutcome(mode, burrow.Name, func() error { return s.UpdateBurrow(burrow) })
+}
+
+// existingOutcome returns the outcome of a row of an existing burrow, updating it with update in
+// upsert mode.
+func existingOutcome(mode ImportMode, name string, update func() error) (ImportOutcome, error) {
+	switch mode {
+	case ImportSkipExisting:
+		return ImportSkipped, nil
+	case ImportUpsert:
+		if update != nil {
+			if err := update(); err != nil {
+				return ImportFailed, err
+			}
+		}
+		return ImportUpdated, nil
+	default:
+		return ImportFailed, errors.WithMessage(models.ErrBurrowAlreadyExists, name)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"net/mail"
+	"net/url"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/models"
+)
+
+// phonePattern matches the phone numbers of the sms contacts, in E.164 format.
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// AddRenter adds a new renter account through the repository.
+func (s *DefaultBurrowService) AddRenter(renter *models.Renter) (*models.Renter, error) {
+	if err := validateRenter(renter); err != nil {
+		return nil, err
+	}
+
+	return s.repo.AddRenter(renter)
+}
+
+// GetRenter returns the renter having the ID through the repository.
+func (s *DefaultBurrowService) GetRenter(id string) (*models.Renter, error) {
+	return s.repo.GetRenter(id)
+}
+
+// GetAllRenters returns the renters through the repository.
+func (s *DefaultBurrowService) GetAllRenters() []*models.Renter {
+	return s.repo.GetAllRenters()
+}
+
+// UpdateRenter replaces the name, contact and limits of an existing renter through the repository.
+func (s *DefaultBurrowService) UpdateRenter(renter *models.Renter) (*models.Renter, error) {
+	if err := validateRenter(renter); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateRenter(renter)
+}
+
+// DeleteRenter deletes a renter who does not rent any burrow through the repository.
+func (s *DefaultBurrowService) DeleteRenter(id string) error {
+	return s.repo.DeleteRenter(id)
+}
+
+// GetRentals returns the current and past rentals of the renter through the repository, oldest first.
+func (s *DefaultBurrowService) GetRentals(renter string) ([]*models.Rental, error) {
+	return s.repo.GetRentals(renter)
+}
+
+// GetBurrowRentals returns the current and past rentals of the named burrow through the
+// repository, oldest first.
+func (s *DefaultBurrowService) GetBurrowRentals(name string) ([]*models.Rental, error) {
+	return s.repo.GetBurrowRentals(name)
+}
+
+// validateRenter checks that the renter has an ID, a name, a contact address valid for its
+// channel and limits that are not negative.
+func validateRenter(renter *models.Renter) error {
+	if renter.ID == "" || renter.Name == "" {
+		return errors.WithMessage(models.ErrInvalidRenter, "id and name are required")
+	}
+
+	address := renter.Contact.Address
+	switch renter.Contact.Channel {
+	case models.ContactEmail:
+		if _, err := mail.ParseAddress(address); err != nil {
+			return errors.WithMessage(models.ErrInvalidRenter, "contact address must be an email address")
+		}
+	case models.ContactSMS:
+		if !phonePattern.MatchString(address) {
+			return errors.WithMessage(models.ErrInvalidRenter, "contact address must be a phone number in E.164 format")
+		}
+	case models.ContactWebhook:
+		if u, err := url.Parse(address); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return errors.WithMessage(models.ErrInvalidRenter, "contact address must be an http or https URL")
+		}
+	default:
+		return errors.WithMessage(models.ErrInvalidRenter, "contact channel must be email, sms or webhook")
+	}
+
+	if renter.Limits.MaxConcurrentBurrows < 0 {
+		return errors.WithMessage(models.ErrInvalidRenter, "limits must not be negative")
+	}
+
+	return nil
+}
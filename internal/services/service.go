@@ -1,17 +1,44 @@
 package services
 
 import (
-	"fmt"
 	"math"
+	"sync/atomic"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/events"
 	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/pricing"
 	"github.com/marcodd23/gopernet/internal/repository"
 )
 
 type GopherService interface {
 	LoadInitialState() error
 	GetAllBurrows() []*models.Burrow
-	RentBurrow(name string) error
+	GetBurrow(name string) (*models.Burrow, error)
+	RentBurrow(name, renter string) error
+	ReleaseBurrow(name, renter string) error
+	RentBurrows(names []string, renter string) []error
+	ReleaseBurrows(names []string, renter string) []error
+	AddBurrow(burrow *models.Burrow) error
+	HoldBurrow(name, renter string) (*models.Hold, error)
+	ConfirmHold(id string) (*models.Hold, error)
+	ReleaseExpiredHolds() []*models.Hold
+	JoinWaitlist(name, renter string) (*models.WaitlistPosition, error)
+	GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error)
+	LeaveWaitlist(name, renter string) error
+	QuoteBurrow(name string) (*models.Quote, error)
+	AccrueUsage() []*models.LedgerEntry
+	AddLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error)
+	LedgerEntries(renter string) []*models.LedgerEntry
+	AddRenter(renter *models.Renter) (*models.Renter, error)
+	GetRenter(id string) (*models.Renter, error)
+	GetAllRenters() []*models.Renter
+	UpdateRenter(renter *models.Renter) (*models.Renter, error)
+	DeleteRenter(id string) error
+	GetRentals(renter string) ([]*models.Rental, error)
+	GetBurrowRentals(name string) ([]*models.Rental, error)
 	GenerateReport() (string, error)
 	SaveState() error
 	SaveReport() error
@@ -19,11 +46,42 @@ type GopherService interface {
 }
 
 type DefaultBurrowService struct {
-	repo repository.StatefulRepository
+	repo    repository.StatefulRepository
+	bus     *events.Bus
+	pricing *pricing.Engine
+	holdTTL atomic.Int64
+
+	reportFormat atomic.Pointer[string]
 }
 
 func NewGopherNetService(repo repository.StatefulRepository) *DefaultBurrowService {
-	return &DefaultBurrowService{repo: repo}
+	s := &DefaultBurrowService{repo: repo}
+	s.holdTTL.Store(int64(models.DefaultHoldTTL))
+	s.SetReportFormat(models.ReportFormatText)
+
+	return s
+}
+
+// SetHoldTTL changes the duration of the next holds.
+func (s *DefaultBurrowService) SetHoldTTL(ttl time.Duration) {
+	s.holdTTL.Store(int64(ttl))
+}
+
+// SetReportFormat changes the format of the next saved reports, one of models.ReportFormats.
+func (s *DefaultBurrowService) SetReportFormat(format string) {
+	s.reportFormat.Store(&format)
+}
+
+// SetEventBus makes the service publish the burrow changes on the bus.
+func (s *DefaultBurrowService) SetEventBus(bus *events.Bus) {
+	s.bus = bus
+}
+
+// SetPricing makes the service quote the burrows with the engine, and the repository quote every
+// new rental with it while renting.
+func (s *DefaultBurrowService) SetPricing(engine *pricing.Engine) {
+	s.pricing = engine
+	s.repo.SetQuoter(engine.Quote)
 }
 
 // LoadInitialState loads the initial state through the repository.
@@ -36,19 +94,242 @@ func (s *DefaultBurrowService) GetAllBurrows() []*models.Burrow {
 	return s.repo.GetAllBurrows()
 }
 
-// RentBurrow rents a burrow through the repository.
-func (s *DefaultBurrowService) RentBurrow(name string) error {
-	return s.repo.RentBurrow(name)
+// GetBurrow returns the named burrow through the repository.
+func (s *DefaultBurrowService) GetBurrow(name string) (*models.Burrow, error) {
+	return s.repo.GetBurrow(name)
+}
+
+// RentBurrow rents a burrow on behalf of the renter through the repository.
+func (s *DefaultBurrowService) RentBurrow(name, renter string) error {
+	if err := s.repo.RentBurrow(name, renter); err != nil {
+		return err
+	}
+
+	s.publishBurrowChanged(name)
+
+	return nil
+}
+
+// ReleaseBurrow frees an occupied burrow through the repository, holding it for the first renter
+// of its waitlist. When renter is not empty, the burrow must be rented by renter.
+func (s *DefaultBurrowService) ReleaseBurrow(name, renter string) error {
+	if err := s.repo.ReleaseBurrow(name, renter); err != nil {
+		return err
+	}
+
+	s.publishBurrowChanged(name)
+	s.promoteWaiter(name)
+
+	return nil
+}
+
+// RentBurrows rents every named burrow to the renter through the repository, or none of them
+// when one cannot be rented. The errors are indexed like names and are all nil on success.
+func (s *DefaultBurrowService) RentBurrows(names []string, renter string) []error {
+	errs := s.repo.RentBurrows(names, renter)
+	s.publishBatchChanged(names, errs)
+
+	return errs
+}
+
+// ReleaseBurrows frees every named burrow through the repository, or none of them when one is
+// not rented, or not by renter when it is not empty. The errors are indexed like names and are all
+// nil on success.
+func (s *DefaultBurrowService) ReleaseBurrows(names []string, renter string) []error {
+	errs := s.repo.ReleaseBurrows(names, renter)
+	if s.publishBatchChanged(names, errs) {
+		for _, name := range names {
+			s.promoteWaiter(name)
+		}
+	}
+
+	return errs
+}
+
+// HoldBurrow holds an available burrow for the renter, blocking the other renters until the hold
+// expires or is confirmed.
+func (s *DefaultBurrowService) HoldBurrow(name, renter string) (*models.Hold, error) {
+	hold, err := s.repo.HoldBurrow(name, renter, time.Duration(s.holdTTL.Load()))
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events.HoldCreated, hold)
+
+	return hold, nil
+}
+
+// GetHold returns the hold having the ID through the repository.
+func (s *DefaultBurrowService) GetHold(id string) (*models.Hold, error) {
+	return s.repo.GetHold(id)
 }
 
-// GenerateReport generates a report of the current state of the burrows.
+// ConfirmHold rents the held burrow to the renter of the hold.
+func (s *DefaultBurrowService) ConfirmHold(id string) (*models.Hold, error) {
+	hold, err := s.repo.ConfirmHold(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishBurrowChanged(hold.Burrow)
+
+	return hold, nil
+}
+
+// ReleaseExpiredHolds releases the expired holds through the repository and returns them.
+func (s *DefaultBurrowService) ReleaseExpiredHolds() []*models.Hold {
+	expired := s.repo.ReleaseExpiredHolds()
+	for _, hold := range expired {
+		s.publish(events.HoldExpired, hold)
+		s.promoteWaiter(hold.Burrow)
+	}
+
+	return expired
+}
+
+// JoinWaitlist queues the renter for an occupied or held burrow through the repository.
+func (s *DefaultBurrowService) JoinWaitlist(name, renter string) (*models.WaitlistPosition, error) {
+	return s.repo.JoinWaitlist(name, renter)
+}
+
+// GetWaitlistPosition returns the position of the renter in the waitlist of the burrow through the repository.
+func (s *DefaultBurrowService) GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error) {
+	return s.repo.GetWaitlistPosition(name, renter)
+}
+
+// LeaveWaitlist removes the renter from the waitlist of the burrow through the repository.
+func (s *DefaultBurrowService) LeaveWaitlist(name, renter string) error {
+	if err := s.repo.LeaveWaitlist(name, renter); err != nil {
+		return err
+	}
+
+	// The renter may have been next for a burrow that was just released.
+	s.promoteWaiter(name)
+
+	return nil
+}
+
+// promoteWaiter holds the named burrow for the first renter of its waitlist when it is free,
+// publishing the hold.
+func (s *DefaultBurrowService) promoteWaiter(name string) {
+	hold, err := s.repo.HoldNextWaiter(name, time.Duration(s.holdTTL.Load()))
+	if err != nil || hold == nil {
+		return
+	}
+
+	s.publish(events.WaitlistPromoted, hold)
+}
+
+// QuoteBurrow returns the daily price of renting the named burrow. The rental of the burrow itself
+// does not count in the occupancy, so that its quote matches the one given before it was rented.
+func (s *DefaultBurrowService) QuoteBurrow(name string) (*models.Quote, error) {
+	if s.pricing == nil {
+		return nil, errors.WithMessage(models.ErrPricingUnavailable, name)
+	}
+
+	burrow, err := s.repo.GetBurrow(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if burrow.HasCollapsed() {
+		return nil, errors.WithMessage(models.ErrBurrowCollapsed, name)
+	}
+
+	return s.pricing.Quote(burrow, s.repo.Occupancy(name)), nil
+}
+
+// AccrueUsage charges the current rentals for their usage through the repository, and returns the charges.
+func (s *DefaultBurrowService) AccrueUsage() []*models.LedgerEntry {
+	return s.repo.AccrueUsage()
+}
+
+// AddLedgerEntry appends a refund or an adjustment to the ledger of its renter through the
+// repository. The currency of the pricing is used when the entry has none.
+func (s *DefaultBurrowService) AddLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error) {
+	if err := validateLedgerEntry(entry); err != nil {
+		return nil, err
+	}
+
+	if entry.Currency == "" && s.pricing != nil {
+		entry.Currency = s.pricing.Currency()
+	}
+
+	return s.repo.AppendLedgerEntry(entry)
+}
+
+// LedgerEntries returns the ledger entries of the renter through the repository, oldest first.
+func (s *DefaultBurrowService) LedgerEntries(renter string) []*models.LedgerEntry {
+	return s.repo.LedgerEntries(renter)
+}
+
+// validateLedgerEntry checks that the entry is a negative refund or a non zero adjustment: charges
+// are only accrued from the rentals.
+func validateLedgerEntry(entry *models.LedgerEntry) error {
+	switch {
+	case entry.Kind == models.EntryRefund && entry.Amount >= 0:
+		return errors.WithMessage(models.ErrInvalidLedgerEntry, "the amount of a refund must be negative")
+	case entry.Kind == models.EntryAdjustment && entry.Amount == 0:
+		return errors.WithMessage(models.ErrInvalidLedgerEntry, "the amount of an adjustment must not be zero")
+	case entry.Kind != models.EntryRefund && entry.Kind != models.EntryAdjustment:
+		return errors.WithMessage(models.ErrInvalidLedgerEntry, "kind must be refund or adjustment")
+	}
+
+	return nil
+}
+
+// AddBurrow adds a new burrow through the repository.
+func (s *DefaultBurrowService) AddBurrow(burrow *models.Burrow) error {
+	if err := validateBurrow(burrow); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddBurrow(burrow); err != nil {
+		return err
+	}
+
+	s.publishBurrowChanged(burrow.Name)
+
+	return nil
+}
+
+// UpdateBurrow replaces the depth, width and age of an existing burrow, keeping its rental.
+func (s *DefaultBurrowService) UpdateBurrow(burrow *models.Burrow) error {
+	if err := validateBurrow(burrow); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateBurrow(burrow); err != nil {
+		return err
+	}
+
+	s.publishBurrowChanged(burrow.Name)
+
+	return nil
+}
+
+func validateBurrow(burrow *models.Burrow) error {
+	if burrow.Name == "" {
+		return errors.WithMessage(models.ErrInvalidBurrow, "name is required")
+	}
+
+	if burrow.Depth < 0 || burrow.Width < 0 || burrow.Age < 0 {
+		return errors.WithMessage(models.ErrInvalidBurrow, "dimensions and age must not be negative")
+	}
+
+	return nil
+}
+
+// GenerateReport generates a report of the current state of the burrows, as text.
 func (s *DefaultBurrowService) GenerateReport() (string, error) {
+	return s.buildReport().Format(models.ReportFormatText)
+}
+
+// buildReport sums up the current state of the burrows.
+func (s *DefaultBurrowService) buildReport() *models.Report {
 	burrows := s.repo.GetAllBurrows()
 
-	totalDepth := 0.0
-	availableBurrows := 0
-	var largestBurrow, smallestBurrow *models.Burrow
-	largestVolume := 0.0
+	report := &models.Report{}
 	smallestVolume := math.MaxFloat64
 
 	// Iterate over all burrows to calculate the report metrics.
@@ -58,44 +339,24 @@ func (s *DefaultBurrowService) GenerateReport() (string, error) {
 			continue
 		}
 
-		totalDepth += burrow.Depth
+		report.TotalDepth += burrow.Depth
 
 		if !burrow.Occupied && !burrow.HasCollapsed() {
-			availableBurrows++
+			report.AvailableBurrows++
 		}
 
-		// Calculate the volume of the burrow (cylindrical volume formula: V = pi * r^2 * h).
-		radius := burrow.Width / 2
-		volume := math.Pi * radius * radius * burrow.Depth
+		volume := burrow.Volume()
 
-		if volume > largestVolume {
-			largestVolume = volume
-			largestBurrow = burrow
+		if report.Largest == nil || volume > report.Largest.Volume {
+			report.Largest = &models.ReportBurrow{Name: burrow.Name, Volume: volume}
 		}
 		if volume < smallestVolume {
 			smallestVolume = volume
-			smallestBurrow = burrow
+			report.Smallest = &models.ReportBurrow{Name: burrow.Name, Volume: volume}
 		}
 	}
 
-	// Create the report string
-	report := fmt.Sprintf("GopherNet Burrow Report\n\n")
-	report += fmt.Sprintf("Total Depth of all Burrows: %.2f meters\n", totalDepth)
-	report += fmt.Sprintf("Number of Available Burrows: %d\n", availableBurrows)
-
-	if largestBurrow != nil {
-		report += fmt.Sprintf("Largest Burrow by Volume: %s (%.2f cubic meters)\n", largestBurrow.Name, largestVolume)
-	} else {
-		report += "Largest Burrow by Volume: N/A\n"
-	}
-
-	if smallestBurrow != nil {
-		report += fmt.Sprintf("Smallest Burrow by Volume: %s (%.2f cubic meters)\n", smallestBurrow.Name, smallestVolume)
-	} else {
-		report += "Smallest Burrow by Volume: N/A\n"
-	}
-
-	return report, nil
+	return report
 }
 
 // SaveState instructs the repository to save the current state.
@@ -103,17 +364,100 @@ func (s *DefaultBurrowService) SaveState() error {
 	return s.repo.SaveState()
 }
 
-// SaveReport generates the report and instructs the repository to save it.
+// Backup returns the whole state as a state document, in the format of the state file.
+func (s *DefaultBurrowService) Backup() ([]byte, error) {
+	return s.repo.Backup()
+}
+
+// RestoreBackup replaces the whole state by the backup, a state document returned by Backup or
+// read from the state file, and publishes the update of every burrow.
+func (s *DefaultBurrowService) RestoreBackup(backup []byte) (*models.RestoreSummary, error) {
+	summary, err := s.repo.RestoreState(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.bus != nil {
+		s.bus.Publish(events.Event{Type: events.BurrowsUpdated})
+	}
+
+	return summary, nil
+}
+
+// SaveReport generates the report in the format set by SetReportFormat and instructs the
+// repository to save it, publishing it when an event bus is set.
 func (s *DefaultBurrowService) SaveReport() error {
-	report, err := s.GenerateReport()
+	report, err := s.buildReport().Format(*s.reportFormat.Load())
 	if err != nil {
 		return err
 	}
 
-	return s.repo.SaveReport(report)
+	if err := s.repo.SaveReport(report); err != nil {
+		return err
+	}
+
+	if s.bus != nil {
+		s.bus.Publish(events.Event{Type: events.ReportGenerated, Data: report})
+	}
+
+	return nil
 }
 
 // UpdateBurrows triggers an update of all burrows through the repository.
 func (s *DefaultBurrowService) UpdateBurrows() {
 	s.repo.UpdateAllBurrows()
+
+	if s.bus != nil {
+		s.bus.Publish(events.Event{Type: events.BurrowsUpdated})
+	}
+}
+
+// publishBurrowChanged publishes the current state of the named burrow, when an event bus is set.
+func (s *DefaultBurrowService) publishBurrowChanged(name string) {
+	if s.bus == nil {
+		return
+	}
+
+	burrow, err := s.repo.GetBurrow(name)
+	if err != nil {
+		return
+	}
+
+	s.bus.Publish(events.Event{Type: events.BurrowChanged, Data: burrow})
+}
+
+// publishBatchChanged publishes the named burrows once, unless the batch failed, and reports
+// whether it succeeded.
+func (s *DefaultBurrowService) publishBatchChanged(names []string, errs []error) bool {
+	if !batchSucceeded(errs) {
+		return false
+	}
+
+	published := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !published[name] {
+			published[name] = true
+			s.publishBurrowChanged(name)
+		}
+	}
+
+	return true
+}
+
+// batchSucceeded reports whether every item of a batch succeeded.
+func batchSucceeded(errs []error) bool {
+	for _, err := range errs {
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// publish publishes an event, when an event bus is set.
+func (s *DefaultBurrowService) publish(eventType events.Type, data interface{}) {
+	if s.bus != nil {
+		s.bus.Publish(events.Event{Type: eventType, Data: data})
+	}
 }
@@ -1,12 +1,16 @@
 package services_test
 
 import (
+	"encoding/json"
+	"github.com/marcodd23/gopernet/internal/events"
 	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
 	"github.com/marcodd23/gopernet/internal/services"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 type MockStatefulRepository struct {
@@ -23,8 +27,108 @@ func (m *MockStatefulRepository) GetAllBurrows() []*models.Burrow {
 	return args.Get(0).([]*models.Burrow)
 }
 
-func (m *MockStatefulRepository) RentBurrow(name string) error {
+func (m *MockStatefulRepository) GetBurrow(name string) (*models.Burrow, error) {
 	args := m.Called(name)
+	burrow, _ := args.Get(0).(*models.Burrow)
+	return burrow, args.Error(1)
+}
+
+func (m *MockStatefulRepository) RentBurrow(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockStatefulRepository) ReleaseBurrow(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockStatefulRepository) RentBurrows(names []string, renter string) []error {
+	args := m.Called(names, renter)
+	return args.Get(0).([]error)
+}
+
+func (m *MockStatefulRepository) ReleaseBurrows(names []string, renter string) []error {
+	args := m.Called(names, renter)
+	return args.Get(0).([]error)
+}
+
+func (m *MockStatefulRepository) SetQuoter(quote repository.QuoteFunc) {
+	m.Called(quote)
+}
+
+func (m *MockStatefulRepository) Occupancy(name string) float64 {
+	args := m.Called(name)
+	return args.Get(0).(float64)
+}
+
+func (m *MockStatefulRepository) AccrueUsage() []*models.LedgerEntry {
+	args := m.Called()
+	entries, _ := args.Get(0).([]*models.LedgerEntry)
+	return entries
+}
+
+func (m *MockStatefulRepository) AppendLedgerEntry(entry *models.LedgerEntry) (*models.LedgerEntry, error) {
+	args := m.Called(entry)
+	appended, _ := args.Get(0).(*models.LedgerEntry)
+	return appended, args.Error(1)
+}
+
+func (m *MockStatefulRepository) LedgerEntries(renter string) []*models.LedgerEntry {
+	args := m.Called(renter)
+	entries, _ := args.Get(0).([]*models.LedgerEntry)
+	return entries
+}
+
+func (m *MockStatefulRepository) HoldBurrow(name, renter string, ttl time.Duration) (*models.Hold, error) {
+	args := m.Called(name, renter, ttl)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockStatefulRepository) GetHold(id string) (*models.Hold, error) {
+	args := m.Called(id)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockStatefulRepository) ConfirmHold(id string) (*models.Hold, error) {
+	args := m.Called(id)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockStatefulRepository) ReleaseExpiredHolds() []*models.Hold {
+	args := m.Called()
+	holds, _ := args.Get(0).([]*models.Hold)
+	return holds
+}
+
+func (m *MockStatefulRepository) JoinWaitlist(name, renter string) (*models.WaitlistPosition, error) {
+	args := m.Called(name, renter)
+	position, _ := args.Get(0).(*models.WaitlistPosition)
+	return position, args.Error(1)
+}
+
+func (m *MockStatefulRepository) GetWaitlistPosition(name, renter string) (*models.WaitlistPosition, error) {
+	args := m.Called(name, renter)
+	position, _ := args.Get(0).(*models.WaitlistPosition)
+	return position, args.Error(1)
+}
+
+func (m *MockStatefulRepository) LeaveWaitlist(name, renter string) error {
+	args := m.Called(name, renter)
+	return args.Error(0)
+}
+
+func (m *MockStatefulRepository) HoldNextWaiter(name string, ttl time.Duration) (*models.Hold, error) {
+	args := m.Called(name, ttl)
+	hold, _ := args.Get(0).(*models.Hold)
+	return hold, args.Error(1)
+}
+
+func (m *MockStatefulRepository) UpdateBurrow(burrow *models.Burrow) error {
+	args := m.Called(burrow)
 	return args.Error(0)
 }
 
@@ -33,6 +137,18 @@ func (m *MockStatefulRepository) SaveState() error {
 	return args.Error(0)
 }
 
+func (m *MockStatefulRepository) Backup() ([]byte, error) {
+	args := m.Called()
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+func (m *MockStatefulRepository) RestoreState(backup []byte) (*models.RestoreSummary, error) {
+	args := m.Called(backup)
+	summary, _ := args.Get(0).(*models.RestoreSummary)
+	return summary, args.Error(1)
+}
+
 func (m *MockStatefulRepository) SaveReport(report string) error {
 	args := m.Called(report)
 	return args.Error(0)
@@ -51,8 +167,50 @@ func (m *MockStatefulRepository) GetReportFile() string {
 	return args.String(0)
 }
 
-func (m *MockStatefulRepository) AddBurrow(burrow *models.Burrow) {
-	m.Called(burrow)
+func (m *MockStatefulRepository) AddRenter(renter *models.Renter) (*models.Renter, error) {
+	args := m.Called(renter)
+	added, _ := args.Get(0).(*models.Renter)
+	return added, args.Error(1)
+}
+
+func (m *MockStatefulRepository) GetRenter(id string) (*models.Renter, error) {
+	args := m.Called(id)
+	renter, _ := args.Get(0).(*models.Renter)
+	return renter, args.Error(1)
+}
+
+func (m *MockStatefulRepository) GetAllRenters() []*models.Renter {
+	args := m.Called()
+	renters, _ := args.Get(0).([]*models.Renter)
+	return renters
+}
+
+func (m *MockStatefulRepository) UpdateRenter(renter *models.Renter) (*models.Renter, error) {
+	args := m.Called(renter)
+	updated, _ := args.Get(0).(*models.Renter)
+	return updated, args.Error(1)
+}
+
+func (m *MockStatefulRepository) DeleteRenter(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStatefulRepository) GetRentals(renter string) ([]*models.Rental, error) {
+	args := m.Called(renter)
+	rentals, _ := args.Get(0).([]*models.Rental)
+	return rentals, args.Error(1)
+}
+
+func (m *MockStatefulRepository) GetBurrowRentals(name string) ([]*models.Rental, error) {
+	args := m.Called(name)
+	rentals, _ := args.Get(0).([]*models.Rental)
+	return rentals, args.Error(1)
+}
+
+func (m *MockStatefulRepository) AddBurrow(burrow *models.Burrow) error {
+	args := m.Called(burrow)
+	return args.Error(0)
 }
 
 func TestGopherNetService_LoadInitialState(t *testing.T) {
@@ -93,16 +251,29 @@ func TestGopherNetService_RentBurrow(t *testing.T) {
 	service := services.NewGopherNetService(mockRepo)
 
 	// Setup the mock expectation
-	mockRepo.On("RentBurrow", "Burrow1").Return(nil)
+	mockRepo.On("RentBurrow", "Burrow1", "renter-1").Return(nil)
 
 	// Call the method
-	err := service.RentBurrow("Burrow1")
+	err := service.RentBurrow("Burrow1", "renter-1")
 
 	// Assert expectations
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGopherNetService_ReleaseBurrow(t *testing.T) {
+	mockRepo := new(MockStatefulRepository)
+	service := services.NewGopherNetService(mockRepo)
+
+	mockRepo.On("ReleaseBurrow", "Burrow1", "").Return(nil)
+	mockRepo.On("ReleaseBurrow", "Burrow2", "").Return(models.ErrBurrowNotRented)
+	mockRepo.On("HoldNextWaiter", "Burrow1", models.DefaultHoldTTL).Return(nil, nil)
+
+	assert.NoError(t, service.ReleaseBurrow("Burrow1", ""))
+	assert.ErrorIs(t, service.ReleaseBurrow("Burrow2", ""), models.ErrBurrowNotRented)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestGopherNetService_GenerateReport(t *testing.T) {
 	mockRepo := new(MockStatefulRepository)
 	service := services.NewGopherNetService(mockRepo)
@@ -149,12 +320,46 @@ func TestGopherNetService_SaveReport(t *testing.T) {
 	mockRepo.On("GetAllBurrows").Return(burrows)
 	mockRepo.On("SaveReport", mock.AnythingOfType("string")).Return(nil)
 
+	bus := events.NewBus()
+	service.SetEventBus(bus)
+	published, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
 	// Call the method
 	err := service.SaveReport()
 
 	// Assert expectations
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+
+	event := <-published
+	assert.Equal(t, events.ReportGenerated, event.Type)
+	assert.Contains(t, event.Data, "GopherNet Burrow Report")
+}
+
+func TestGopherNetService_SaveReport_JSON(t *testing.T) {
+	mockRepo := new(MockStatefulRepository)
+	service := services.NewGopherNetService(mockRepo)
+	service.SetReportFormat(models.ReportFormatJSON)
+
+	burrows := []*models.Burrow{
+		{Name: "Burrow1", Depth: 1.5, Width: 1.0, Occupied: false, Age: 100},
+		{Name: "Burrow2", Depth: 2.0, Width: 1.2, Occupied: true, Age: 50},
+	}
+	mockRepo.On("GetAllBurrows").Return(burrows)
+	var saved string
+	mockRepo.On("SaveReport", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		saved = args.String(0)
+	}).Return(nil)
+
+	assert.NoError(t, service.SaveReport())
+
+	var report models.Report
+	assert.NoError(t, json.Unmarshal([]byte(saved), &report))
+	assert.Equal(t, 3.5, report.TotalDepth)
+	assert.Equal(t, 1, report.AvailableBurrows)
+	assert.Equal(t, "Burrow2", report.Largest.Name)
+	assert.Equal(t, "Burrow1", report.Smallest.Name)
 }
 
 func TestGopherNetService_UpdateBurrows(t *testing.T) {
@@ -170,3 +375,21 @@ func TestGopherNetService_UpdateBurrows(t *testing.T) {
 	// Assert expectations
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGopherNetService_AddBurrow(t *testing.T) {
+	mockRepo := new(MockStatefulRepository)
+	service := services.NewGopherNetService(mockRepo)
+
+	burrow := &models.Burrow{Name: "Burrow1", Depth: 1.5, Width: 1.0}
+	mockRepo.On("AddBurrow", burrow).Return(nil)
+
+	// Call the method
+	err := service.AddBurrow(burrow)
+	assert.NoError(t, err)
+
+	// Invalid burrows never reach the repository
+	assert.Error(t, service.AddBurrow(&models.Burrow{Depth: 1}))
+	assert.Error(t, service.AddBurrow(&models.Burrow{Name: "Burrow2", Width: -1}))
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,217 @@
+// Package tlsconfig builds the TLS configuration of the servers, reloading the certificates
+// when their files change on disk.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/marcodd23/go-micro-core/pkg/logmgr"
+	"github.com/pkg/errors"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+)
+
+// DefaultReloadInterval is the minimum interval between two checks of the certificate files.
+const DefaultReloadInterval = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// reloader holds the server certificate and the client CAs, and reloads them when their files change.
+// Files are checked lazily, during the handshakes, at most once per reload interval.
+type reloader struct {
+	cfg        config.TLS
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+	interval   time.Duration
+	clock      clock.Clock
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	versions    map[string]fileVersion
+	lastCheck   time.Time
+}
+
+// fileVersion identifies the content of a file by its modification time and size.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// newReloader loads the certificate, the key and the client CAs of cfg.
+func newReloader(cfg config.TLS, clk clock.Clock) (*reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls requires both certFile and keyFile")
+	}
+
+	minVersion := uint16(tls.VersionTLS12)
+	if cfg.MinVersion != "" {
+		var ok bool
+		if minVersion, ok = tlsVersions[cfg.MinVersion]; !ok {
+			return nil, errors.Errorf("unsupported tls minVersion %q, expected one of 1.0, 1.1, 1.2, 1.3", cfg.MinVersion)
+		}
+	}
+
+	clientAuth := tls.NoClientCert
+	if cfg.ClientCAFile != "" {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+
+	r := &reloader{
+		cfg:        cfg,
+		minVersion: minVersion,
+		clientAuth: clientAuth,
+		interval:   interval,
+		clock:      clk,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// New builds a server TLS configuration from cfg. When cfg.ClientCAFile is set, clients must
+// present a certificate signed by one of its CAs. Every handshake uses the current certificate
+// and client CAs: the files are checked at most once per cfg.ReloadInterval, measured with clk,
+// and reloaded when they change.
+func New(cfg config.TLS, clk clock.Clock) (*tls.Config, error) {
+	r, err := newReloader(cfg, clk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion:         r.minVersion,
+		GetConfigForClient: r.configForClient,
+	}, nil
+}
+
+func (r *reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.maybeReload()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certificate := r.certificate
+
+	return &tls.Config{
+		MinVersion:   r.minVersion,
+		NextProtos:   []string{"h2", "http/1.1"},
+		Certificates: []tls.Certificate{*certificate},
+		ClientAuth:   r.clientAuth,
+		ClientCAs:    r.clientCAs,
+	}, nil
+}
+
+// maybeReload reloads the files if the reload interval has elapsed and one of them changed.
+// Reload errors are logged and the previous certificates are kept.
+func (r *reloader) maybeReload() {
+	r.mu.Lock()
+	now := r.clock.Now()
+	if now.Sub(r.lastCheck) < r.interval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastCheck = now
+	changed := r.changed()
+	r.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err := r.load(); err != nil {
+		logmgr.GetLogger().LogError(context.Background(), "Failed to reload the TLS certificates, keeping the previous ones", err)
+		return
+	}
+
+	logmgr.GetLogger().LogInfo(context.Background(), "Reloaded the TLS certificates")
+}
+
+// changed reports whether a file differs from the loaded version. Must be called with the lock held.
+func (r *reloader) changed() bool {
+	for _, file := range r.files() {
+		version, err := statFile(file)
+		if err != nil || version != r.versions[file] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// load reads the certificate, the key and the client CAs.
+func (r *reloader) load() error {
+	versions := make(map[string]fileVersion)
+	for _, file := range r.files() {
+		version, err := statFile(file)
+		if err != nil {
+			return errors.WithMessage(err, "failed to read the tls files")
+		}
+		versions[file] = version
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return errors.WithMessage(err, "failed to load the tls certificate")
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return errors.WithMessage(err, "failed to read the tls client CAs")
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.Errorf("no PEM certificate found in %s", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.versions = versions
+	r.lastCheck = r.clock.Now()
+
+	return nil
+}
+
+func (r *reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+
+	return files
+}
+
+func statFile(file string) (fileVersion, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileVersion{}, err
+	}
+
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}, nil
+}
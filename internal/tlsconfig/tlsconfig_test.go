@@ -0,0 +1,216 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/clock"
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/tlsconfig"
+)
+
+// authority is a certificate authority generated for the tests.
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newAuthority(t *testing.T) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key of a leaf certificate signed by the authority.
+func (a *authority) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, content []byte, modTime time.Time) {
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// startServer starts an HTTPS server using the TLS configuration built from cfg.
+func startServer(t *testing.T, cfg config.TLS, clk clock.Clock) *httptest.Server {
+	tlsConfig, err := tlsconfig.New(cfg, clk)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// get calls the server on a new connection and returns the serial number of the server certificate.
+func get(server *httptest.Server, ca *authority, clientCert *tls.Certificate) (int64, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	clientConfig := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		clientConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig, DisableKeepAlives: true}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64(), nil
+}
+
+func TestNew_ReloadsChangedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	cfg := config.TLS{
+		Enabled:        true,
+		CertFile:       filepath.Join(dir, "server.pem"),
+		KeyFile:        filepath.Join(dir, "server-key.pem"),
+		ReloadInterval: time.Minute,
+	}
+
+	start := time.Now().Add(-time.Hour)
+	certPEM, keyPEM := ca.issue(t, 100, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.CertFile, certPEM, start)
+	writeFile(t, cfg.KeyFile, keyPEM, start)
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := startServer(t, cfg, clk)
+
+	serial, err := get(server, ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), serial)
+
+	// The renewed certificate is picked up once the reload interval has elapsed.
+	certPEM, keyPEM = ca.issue(t, 200, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.CertFile, certPEM, start.Add(time.Minute))
+	writeFile(t, cfg.KeyFile, keyPEM, start.Add(time.Minute))
+
+	serial, err = get(server, ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), serial)
+
+	clk.Advance(time.Minute)
+	serial, err = get(server, ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), serial)
+
+	// A broken certificate is ignored, the previous one is kept.
+	writeFile(t, cfg.CertFile, []byte("not a certificate"), start.Add(2*time.Minute))
+	clk.Advance(time.Minute)
+	serial, err = get(server, ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), serial)
+}
+
+func TestNew_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	cfg := config.TLS{
+		Enabled:      true,
+		CertFile:     filepath.Join(dir, "server.pem"),
+		KeyFile:      filepath.Join(dir, "server-key.pem"),
+		MinVersion:   "1.3",
+		ClientCAFile: filepath.Join(dir, "ca.pem"),
+	}
+
+	certPEM, keyPEM := ca.issue(t, 100, x509.ExtKeyUsageServerAuth)
+	writeFile(t, cfg.CertFile, certPEM, time.Now())
+	writeFile(t, cfg.KeyFile, keyPEM, time.Now())
+	writeFile(t, cfg.ClientCAFile, ca.pem, time.Now())
+
+	server := startServer(t, cfg, clock.New())
+
+	_, err := get(server, ca, nil)
+	assert.Error(t, err, "clients without a certificate are rejected")
+
+	clientCertPEM, clientKeyPEM := ca.issue(t, 300, x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	require.NoError(t, err)
+
+	_, err = get(server, ca, &clientCert)
+	assert.NoError(t, err)
+
+	otherCertPEM, otherKeyPEM := newAuthority(t).issue(t, 400, x509.ExtKeyUsageClientAuth)
+	otherCert, err := tls.X509KeyPair(otherCertPEM, otherKeyPEM)
+	require.NoError(t, err)
+
+	_, err = get(server, ca, &otherCert)
+	assert.Error(t, err, "clients with a certificate of another CA are rejected")
+}
+
+func TestNew_InvalidConfiguration(t *testing.T) {
+	_, err := tlsconfig.New(config.TLS{Enabled: true}, clock.New())
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	certPEM, keyPEM := ca.issue(t, 100, x509.ExtKeyUsageServerAuth)
+	cfg := config.TLS{CertFile: filepath.Join(dir, "server.pem"), KeyFile: filepath.Join(dir, "server-key.pem"), MinVersion: "1.4"}
+	writeFile(t, cfg.CertFile, certPEM, time.Now())
+	writeFile(t, cfg.KeyFile, keyPEM, time.Now())
+
+	_, err = tlsconfig.New(cfg, clock.New())
+	assert.ErrorContains(t, err, "minVersion")
+
+	cfg.MinVersion = ""
+	cfg.ClientCAFile = filepath.Join(dir, "missing.pem")
+	_, err = tlsconfig.New(cfg, clock.New())
+	assert.Error(t, err)
+}
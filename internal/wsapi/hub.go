@@ -0,0 +1,417 @@
+// Package wsapi serves the live feed of GopherNet over WebSocket. Clients subscribe to burrows or
+// to event types, and receive the matching events published on the bus.
+package wsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/services"
+)
+
+// Defaults of the connection settings.
+const (
+	DefaultSendBuffer   = 64
+	DefaultPingInterval = 30 * time.Second
+	DefaultPongTimeout  = 60 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// maxMessageBytes bounds the size of the client messages.
+const maxMessageBytes = 4096
+
+// maxNameLength bounds the length of the burrow names.
+const maxNameLength = 100
+
+// maxSubscriptions bounds the number of burrows a client subscribes to.
+const maxSubscriptions = 100
+
+// Actions of the client messages.
+const (
+	ActionSubscribe   = "subscribe"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+// Types of the server messages.
+const (
+	TypeSubscribed = "subscribed"
+	TypeEvent      = "event"
+	TypeError      = "error"
+)
+
+// eventTypes are the event types the clients subscribe to.
+var eventTypes = map[events.Type]bool{
+	events.BurrowChanged:             true,
+	events.BurrowsUpdated:            true,
+	events.HoldCreated:               true,
+	events.HoldExpired:               true,
+	events.WaitlistPromoted:          true,
+	events.ReportGenerated:           true,
+	events.AlertPersistenceFailing:   true,
+	events.AlertPersistenceRecovered: true,
+}
+
+// ClientMessage is a message sent by a client to change its subscriptions.
+// Subscribing to a burrow delivers its burrow.changed events, including its state after every
+// periodic update, while subscribing to an event type delivers every event of the type.
+type ClientMessage struct {
+	Action  string        `json:"action"`
+	Burrows []string      `json:"burrows,omitempty"`
+	Events  []events.Type `json:"events,omitempty"`
+}
+
+// ServerMessage is a message sent to a client: an event, the subscriptions of the client after
+// a change, or an error about its last message.
+type ServerMessage struct {
+	Type    string        `json:"type"`
+	Event   *events.Event `json:"event,omitempty"`
+	Burrows []string      `json:"burrows,omitempty"`
+	Events  []events.Type `json:"events,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+// Hub serves the WebSocket connections and closes them on shutdown.
+type Hub struct {
+	service      services.GopherService
+	bus          *events.Bus
+	upgrader     websocket.Upgrader
+	sendBuffer   int
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	writeTimeout time.Duration
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+}
+
+// NewHub creates the hub of the connections, reading the events from bus and the state of the
+// subscribed burrows from service.
+func NewHub(service services.GopherService, bus *events.Bus, cfg config.WebSocket) *Hub {
+	h := &Hub{
+		service:      service,
+		bus:          bus,
+		sendBuffer:   cfg.SendBuffer,
+		pingInterval: cfg.PingInterval,
+		pongTimeout:  cfg.PongTimeout,
+		writeTimeout: cfg.WriteTimeout,
+		clients:      make(map[*client]struct{}),
+	}
+	if h.sendBuffer <= 0 {
+		h.sendBuffer = DefaultSendBuffer
+	}
+	if h.pingInterval <= 0 {
+		h.pingInterval = DefaultPingInterval
+	}
+	if h.pongTimeout <= 0 {
+		h.pongTimeout = DefaultPongTimeout
+	}
+	if h.writeTimeout <= 0 {
+		h.writeTimeout = DefaultWriteTimeout
+	}
+
+	if len(cfg.AllowedOrigins) > 0 {
+		allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+		for _, origin := range cfg.AllowedOrigins {
+			allowed[origin] = true
+		}
+		h.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || allowed["*"] || allowed[origin]
+		}
+	}
+
+	return h
+}
+
+// ServeHTTP upgrades the request to a WebSocket connection and serves it until the client leaves,
+// falls behind or the hub is closed.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Send the headers set by the middlewares, such as the request ID, with the handshake.
+	conn, err := h.upgrader.Upgrade(w, r, w.Header())
+	if err != nil {
+		// The upgrader has replied with an error.
+		return
+	}
+
+	c := &client{
+		conn:    conn,
+		send:    make(chan ServerMessage, h.sendBuffer),
+		done:    make(chan struct{}),
+		burrows: make(map[string]bool),
+		types:   make(map[events.Type]bool),
+	}
+	if !h.register(c) {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+	defer h.unregister(c)
+
+	received, unsubscribe := h.bus.Subscribe(h.sendBuffer)
+	defer unsubscribe()
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.write(c)
+	}()
+	go h.dispatch(c, received)
+
+	h.read(c)
+	c.close(websocket.CloseNormalClosure, "")
+	<-written
+}
+
+// Close closes every connection with a going away status and refuses the new ones.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	clients := make([]*client, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		c.close(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+// Len returns the number of open connections.
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients)
+}
+
+func (h *Hub) register(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.clients[c] = struct{}{}
+
+	return true
+}
+
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, c)
+}
+
+// read handles the client messages until the connection fails, the client closes it or no pong
+// arrives in time.
+func (h *Hub) read(c *client) {
+	c.conn.SetReadLimit(maxMessageBytes)
+	_ = c.conn.SetReadDeadline(time.Now().Add(h.pongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(h.pongTimeout))
+	})
+	c.conn.SetCloseHandler(func(code int, _ string) error {
+		// Echo the status of the client, the writer sends the close message.
+		if code == websocket.CloseNoStatusReceived {
+			code = websocket.CloseNormalClosure
+		}
+		c.close(code, "")
+		return nil
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var message ClientMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			c.enqueue(ServerMessage{Type: TypeError, Message: "malformed message"})
+			continue
+		}
+		c.enqueue(c.handle(message))
+	}
+}
+
+// write writes the queued messages and the pings until the client is closed, then sends the
+// close message and closes the connection.
+func (h *Hub) write(c *client) {
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+	defer c.conn.Close()
+
+	for {
+		select {
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage, c.closeMessage, time.Now().Add(h.writeTimeout))
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(h.writeTimeout))
+			if err := c.conn.WriteJSON(message); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.writeTimeout)); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// dispatch queues the events matching the subscriptions of the client until it is closed.
+func (h *Hub) dispatch(c *client, received <-chan events.Event) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case event, ok := <-received:
+			if !ok {
+				return
+			}
+
+			if c.subscribedTo(event.Type) {
+				c.enqueue(ServerMessage{Type: TypeEvent, Event: &event})
+			}
+
+			switch event.Type {
+			case events.BurrowChanged:
+				if burrow, ok := event.Data.(*models.Burrow); ok && !c.subscribedTo(event.Type) && c.subscribedToBurrow(burrow.Name) {
+					c.enqueue(ServerMessage{Type: TypeEvent, Event: &event})
+				}
+			case events.BurrowsUpdated:
+				// The periodic update changes every burrow: send the state of the subscribed ones.
+				for _, name := range c.subscribedBurrows() {
+					burrow, err := h.service.GetBurrow(name)
+					if err != nil {
+						continue
+					}
+					c.enqueue(ServerMessage{Type: TypeEvent, Event: &events.Event{Type: events.BurrowChanged, Time: event.Time, Data: burrow}})
+				}
+			}
+		}
+	}
+}
+
+// client is a WebSocket connection and its subscriptions.
+type client struct {
+	conn *websocket.Conn
+	send chan ServerMessage
+
+	// done is closed, with closeMessage set, when the connection must be closed.
+	done         chan struct{}
+	closeOnce    sync.Once
+	closeMessage []byte
+
+	mu      sync.Mutex
+	burrows map[string]bool
+	types   map[events.Type]bool
+}
+
+// close makes the writer send a close message with the code and text, then close the connection.
+func (c *client) close(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeMessage = websocket.FormatCloseMessage(code, text)
+		close(c.done)
+	})
+}
+
+// enqueue queues the message, closing the connection of clients too slow to read their messages.
+func (c *client) enqueue(message ServerMessage) {
+	select {
+	case <-c.done:
+	case c.send <- message:
+	default:
+		c.close(websocket.CloseTryAgainLater, "send buffer full")
+	}
+}
+
+// handle applies a client message and returns the reply.
+func (c *client) handle(message ClientMessage) ServerMessage {
+	if message.Action != ActionSubscribe && message.Action != ActionUnsubscribe {
+		return ServerMessage{Type: TypeError, Message: fmt.Sprintf("action must be %q or %q", ActionSubscribe, ActionUnsubscribe)}
+	}
+	for _, name := range message.Burrows {
+		if name == "" || len(name) > maxNameLength {
+			return ServerMessage{Type: TypeError, Message: fmt.Sprintf("burrow names are required and must be at most %d characters", maxNameLength)}
+		}
+	}
+	for _, eventType := range message.Events {
+		if !eventTypes[eventType] {
+			return ServerMessage{Type: TypeError, Message: fmt.Sprintf("unknown event type %q", eventType)}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subscribe := message.Action == ActionSubscribe
+	for _, name := range message.Burrows {
+		if subscribe && !c.burrows[name] && len(c.burrows) >= maxSubscriptions {
+			return ServerMessage{Type: TypeError, Message: fmt.Sprintf("at most %d burrows can be subscribed to", maxSubscriptions)}
+		}
+		if subscribe {
+			c.burrows[name] = true
+		} else {
+			delete(c.burrows, name)
+		}
+	}
+	for _, eventType := range message.Events {
+		if subscribe {
+			c.types[eventType] = true
+		} else {
+			delete(c.types, eventType)
+		}
+	}
+
+	reply := ServerMessage{Type: TypeSubscribed, Burrows: make([]string, 0, len(c.burrows)), Events: make([]events.Type, 0, len(c.types))}
+	for name := range c.burrows {
+		reply.Burrows = append(reply.Burrows, name)
+	}
+	for eventType := range c.types {
+		reply.Events = append(reply.Events, eventType)
+	}
+	sort.Strings(reply.Burrows)
+	sort.Slice(reply.Events, func(i, j int) bool { return reply.Events[i] < reply.Events[j] })
+
+	return reply
+}
+
+func (c *client) subscribedTo(eventType events.Type) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.types[eventType]
+}
+
+func (c *client) subscribedToBurrow(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.burrows[name]
+}
+
+func (c *client) subscribedBurrows() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.burrows))
+	for name := range c.burrows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
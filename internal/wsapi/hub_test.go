@@ -0,0 +1,170 @@
+package wsapi_test
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcodd23/gopernet/internal/config"
+	"github.com/marcodd23/gopernet/internal/events"
+	"github.com/marcodd23/gopernet/internal/models"
+	"github.com/marcodd23/gopernet/internal/repository"
+	"github.com/marcodd23/gopernet/internal/services"
+	"github.com/marcodd23/gopernet/internal/wsapi"
+)
+
+type fixture struct {
+	hub     *wsapi.Hub
+	bus     *events.Bus
+	service *services.DefaultBurrowService
+	url     string
+}
+
+func newFixture(t *testing.T, cfg config.WebSocket) *fixture {
+	repo := repository.NewMemoryRepository("", filepath.Join(t.TempDir(), "report.txt"))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Molehole", Depth: 3.0, Width: 1.3, Age: 50}))
+	require.NoError(t, repo.AddBurrow(&models.Burrow{Name: "The Deep Den", Depth: 2.2, Width: 1.2, Age: 40}))
+
+	bus := events.NewBus()
+	service := services.NewGopherNetService(repo)
+	service.SetEventBus(bus)
+
+	hub := wsapi.NewHub(service, bus, cfg)
+	server := httptest.NewServer(hub)
+	t.Cleanup(server.Close)
+
+	return &fixture{hub: hub, bus: bus, service: service, url: "ws" + strings.TrimPrefix(server.URL, "http")}
+}
+
+func (f *fixture) dial(t *testing.T) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(f.url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, message wsapi.ClientMessage) wsapi.ServerMessage {
+	require.NoError(t, conn.WriteJSON(message))
+	return receive(t, conn)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) wsapi.ServerMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var message wsapi.ServerMessage
+	require.NoError(t, conn.ReadJSON(&message))
+
+	return message
+}
+
+// burrowOf returns the burrow of a burrow.changed event message.
+func burrowOf(t *testing.T, message wsapi.ServerMessage) map[string]interface{} {
+	require.Equal(t, wsapi.TypeEvent, message.Type)
+	require.Equal(t, events.BurrowChanged, message.Event.Type)
+	burrow, ok := message.Event.Data.(map[string]interface{})
+	require.True(t, ok)
+
+	return burrow
+}
+
+func TestHub_Subscriptions(t *testing.T) {
+	f := newFixture(t, config.WebSocket{})
+	conn := f.dial(t)
+
+	reply := send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Burrows: []string{"The Molehole"}})
+	assert.Equal(t, wsapi.ServerMessage{Type: wsapi.TypeSubscribed, Burrows: []string{"The Molehole"}}, reply)
+
+	// Only the subscribed burrow is delivered.
+	require.NoError(t, f.service.RentBurrow("The Deep Den", "alice"))
+	require.NoError(t, f.service.RentBurrow("The Molehole", "bob"))
+	assert.Equal(t, "bob", burrowOf(t, receive(t, conn))["rentedBy"])
+
+	// The periodic update delivers the new state of the subscribed burrows.
+	f.service.UpdateBurrows()
+	assert.Equal(t, float64(51), burrowOf(t, receive(t, conn))["age"])
+
+	reply = send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Events: []events.Type{events.ReportGenerated}})
+	assert.Equal(t, []events.Type{events.ReportGenerated}, reply.Events)
+
+	require.NoError(t, f.service.SaveReport())
+	message := receive(t, conn)
+	require.Equal(t, events.ReportGenerated, message.Event.Type)
+	assert.Contains(t, message.Event.Data, "GopherNet Burrow Report")
+
+	reply = send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionUnsubscribe, Burrows: []string{"The Molehole"}, Events: []events.Type{events.ReportGenerated}})
+	assert.Equal(t, wsapi.ServerMessage{Type: wsapi.TypeSubscribed}, reply)
+
+	// Invalid messages get an error, the connection stays open.
+	reply = send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Events: []events.Type{"unknown"}})
+	assert.Equal(t, wsapi.TypeError, reply.Type)
+
+	reply = send(t, conn, wsapi.ClientMessage{Action: "publish"})
+	assert.Equal(t, wsapi.TypeError, reply.Type)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+	assert.Equal(t, wsapi.TypeError, receive(t, conn).Type)
+
+	reply = send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Events: []events.Type{events.BurrowChanged}})
+	assert.Equal(t, wsapi.TypeSubscribed, reply.Type)
+}
+
+func TestHub_Keepalive(t *testing.T) {
+	f := newFixture(t, config.WebSocket{PingInterval: 20 * time.Millisecond, PongTimeout: 100 * time.Millisecond})
+
+	// Clients answering the pings stay connected.
+	alive := f.dial(t)
+	go func() {
+		for {
+			if _, _, err := alive.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Clients not answering the pings are disconnected.
+	silent := f.dial(t)
+	silent.SetPingHandler(func(string) error { return nil })
+	require.NoError(t, silent.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		if _, _, err := silent.ReadMessage(); err != nil {
+			break
+		}
+	}
+
+	assert.Eventually(t, func() bool { return f.hub.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestHub_SlowConsumer(t *testing.T) {
+	f := newFixture(t, config.WebSocket{SendBuffer: 2, WriteTimeout: time.Second})
+	conn := f.dial(t)
+	send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Events: []events.Type{events.ReportGenerated}})
+
+	// The client stops reading: the socket buffers, then the send buffer, fill up.
+	report := strings.Repeat("x", 1<<20)
+	assert.Eventually(t, func() bool {
+		f.bus.Publish(events.Event{Type: events.ReportGenerated, Data: report})
+		return f.hub.Len() == 0
+	}, 10*time.Second, time.Millisecond)
+}
+
+func TestHub_Close(t *testing.T) {
+	f := newFixture(t, config.WebSocket{})
+	conn := f.dial(t)
+	send(t, conn, wsapi.ClientMessage{Action: wsapi.ActionSubscribe, Burrows: []string{"The Molehole"}})
+
+	f.hub.Close()
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+
+	// New connections are closed right away.
+	_, _, err = f.dial(t).ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+	assert.Eventually(t, func() bool { return f.hub.Len() == 0 }, 5*time.Second, 10*time.Millisecond)
+}